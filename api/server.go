@@ -0,0 +1,325 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"focusd/core"
+	"focusd/storage"
+	"focusd/system"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const passwordHeader = "X-Focusd-Password"
+
+type Server struct {
+	tracker *core.Tracker
+	token   string
+	srv     *http.Server
+}
+
+type currentSessionResponse struct {
+	Active      bool                `json:"active"`
+	Paused      bool                `json:"paused"`
+	Idle        bool                `json:"idle"`
+	Session     *core.ActiveSession `json:"session,omitempty"`
+	ElapsedSecs int                 `json:"elapsed_secs"`
+}
+
+type sessionsResponse struct {
+	Sessions []storage.Session `json:"sessions"`
+	Total    int               `json:"total"`
+	Limit    int               `json:"limit"`
+	Offset   int               `json:"offset"`
+}
+
+type pomodoroResponse struct {
+	Active        bool `json:"active"`
+	RemainingSecs int  `json:"remaining_secs"`
+	TotalMinutes  int  `json:"total_minutes"`
+}
+
+func NewServer(tracker *core.Tracker, port int, token string) *Server {
+	s := &Server{
+		tracker: tracker,
+		token:   token,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/session/current", s.handleCurrentSession)
+	mux.HandleFunc("/api/v1/summary/today", s.handleTodaySummary)
+	mux.HandleFunc("/api/v1/summary", s.handlePeriodSummary)
+	mux.HandleFunc("/api/v1/sessions", s.handleSessions)
+	mux.HandleFunc("/api/v1/pause", s.handlePause)
+	mux.HandleFunc("/api/v1/resume", s.handleResume)
+	mux.HandleFunc("/api/v1/pomodoro", s.handlePomodoroStatus)
+	mux.HandleFunc("/api/v1/pomodoro/start", s.handlePomodoroStart)
+	mux.HandleFunc("/api/v1/pomodoro/stop", s.handlePomodoroStop)
+
+	s.srv = &http.Server{
+		Addr:              net.JoinHostPort("127.0.0.1", strconv.Itoa(port)),
+		Handler:           s.authenticate(mux),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      10 * time.Second,
+	}
+	return s
+}
+
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.srv.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.srv.Addr, err)
+	}
+
+	go s.srv.Serve(listener)
+	return nil
+}
+
+func (s *Server) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return s.srv.Shutdown(ctx)
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "missing or invalid token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleCurrentSession(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	resp := currentSessionResponse{
+		Paused: s.tracker.IsPaused(),
+		Idle:   s.tracker.IsIdle(),
+	}
+	if session := s.tracker.CurrentSessionAsStored(); session != nil {
+		resp.Active = true
+		resp.Session = session
+		resp.ElapsedSecs = int(s.tracker.Now().Sub(session.StartTime).Seconds())
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleTodaySummary(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	summary, err := core.GetDailySummary(storage.Today())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
+
+func (s *Server) handlePeriodSummary(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	days := 7
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "days must be a positive integer")
+			return
+		}
+		days = n
+	}
+
+	summary, err := core.GetPeriodSummary(days)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
+
+func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	query := r.URL.Query()
+	limit, err := intParam(query.Get("limit"), 50)
+	if err != nil || limit < 1 || limit > 1000 {
+		writeError(w, http.StatusBadRequest, "limit must be between 1 and 1000")
+		return
+	}
+	offset, err := intParam(query.Get("offset"), 0)
+	if err != nil || offset < 0 {
+		writeError(w, http.StatusBadRequest, "offset must be a non-negative integer")
+		return
+	}
+
+	from, to := query.Get("from"), query.Get("to")
+	for _, d := range []string{from, to} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			writeError(w, http.StatusBadRequest, "dates must use YYYY-MM-DD")
+			return
+		}
+	}
+
+	sessions, total, err := storage.GetSessionsPaginated(limit, offset, from, to)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if sessions == nil {
+		sessions = []storage.Session{}
+	}
+	writeJSON(w, http.StatusOK, sessionsResponse{
+		Sessions: sessions,
+		Total:    total,
+		Limit:    limit,
+		Offset:   offset,
+	})
+}
+
+func (s *Server) handlePause(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodPost) || !requirePassword(w, r) {
+		return
+	}
+	if err := s.tracker.SetPaused(true); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"paused": true})
+}
+
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodPost) {
+		return
+	}
+	if err := s.tracker.SetPaused(false); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"paused": false})
+}
+
+func (s *Server) handlePomodoroStatus(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+	writeJSON(w, http.StatusOK, pomodoroStatus())
+}
+
+func (s *Server) handlePomodoroStart(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var body struct {
+		Minutes int `json:"minutes"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON body")
+			return
+		}
+	}
+
+	if err := core.StartPomodoro(body.Minutes); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, pomodoroStatus())
+}
+
+func (s *Server) handlePomodoroStop(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodPost) || !requirePassword(w, r) {
+		return
+	}
+	if err := core.StopPomodoro(); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, pomodoroStatus())
+}
+
+func pomodoroStatus() pomodoroResponse {
+	active, remaining, total := core.GetPomodoroStatus()
+	return pomodoroResponse{
+		Active:        active,
+		RemainingSecs: int(remaining.Seconds()),
+		TotalMinutes:  total,
+	}
+}
+
+func requireMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return false
+	}
+	return true
+}
+
+// requirePassword applies the CLI's password check to actions that weaken
+// tracking. The password is sent in the X-Focusd-Password header.
+func requirePassword(w http.ResponseWriter, r *http.Request) bool {
+	if err := system.UserConfigError(); err != nil {
+		writeError(w, http.StatusServiceUnavailable, "cannot check the password: "+err.Error())
+		return false
+	}
+	if !system.IsPasswordEnabled() {
+		return true
+	}
+	if remaining := storage.PasswordLockRemaining(); remaining > 0 {
+		writeError(w, http.StatusTooManyRequests, fmt.Sprintf("too many failed attempts; try again in %s", remaining.Round(time.Second)))
+		return false
+	}
+
+	password := r.Header.Get(passwordHeader)
+	if password == "" {
+		writeError(w, http.StatusForbidden, "password required in "+passwordHeader)
+		return false
+	}
+	ok := system.CheckPassword(password)
+	if err := storage.RecordPasswordAttempt(ok); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return false
+	}
+	if !ok {
+		writeError(w, http.StatusForbidden, "incorrect password")
+		return false
+	}
+	return true
+}
+
+func intParam(value string, fallback int) (int, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
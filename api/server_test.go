@@ -0,0 +1,202 @@
+package api
+
+import (
+	"encoding/json"
+	"focusd/core"
+	"focusd/storage"
+	"focusd/system"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func isolateDataDir(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("APPDATA", dir)
+	if err := system.ReloadUserConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { storage.Close() })
+}
+
+func TestRequirePassword(t *testing.T) {
+	isolateDataDir(t)
+
+	check := func(password string) int {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/pause", nil)
+		if password != "" {
+			r.Header.Set(passwordHeader, password)
+		}
+		w := httptest.NewRecorder()
+		if requirePassword(w, r) {
+			return http.StatusOK
+		}
+		return w.Code
+	}
+
+	if got := check(""); got != http.StatusOK {
+		t.Fatalf("without a password set, status = %d, want %d", got, http.StatusOK)
+	}
+
+	if err := system.SetPassword("hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		password string
+		want     int
+	}{
+		{"missing header", "", http.StatusForbidden},
+		{"wrong password", "hunter3", http.StatusForbidden},
+		{"correct password", "hunter2", http.StatusOK},
+	}
+	for _, tt := range tests {
+		if got := check(tt.password); got != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	for range 3 {
+		check("wrong")
+	}
+	if got := check("hunter2"); got != http.StatusTooManyRequests {
+		t.Errorf("after repeated failures, status = %d, want %d", got, http.StatusTooManyRequests)
+	}
+}
+
+type noWindows struct{}
+
+func (noWindows) ForegroundWindow() (*system.WindowInfo, error) { return nil, nil }
+
+type silentNotifier struct{}
+
+func (silentNotifier) Notify(title, message string) bool { return true }
+
+func (silentNotifier) NotifyWithAction(title, message string, callback func(disable bool)) bool {
+	return true
+}
+
+type neverIdle struct{}
+
+func (neverIdle) LastInputTime() (time.Time, error) { return time.Now(), nil }
+
+const testToken = "test-token"
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	isolateDataDir(t)
+	tracker := core.NewTrackerWithOptions(core.TrackerOptions{
+		Windows:    noWindows{},
+		Notifier:   silentNotifier{},
+		IdleSource: neverIdle{},
+	})
+	return NewServer(tracker, 0, testToken)
+}
+
+func serve(s *Server, method, target, token string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, nil)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	s.srv.Handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestTokenAuth(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"missing token", "", http.StatusUnauthorized},
+		{"wrong token", "test-tokem", http.StatusUnauthorized},
+		{"token prefix", "test", http.StatusUnauthorized},
+		{"valid token", testToken, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if got := serve(s, http.MethodGet, "/api/v1/session/current", tt.token).Code; got != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEndpoints(t *testing.T) {
+	s := newTestServer(t)
+	start := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	for i := range 3 {
+		begin := start.Add(time.Duration(i) * time.Hour)
+		if err := storage.InsertSession(&storage.Session{
+			AppName: "VS Code", ExeName: "code", WindowTitle: "main.go",
+			StartTime: begin, EndTime: begin.Add(10 * time.Minute), DurationSecs: 600, Date: "2026-03-10",
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		method string
+		target string
+		want   int
+	}{
+		{http.MethodGet, "/api/v1/session/current", http.StatusOK},
+		{http.MethodPost, "/api/v1/session/current", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/v1/summary/today", http.StatusOK},
+		{http.MethodGet, "/api/v1/summary?days=7", http.StatusOK},
+		{http.MethodGet, "/api/v1/summary?days=0", http.StatusBadRequest},
+		{http.MethodGet, "/api/v1/sessions?limit=2", http.StatusOK},
+		{http.MethodGet, "/api/v1/sessions?limit=5000", http.StatusBadRequest},
+		{http.MethodGet, "/api/v1/sessions?offset=-1", http.StatusBadRequest},
+		{http.MethodGet, "/api/v1/sessions?from=10-03-2026", http.StatusBadRequest},
+		{http.MethodGet, "/api/v1/pomodoro", http.StatusOK},
+		{http.MethodGet, "/api/v1/pause", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		if got := serve(s, tt.method, tt.target, testToken).Code; got != tt.want {
+			t.Errorf("%s %s: status = %d, want %d", tt.method, tt.target, got, tt.want)
+		}
+	}
+
+	var page sessionsResponse
+	w := serve(s, http.MethodGet, "/api/v1/sessions?limit=2&from=2026-03-10&to=2026-03-10", testToken)
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 3 || len(page.Sessions) != 2 || page.Limit != 2 {
+		t.Errorf("sessions page = total %d, %d sessions, limit %d; want 3, 2, 2", page.Total, len(page.Sessions), page.Limit)
+	}
+
+	var current currentSessionResponse
+	if err := json.Unmarshal(serve(s, http.MethodGet, "/api/v1/session/current", testToken).Body.Bytes(), &current); err != nil {
+		t.Fatal(err)
+	}
+	if current.Active || current.Session != nil {
+		t.Errorf("current session = %+v, want none before the tracker sees a window", current)
+	}
+}
+
+func TestPauseAndResume(t *testing.T) {
+	s := newTestServer(t)
+
+	if w := serve(s, http.MethodPost, "/api/v1/pause", testToken); w.Code != http.StatusOK {
+		t.Fatalf("pause status = %d: %s", w.Code, w.Body)
+	}
+	if !storage.IsPaused() || !s.tracker.IsPaused() {
+		t.Error("pause did not pause tracking")
+	}
+	if w := serve(s, http.MethodPost, "/api/v1/resume", testToken); w.Code != http.StatusOK {
+		t.Fatalf("resume status = %d: %s", w.Code, w.Body)
+	}
+	if storage.IsPaused() || s.tracker.IsPaused() {
+		t.Error("resume did not resume tracking")
+	}
+}
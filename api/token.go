@@ -0,0 +1,61 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"focusd/storage"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func GetTokenPath() (string, error) {
+	dataDir, err := storage.GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "api_token"), nil
+}
+
+func LoadOrCreateToken() (string, error) {
+	path, err := GetTokenPath()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+	}
+
+	return RotateToken()
+}
+
+func RotateToken() (string, error) {
+	path, err := GetTokenPath()
+	if err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+
+	tempPath := path + ".tmp"
+	if err := os.WriteFile(tempPath, []byte(token+"\n"), 0600); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		return "", err
+	}
+	return token, nil
+}
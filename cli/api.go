@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"fmt"
+	"focusd/api"
+	"focusd/system"
+	"focusd/ui"
+	"os"
+	"strconv"
+)
+
+func HandleAPICommand(args []string) {
+	if len(args) < 3 {
+		RunAPIStatus()
+		return
+	}
+
+	switch args[2] {
+	case "status":
+		RunAPIStatus()
+	case "enable":
+		port := system.GetAPIPort()
+		if len(args) > 3 {
+			p, err := strconv.Atoi(args[3])
+			if err != nil || p < 1 || p > 65535 {
+				ui.PrintError("Invalid port. Usage: focusd api enable [port]")
+				os.Exit(1)
+			}
+			port = p
+		}
+		RunAPIEnable(port)
+	case "disable":
+		RunAPIDisable()
+	case "token":
+		RunAPIToken(false)
+	case "rotate-token":
+		RunAPIToken(true)
+	default:
+		fmt.Printf("Unknown api command: %s\n", args[2])
+		fmt.Println("Available: status, enable [port], disable, token, rotate-token")
+		os.Exit(1)
+	}
+}
+
+func RunAPIStatus() {
+	ui.PrintSectionHeader("Local HTTP API")
+
+	if system.GetAPIEnabled() {
+		ui.PrintStatus("API", "ENABLED", true)
+	} else {
+		ui.PrintStatus("API", "DISABLED", false)
+	}
+	ui.PrintStatus("Address", fmt.Sprintf("http://127.0.0.1:%d/api/v1", system.GetAPIPort()), false)
+	fmt.Println()
+	fmt.Println("  Requests must send 'Authorization: Bearer <token>'.")
+	fmt.Println("  Run 'focusd api token' to print the token.")
+	if system.IsPasswordEnabled() {
+		fmt.Println("  Pause and pomodoro/stop also need 'X-Focusd-Password: <password>'.")
+	}
+	fmt.Println()
+}
+
+func RunAPIEnable(port int) {
+	if _, err := api.LoadOrCreateToken(); err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to create API token: %v", err))
+		os.Exit(1)
+	}
+	if err := system.SetAPIEnabled(true, port); err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to enable API: %v", err))
+		os.Exit(1)
+	}
+	ui.PrintOK(fmt.Sprintf("API enabled on 127.0.0.1:%d", port))
+	ui.PrintInfo("Restart the daemon ('focusd stop' then 'focusd start') to apply.")
+}
+
+func RunAPIDisable() {
+	if err := system.SetAPIEnabled(false, 0); err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to disable API: %v", err))
+		os.Exit(1)
+	}
+	ui.PrintOK("API disabled.")
+	ui.PrintInfo("Restart the daemon ('focusd stop' then 'focusd start') to apply.")
+}
+
+func RunAPIToken(rotate bool) {
+	var token string
+	var err error
+	if rotate {
+		token, err = api.RotateToken()
+	} else {
+		token, err = api.LoadOrCreateToken()
+	}
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to read API token: %v", err))
+		os.Exit(1)
+	}
+
+	if rotate {
+		ui.PrintOK("API token rotated. Restart the daemon to apply.")
+	}
+	fmt.Println(token)
+}
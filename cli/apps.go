@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"fmt"
+	"focusd/core"
+	"focusd/storage"
+	"focusd/ui"
+	"os"
+	"strings"
+)
+
+func HandleAppsCommand(args []string) {
+	if len(args) < 3 {
+		RunAppsList()
+		return
+	}
+
+	switch args[2] {
+	case "list":
+		RunAppsList()
+	case "rename":
+		if len(args) < 5 {
+			fmt.Println("Usage: focusd apps rename <exe_name> <display name>")
+			os.Exit(1)
+		}
+		RunAppsRename(args[3], strings.Join(args[4:], " "))
+	case "add":
+		RunAppsAdd(args[3:])
+	case "remove":
+		if len(args) < 4 {
+			fmt.Println("Usage: focusd apps remove <display name|exe_name>")
+			os.Exit(1)
+		}
+		RunAppsRemove(strings.Join(args[3:], " "))
+	case "apply":
+		RunAppsApply()
+	default:
+		fmt.Printf("Unknown apps command: %s\n", args[2])
+		fmt.Println("Available: list, rename, add, remove, apply")
+		os.Exit(1)
+	}
+}
+
+func RunAppsList() {
+	if err := core.ReloadAppRules(); err != nil {
+		ui.PrintError(err.Error())
+		os.Exit(1)
+	}
+	file, err := core.LoadAppRulesFile()
+	if err != nil {
+		ui.PrintError(err.Error())
+		os.Exit(1)
+	}
+
+	ui.PrintSectionHeader("App Identity Rules")
+	if len(file.Rules) == 0 {
+		fmt.Println("  No rules. Apps are named from their executable.")
+	} else {
+		columns := []ui.TableColumn{
+			{Header: "Name", Width: 20},
+			{Header: "Exe", Width: 24},
+			{Header: "Path contains", Width: 20},
+			{Header: "Title regex", Width: 20},
+		}
+		var rows [][]string
+		for _, r := range file.Rules {
+			rows = append(rows, []string{r.Name, r.Exe, r.Path, r.Title})
+		}
+		ui.PrintTable(columns, rows)
+	}
+	fmt.Println()
+
+	path, _ := core.GetAppRulesPath()
+	fmt.Printf("  Rules file: %s\n", path)
+	fmt.Println("  Rules with a path or title split a host process (java, python,")
+	fmt.Println("  ApplicationFrameHost, Electron) into separately tracked apps.")
+	fmt.Println()
+}
+
+func RunAppsRename(exeName, name string) {
+	if err := core.RenameApp(exeName, name); err != nil {
+		ui.PrintError(err.Error())
+		os.Exit(1)
+	}
+	ui.PrintOK(fmt.Sprintf("%s will be shown as %s.", exeName, name))
+
+	if err := storage.Init(); err != nil {
+		ui.PrintWarn(fmt.Sprintf("Rule saved, but history could not be updated: %v", err))
+		return
+	}
+	defer storage.Close()
+
+	n, err := core.RenameAppHistory(exeName, name)
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to rename stored history: %v", err))
+		os.Exit(1)
+	}
+	ui.PrintInfo(fmt.Sprintf("Updated %d stored rows.", n))
+}
+
+func RunAppsAdd(args []string) {
+	var rule core.AppRule
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		value := func() string {
+			if i+1 >= len(args) {
+				ui.PrintError(args[i] + " needs a value")
+				os.Exit(1)
+			}
+			i++
+			return args[i]
+		}
+		switch args[i] {
+		case "--exe":
+			rule.Exe = value()
+		case "--path":
+			rule.Path = value()
+		case "--title":
+			rule.Title = value()
+		default:
+			positional = append(positional, args[i])
+		}
+	}
+	rule.Name = strings.Join(positional, " ")
+
+	if rule.Name == "" || rule.Exe == "" {
+		fmt.Println("Usage: focusd apps add <display name> --exe <exe_name> [--path <text>] [--title <regex>]")
+		os.Exit(1)
+	}
+
+	if err := core.AddAppRule(rule); err != nil {
+		ui.PrintError(err.Error())
+		os.Exit(1)
+	}
+	ui.PrintOK(fmt.Sprintf("Rule added for %s.", rule.Name))
+	fmt.Println("Run 'focusd apps apply' to update stored history.")
+}
+
+func RunAppsRemove(nameOrExe string) {
+	n, err := core.RemoveAppRules(nameOrExe)
+	if err != nil {
+		ui.PrintError(err.Error())
+		os.Exit(1)
+	}
+	if n == 0 {
+		ui.PrintInfo("No matching rules.")
+		return
+	}
+	ui.PrintOK(fmt.Sprintf("Removed %d rule(s).", n))
+	fmt.Println("Run 'focusd apps apply' to update stored history.")
+}
+
+func RunAppsApply() {
+	if err := core.ReloadAppRules(); err != nil {
+		ui.PrintError(err.Error())
+		os.Exit(1)
+	}
+	if err := storage.Init(); err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to initialize: %v", err))
+		os.Exit(1)
+	}
+	defer storage.Close()
+
+	n, err := core.ApplyAppRules()
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to apply rules: %v", err))
+		os.Exit(1)
+	}
+	ui.PrintOK(fmt.Sprintf("Rules applied; %d sessions reassigned.", n))
+	fmt.Println("Path-based rules only apply to new sessions, since paths are not stored.")
+}
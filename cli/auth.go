@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"fmt"
+	"focusd/storage"
+	"focusd/system"
+	"focusd/ui"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	passwordEnvVar    = "FOCUSD_PASSWORD"
+	passwordStdinFlag = "--password-stdin"
+)
+
+type commandPolicy func(args []string) bool
+
+func always(args []string) bool {
+	return true
+}
+
+func withArgs(args []string) bool {
+	return len(args) > 2
+}
+
+func subcommands(names ...string) commandPolicy {
+	return func(args []string) bool {
+		if len(args) < 3 {
+			return false
+		}
+		for _, name := range names {
+			if args[2] == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func browserPolicy(args []string) bool {
+	if subcommands("add", "remove")(args) {
+		return true
+	}
+	return len(args) > 4 && args[2] == "private"
+}
+
+var protectedCommands = map[string]commandPolicy{
+	"init":               always,
+	"i":                  always,
+	"stop":               always,
+	"pause":              always,
+	"p":                  always,
+	"limit":              withArgs,
+	"retention":          subcommands("set", "reset"),
+	"ret":                subcommands("set", "reset"),
+	"autostart":          subcommands("enable", "disable"),
+	"auto":               subcommands("enable", "disable"),
+	"path":               subcommands("enable", "disable"),
+	"browser":            browserPolicy,
+	"api":                subcommands("enable", "disable", "token", "rotate-token"),
+	"encryption":         subcommands("enable", "disable"),
+	"privacy":            subcommands("mode", "redact"),
+	"categories":         subcommands("app", "site", "rate", "reset"),
+	"groups":             subcommands("add", "remove"),
+	"apps":               subcommands("rename", "add", "remove", "apply"),
+	"import":             always,
+	"forget":             always,
+	"uninstall":          always,
+	"rebuild-aggregates": always,
+}
+
+func isProtected(args []string) bool {
+	if len(args) < 2 {
+		return false
+	}
+	policy, ok := protectedCommands[args[1]]
+	return ok && policy(args)
+}
+
+func extractPasswordFlag(args []string) ([]string, bool) {
+	out := make([]string, 0, len(args))
+	found := false
+	for _, arg := range args {
+		if arg == passwordStdinFlag {
+			found = true
+			continue
+		}
+		out = append(out, arg)
+	}
+	return out, found
+}
+
+func requirePassword(fromStdin bool) bool {
+	if err := system.UserConfigError(); err != nil {
+		ui.PrintError(fmt.Sprintf("Cannot check the password: %v", err))
+		fmt.Println("  Fix config.json before running this command.")
+		return false
+	}
+	if !system.IsPasswordEnabled() {
+		return true
+	}
+
+	if err := storage.Init(); err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to open database: %v", err))
+		return false
+	}
+	defer storage.Close()
+
+	if passwordLocked() {
+		return false
+	}
+
+	var pwd string
+	switch {
+	case fromStdin:
+		pwd = readStdinLine()
+	case os.Getenv(passwordEnvVar) != "":
+		pwd = os.Getenv(passwordEnvVar)
+	case isInteractive():
+		fmt.Print("Password: ")
+		pwd = readStdinLine()
+	}
+
+	if pwd == "" {
+		ui.PrintError(fmt.Sprintf("This command requires the focusd password. Pass it with %s or %s.", passwordStdinFlag, passwordEnvVar))
+		return false
+	}
+	if !checkPassword(pwd) {
+		ui.PrintError("Incorrect password!")
+		return false
+	}
+	return true
+}
+
+func passwordLocked() bool {
+	if remaining := storage.PasswordLockRemaining(); remaining > 0 {
+		ui.PrintError(fmt.Sprintf("Too many failed attempts. Try again in %s.", remaining.Round(time.Second)))
+		return true
+	}
+	return false
+}
+
+func checkPassword(pwd string) bool {
+	ok := system.CheckPassword(pwd)
+	recordPasswordAttempt(ok)
+	if ok && system.NeedsRecoveryCodes() {
+		ui.PrintInfo("Your password was upgraded and has no recovery codes yet.")
+		showNewRecoveryCodes()
+	}
+	return ok
+}
+
+func recordPasswordAttempt(ok bool) {
+	if err := storage.RecordPasswordAttempt(ok); err != nil {
+		ui.PrintWarn(fmt.Sprintf("Failed to record password attempt: %v", err))
+	}
+}
+
+func isInteractive() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func readStdinLine() string {
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				break
+			}
+			line = append(line, buf[0])
+		}
+		if err != nil {
+			break
+		}
+	}
+	return strings.TrimSpace(string(line))
+}
@@ -5,6 +5,8 @@ import (
 	"focusd/storage"
 	"focusd/ui"
 	"os"
+	"sort"
+	"strings"
 )
 
 func HandleBrowsersCommand(args []string) {
@@ -28,9 +30,19 @@ func HandleBrowsersCommand(args []string) {
 			os.Exit(1)
 		}
 		RunBrowserRemove(args[3])
+	case "private":
+		if len(args) < 4 {
+			RunBrowserPrivateList()
+			return
+		}
+		if len(args) < 5 {
+			fmt.Printf("Usage: focusd browser private <exe_name> <%s>\n", strings.Join(storage.PrivateWindowModes, "|"))
+			os.Exit(1)
+		}
+		RunBrowserPrivateSet(args[3], args[4])
 	default:
 		fmt.Printf("Unknown browser command: %s\n", args[2])
-		fmt.Println("Available: list, add, remove")
+		fmt.Println("Available: list, add, remove, private")
 		os.Exit(1)
 	}
 }
@@ -69,3 +81,36 @@ func RunBrowserRemove(exeName string) {
 	}
 	ui.PrintOK(fmt.Sprintf("Removed '%s' from browser list.", exeName))
 }
+
+func RunBrowserPrivateList() {
+	ui.PrintSectionHeader("Private Windows")
+
+	fmt.Println("  InPrivate/Incognito/Private Browsing windows are logged as")
+	fmt.Println("  \"<Browser> (Private)\" with no title, unless overridden below.")
+	fmt.Println()
+
+	modes := storage.GetPrivateWindowModes()
+	if len(modes) == 0 {
+		fmt.Println("  No per-browser overrides.")
+	} else {
+		var exes []string
+		for exe := range modes {
+			exes = append(exes, exe)
+		}
+		sort.Strings(exes)
+		for _, exe := range exes {
+			ui.PrintKeyValue(exe, modes[exe])
+		}
+	}
+	fmt.Println()
+	fmt.Println("  Modes: private (label only), ignore (don't track), track (log titles)")
+	fmt.Println()
+}
+
+func RunBrowserPrivateSet(exeName, mode string) {
+	if err := storage.SetPrivateWindowMode(exeName, mode); err != nil {
+		ui.PrintError(err.Error())
+		os.Exit(1)
+	}
+	ui.PrintOK(fmt.Sprintf("Private windows of '%s': %s", exeName, strings.ToLower(mode)))
+}
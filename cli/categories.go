@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"fmt"
+	"focusd/core"
+	"focusd/ui"
+	"os"
+	"sort"
+	"strings"
+)
+
+func HandleCategoriesCommand(args []string) {
+	if len(args) < 3 {
+		RunCategoriesList()
+		return
+	}
+
+	switch args[2] {
+	case "list":
+		RunCategoriesList()
+	case "app", "site":
+		if len(args) < 5 {
+			fmt.Printf("Usage: focusd categories %s <name> <category>\n", args[2])
+			os.Exit(1)
+		}
+		RunCategoriesAssign(args[2], strings.Join(args[3:len(args)-1], " "), args[len(args)-1])
+	case "rate":
+		if len(args) < 5 {
+			fmt.Printf("Usage: focusd categories rate <category> <%s>\n", strings.Join(core.Ratings, "|"))
+			os.Exit(1)
+		}
+		RunCategoriesRate(strings.Join(args[3:len(args)-1], " "), args[len(args)-1])
+	case "reset":
+		if len(args) < 5 {
+			fmt.Println("Usage: focusd categories reset app|site|category <name>")
+			os.Exit(1)
+		}
+		RunCategoriesReset(args[3], strings.Join(args[4:], " "))
+	default:
+		fmt.Printf("Unknown categories command: %s\n", args[2])
+		fmt.Println("Available: list, app, site, rate, reset")
+		os.Exit(1)
+	}
+}
+
+func loadCategoriesOrExit() *core.CategoriesFile {
+	if err := core.ReloadCategories(); err != nil {
+		ui.PrintError(err.Error())
+		path, _ := core.GetCategoriesPath()
+		fmt.Printf("  Fix %s and try again.\n", path)
+		os.Exit(1)
+	}
+	file, err := core.LoadCategoriesFile()
+	if err != nil {
+		ui.PrintError(err.Error())
+		os.Exit(1)
+	}
+	return file
+}
+
+func RunCategoriesList() {
+	file := loadCategoriesOrExit()
+
+	ui.PrintSectionHeader("Categories")
+	columns := []ui.TableColumn{
+		{Header: "Category", Width: 20},
+		{Header: "Rating", Width: 12},
+		{Header: "Source", Width: 8},
+	}
+	var rows [][]string
+	for _, c := range core.GetCategories() {
+		source := "built-in"
+		if c.Custom {
+			source = "custom"
+		}
+		rows = append(rows, []string{c.Name, c.Rating, source})
+	}
+	ui.PrintTable(columns, rows)
+	fmt.Println()
+
+	if len(file.Apps) > 0 || len(file.Sites) > 0 {
+		ui.PrintSectionHeader("Custom Assignments")
+		printCategoryAssignments("app", file.Apps)
+		printCategoryAssignments("site", file.Sites)
+		fmt.Println()
+	}
+
+	path, _ := core.GetCategoriesPath()
+	fmt.Printf("  Overrides: %s\n", path)
+	fmt.Println("  Productive time counts fully toward the score, neutral time counts half.")
+	fmt.Println()
+}
+
+func printCategoryAssignments(kind string, assignments map[string]string) {
+	names := make([]string, 0, len(assignments))
+	for name := range assignments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("  %-5s %-30s → %s\n", kind, name, assignments[name])
+	}
+}
+
+func RunCategoriesAssign(kind, name, category string) {
+	loadCategoriesOrExit()
+
+	var err error
+	if kind == "app" {
+		err = core.SetAppCategory(name, category)
+	} else {
+		err = core.SetSiteCategory(name, category)
+	}
+	if err != nil {
+		ui.PrintError(err.Error())
+		os.Exit(1)
+	}
+	ui.PrintOK(fmt.Sprintf("%s is now counted as %s.", name, category))
+}
+
+func RunCategoriesRate(category, rating string) {
+	loadCategoriesOrExit()
+
+	if err := core.SetCategoryRating(category, rating); err != nil {
+		ui.PrintError(err.Error())
+		os.Exit(1)
+	}
+	ui.PrintOK(fmt.Sprintf("%s is now rated %s.", category, strings.ToLower(rating)))
+}
+
+func RunCategoriesReset(kind, name string) {
+	loadCategoriesOrExit()
+
+	var err error
+	switch kind {
+	case "app":
+		err = core.SetAppCategory(name, "")
+	case "site":
+		err = core.SetSiteCategory(name, "")
+	case "category":
+		err = core.SetCategoryRating(name, "")
+	default:
+		ui.PrintError(fmt.Sprintf("Unknown kind %q (use app, site or category)", kind))
+		os.Exit(1)
+	}
+	if err != nil {
+		ui.PrintError(err.Error())
+		os.Exit(1)
+	}
+	ui.PrintOK(fmt.Sprintf("Custom %s setting for %s removed.", kind, name))
+}
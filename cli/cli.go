@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"focusd/system"
+	"focusd/ui"
 	"os"
 )
 
@@ -14,6 +15,7 @@ func PrintHelp() {
 	fmt.Println("Usage:")
 	fmt.Println("  focusd start              Start tracking (background)")
 	fmt.Println("  focusd stop               Stop tracking")
+	fmt.Println("  focusd reload             Reload config in the running daemon")
 	fmt.Println()
 	fmt.Println("Setup:")
 	fmt.Println("  focusd init      (i)      Initialize and grant consent")
@@ -23,24 +25,36 @@ func PrintHelp() {
 	fmt.Println("Viewing Data:")
 	fmt.Println("  focusd status    (s)      Show tracking status")
 	fmt.Println("  focusd stats     (st)     Detailed usage breakdown")
-	fmt.Println("  focusd export    (e)      Export data to CSV")
+	fmt.Println("  focusd export    (e)      Export data (csv/json/ndjson)")
+	fmt.Println("  focusd import <file>      Import exported sessions")
 	fmt.Println()
 	fmt.Println("Tracking Control:")
 	fmt.Println("  focusd pause     (p)      Pause tracking")
 	fmt.Println("  focusd resume    (r)      Resume tracking")
 	fmt.Println("  focusd focus [min]        Start Pomodoro timer")
-	fmt.Println("  focusd limit [app] [min]  Set daily app limit")
+	fmt.Println("  focusd limit <t> <min>    Daily limit (app, site:, category:)")
 	fmt.Println()
 	fmt.Println("Configuration:")
-	fmt.Println("  focusd retention (ret)    Show/set retention days")
+	fmt.Println("  focusd retention (ret)    Show/set retention tiers")
+	fmt.Println("  focusd rebuild-aggregates Recompute daily totals from sessions")
+	fmt.Println("  focusd forget app|site    Delete all data for an app or site")
 	fmt.Println("  focusd autostart (auto)   Manage auto-start")
 	fmt.Println("  focusd path               Manage PATH integration")
-	fmt.Println("  focusd browser            Manage custom browsers")
+	fmt.Println("  focusd browser            Manage browsers and private windows")
+	fmt.Println("  focusd groups             Manage site grouping rules")
+	fmt.Println("  focusd apps               Rename apps and manage identity rules")
+	fmt.Println("  focusd categories         Productivity categories and ratings")
+	fmt.Println("  focusd api                Manage the local HTTP API")
+	fmt.Println("  focusd encryption         Encrypt window titles at rest")
+	fmt.Println("  focusd privacy            Title privacy mode and redactions")
 	fmt.Println()
 	fmt.Println("Other:")
 	fmt.Println("  focusd help      (h)      Show this help message")
 	fmt.Println("  focusd version   (-v)     Show version")
-	fmt.Println("  focusd reset-password     Reset password (when locked out)")
+	fmt.Println("  focusd reset-password     Reset password with a recovery code")
+	fmt.Println()
+	fmt.Println("When a password is set, commands that change settings or data ask for it.")
+	fmt.Println("For scripts, pipe it with --password-stdin or set FOCUSD_PASSWORD.")
 	fmt.Println()
 }
 
@@ -49,11 +63,21 @@ func PrintVersion() {
 }
 
 func Run(args []string) {
+	args, passwordFromStdin := extractPasswordFlag(args)
+	if err := system.UserConfigError(); err != nil {
+		ui.PrintWarn(err.Error() + "; using default settings until it is fixed")
+	} else if err := system.MigrateUserConfig(); err != nil {
+		ui.PrintWarn("Could not remove the plaintext password from config.json: " + err.Error())
+	}
 	if len(args) < 2 {
 		RunInteractiveMenu()
 		return
 	}
 
+	if isProtected(args) && !requirePassword(passwordFromStdin) {
+		os.Exit(1)
+	}
+
 	command := args[1]
 
 	switch command {
@@ -71,6 +95,8 @@ func Run(args []string) {
 		RunStart()
 	case "stop":
 		RunStop()
+	case "reload":
+		RunReload()
 	case "--daemon":
 		RunDaemon()
 	case "status", "s":
@@ -89,8 +115,26 @@ func Run(args []string) {
 		handlePath(args)
 	case "browser":
 		HandleBrowsersCommand(args)
+	case "api":
+		HandleAPICommand(args)
 	case "export", "e":
-		RunExport()
+		RunExport(args)
+	case "import":
+		RunImport(args)
+	case "forget":
+		HandleForgetCommand(args)
+	case "encryption":
+		HandleEncryptionCommand(args)
+	case "privacy":
+		HandlePrivacyCommand(args)
+	case "groups":
+		HandleGroupsCommand(args)
+	case "apps":
+		HandleAppsCommand(args)
+	case "categories":
+		HandleCategoriesCommand(args)
+	case "rebuild-aggregates":
+		RunRebuildAggregates(args)
 	case "uninstall":
 		RunUninstall()
 	case "help", "-h", "--help", "h":
@@ -98,7 +142,7 @@ func Run(args []string) {
 	case "version", "-v", "--version":
 		PrintVersion()
 	case "reset-password":
-		RunResetPassword()
+		RunResetPassword(args)
 	default:
 		fmt.Printf("Unknown command: %s\n", command)
 		fmt.Println("Run 'focusd help' for usage information.")
@@ -115,11 +159,7 @@ func handleRetention(args []string) {
 	case "status":
 		RunRetentionStatus()
 	case "set":
-		if len(args) < 4 {
-			fmt.Println("Usage: focusd retention set <days>")
-			os.Exit(1)
-		}
-		RunRetentionSet(args[3])
+		RunRetentionSet(args[3:])
 	case "reset":
 		RunRetentionReset()
 	default:
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+	"focusd/storage"
+	"focusd/system"
+	"focusd/ui"
+	"os"
+)
+
+func HandleEncryptionCommand(args []string) {
+	if err := storage.Init(); err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to initialize: %v", err))
+		os.Exit(1)
+	}
+	defer storage.Close()
+
+	if len(args) < 3 {
+		RunEncryptionStatus()
+		return
+	}
+
+	switch args[2] {
+	case "status":
+		RunEncryptionStatus()
+	case "enable":
+		runEncryptionChange(true)
+	case "disable":
+		runEncryptionChange(false)
+	default:
+		fmt.Printf("Unknown encryption command: %s\n", args[2])
+		fmt.Println("Available: status, enable, disable")
+		os.Exit(1)
+	}
+}
+
+func RunEncryptionStatus() {
+	ui.PrintSectionHeader("Encryption at Rest")
+	if storage.IsTitleEncryptionEnabled() {
+		ui.PrintKeyValue("Window titles", "ENCRYPTED")
+	} else {
+		ui.PrintKeyValue("Window titles", "plain text")
+	}
+	ui.PrintKeyValue("Key", system.KeystoreDescription())
+	if !system.KeystoreEncryptsKey {
+		printUnprotectedKeyWarning()
+	}
+	fmt.Println()
+	fmt.Println("  Encrypts window titles and site names in focusd.db.")
+	fmt.Println("  Use 'focusd export --encrypt' for passphrase-protected exports.")
+	fmt.Println()
+}
+
+func runEncryptionChange(enable bool) {
+	if storage.IsTitleEncryptionEnabled() == enable {
+		RunEncryptionStatus()
+		return
+	}
+
+	wasRunning := isDaemonRunning()
+	if wasRunning {
+		ui.PrintInfo("Stopping focusd while titles are rewritten...")
+		if err := stopDaemon(); err != nil {
+			ui.PrintError("Could not stop focusd. Stop it and try again.")
+			os.Exit(1)
+		}
+	}
+
+	var err error
+	if enable {
+		err = storage.EnableTitleEncryption()
+	} else {
+		err = storage.DisableTitleEncryption()
+	}
+
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to update encryption: %v", err))
+	} else if enable {
+		ui.PrintOK("Window titles are now encrypted at rest.")
+		ui.PrintWarn("Database backups cannot be read without the key: " + system.KeystoreDescription())
+		if !system.KeystoreEncryptsKey {
+			printUnprotectedKeyWarning()
+		}
+	} else {
+		ui.PrintOK("Window titles are now stored as plain text.")
+	}
+
+	if wasRunning {
+		StartDaemonProcess()
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+func printUnprotectedKeyWarning() {
+	ui.PrintWarn("The key itself is not encrypted on this system. Anyone who can read the data")
+	fmt.Println("  directory can read the key too; this only protects copies of focusd.db.")
+}
@@ -1,122 +1,476 @@
 package cli
 
 import (
+	"bytes"
 	"encoding/csv"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"focusd/core"
 	"focusd/storage"
+	"focusd/system"
 	"focusd/ui"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 )
 
-func RunExport() {
-	if err := storage.Init(); err != nil {
-		ui.PrintError(fmt.Sprintf("Failed to initialize: %v", err))
+const (
+	exportTableSessions     = "sessions"
+	exportTableApps         = "apps"
+	exportTableBrowsing     = "browsing"
+	exportTableProductivity = "productivity"
+)
+
+const exportPassphraseEnvVar = "FOCUSD_EXPORT_PASSPHRASE"
+
+var exportTables = []string{exportTableApps, exportTableSessions, exportTableBrowsing, exportTableProductivity}
+
+type exportOptions struct {
+	Format  string
+	From    string
+	To      string
+	Out     string
+	Tables  []string
+	Encrypt bool
+
+	passphrase string
+}
+
+type exportData struct {
+	Apps         []storage.AppDailyStat
+	Sessions     []storage.Session
+	Browsing     []browsingExportRow
+	Productivity []productivityExportRow
+}
+
+type browsingExportRow struct {
+	Date              string `json:"date"`
+	Site              string `json:"site"`
+	TotalDurationSecs int    `json:"total_duration_secs"`
+	OpenCount         int    `json:"open_count"`
+}
+
+type productivityExportRow struct {
+	Date              string `json:"date"`
+	Category          string `json:"category"`
+	Rating            string `json:"rating"`
+	TotalDurationSecs int    `json:"total_duration_secs"`
+	ProductivityScore int    `json:"productivity_score"`
+}
+
+func RunExport(args []string) {
+	opts, err := parseExportArgs(args)
+	if err != nil {
+		ui.PrintError(err.Error())
+		printExportUsage()
 		os.Exit(1)
 	}
+
+	toStdout := opts.Out == "-"
+	fail := func(msg string) {
+		if toStdout {
+			fmt.Fprintln(os.Stderr, "Error: "+msg)
+		} else {
+			ui.PrintError(msg)
+		}
+		os.Exit(1)
+	}
+
+	if err := storage.Init(); err != nil {
+		fail(fmt.Sprintf("Failed to initialize: %v", err))
+	}
 	defer storage.Close()
 
 	if !storage.IsConsentGranted() {
-		ui.PrintError("focusd is not initialized. Run 'focusd init' first.")
-		os.Exit(1)
+		fail("focusd is not initialized. Run 'focusd init' first.")
 	}
 
-	userProfile := os.Getenv("USERPROFILE")
-	exportDir := filepath.Join(userProfile, "Downloads")
-	if userProfile == "" {
-		wd, err := os.Getwd()
+	data, err := loadExportData(opts)
+	if err != nil {
+		fail(fmt.Sprintf("Failed to read data: %v", err))
+	}
+
+	if opts.Encrypt {
+		passphrase, err := readExportPassphrase(true)
 		if err != nil {
-			exportDir = "."
-		} else {
-			exportDir = wd
+			fail(err.Error())
 		}
+		opts.passphrase = passphrase
 	}
 
-	timestamp := time.Now().Format("2006-01-02_150405")
-
-	appsFile := filepath.Join(exportDir, fmt.Sprintf("focusd_apps_%s.csv", timestamp))
-	sessionsFile := filepath.Join(exportDir, fmt.Sprintf("focusd_sessions_%s.csv", timestamp))
+	if toStdout {
+		if err := writeExportTo(os.Stdout, opts, data, opts.Tables); err != nil {
+			fail(err.Error())
+		}
+		return
+	}
 
-	if err := exportApps(appsFile); err != nil {
-		ui.PrintError(fmt.Sprintf("Failed to export apps: %v", err))
-	} else {
-		ui.PrintOK(fmt.Sprintf("Exported apps to: %s", appsFile))
+	files, err := exportTargets(opts)
+	if err != nil {
+		fail(err.Error())
 	}
 
-	if err := exportSessions(sessionsFile); err != nil {
-		ui.PrintError(fmt.Sprintf("Failed to export sessions: %v", err))
-	} else {
-		ui.PrintOK(fmt.Sprintf("Exported sessions to: %s", sessionsFile))
+	for _, target := range files {
+		if err := writeExportFile(target.path, opts, data, target.tables); err != nil {
+			ui.PrintError(fmt.Sprintf("Failed to export %s: %v", strings.Join(target.tables, ", "), err))
+			continue
+		}
+		ui.PrintOK(fmt.Sprintf("Exported %s to: %s", strings.Join(target.tables, ", "), target.path))
 	}
 
 	fmt.Println()
 	ui.PrintInfo("Export complete!")
 }
 
-func exportApps(filename string) error {
-	apps, err := storage.GetAllAppStats()
-	if err != nil {
-		return err
+func printExportUsage() {
+	fmt.Println("Usage: focusd export [--format csv|json|ndjson] [--from YYYY-MM-DD] [--to YYYY-MM-DD]")
+	fmt.Println("                     [--table apps|sessions|browsing|productivity|all] [--out <file|dir|->] [--encrypt]")
+}
+
+func parseExportArgs(args []string) (*exportOptions, error) {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	opts := &exportOptions{}
+	var table string
+	fs.StringVar(&opts.Format, "format", "csv", "")
+	fs.StringVar(&opts.From, "from", "", "")
+	fs.StringVar(&opts.To, "to", "", "")
+	fs.StringVar(&opts.Out, "out", "", "")
+	fs.StringVar(&table, "table", "all", "")
+	fs.BoolVar(&opts.Encrypt, "encrypt", false, "")
+
+	if len(args) > 2 {
+		if err := fs.Parse(args[2:]); err != nil {
+			return nil, err
+		}
+		if fs.NArg() > 0 {
+			return nil, fmt.Errorf("unexpected argument: %s", fs.Arg(0))
+		}
 	}
 
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
+	opts.Format = strings.ToLower(opts.Format)
+	switch opts.Format {
+	case "csv", "json", "ndjson":
+	default:
+		return nil, fmt.Errorf("unknown format %q", opts.Format)
+	}
+
+	for _, d := range []string{opts.From, opts.To} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", d)
+		}
+	}
+	if opts.From != "" && opts.To != "" && opts.From > opts.To {
+		return nil, fmt.Errorf("--from %s is after --to %s", opts.From, opts.To)
 	}
-	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	switch table = strings.ToLower(table); table {
+	case "all":
+		opts.Tables = exportTables
+	case exportTableApps, exportTableSessions, exportTableBrowsing, exportTableProductivity:
+		opts.Tables = []string{table}
+	default:
+		return nil, fmt.Errorf("unknown table %q", table)
+	}
 
-	writer.Write([]string{"Date", "App Name", "Executable", "Duration (seconds)", "Open Count"})
+	if opts.Format == "csv" && opts.Out == "-" && len(opts.Tables) > 1 {
+		return nil, fmt.Errorf("CSV to stdout needs a single --table")
+	}
+	return opts, nil
+}
 
-	for _, app := range apps {
-		writer.Write([]string{
-			app.Date,
-			app.AppName,
-			app.ExeName,
-			strconv.Itoa(app.TotalDurationSecs),
-			strconv.Itoa(app.OpenCount),
-		})
+func loadExportData(opts *exportOptions) (*exportData, error) {
+	data := &exportData{}
+	for _, table := range opts.Tables {
+		var err error
+		switch table {
+		case exportTableApps:
+			data.Apps, err = storage.GetAppStatsInRange(opts.From, opts.To)
+		case exportTableSessions:
+			data.Sessions, err = storage.GetSessionsInRange(opts.From, opts.To)
+		case exportTableBrowsing:
+			var stats []storage.AppDailyStat
+			stats, err = storage.GetBrowserStatsInRange(opts.From, opts.To)
+			for _, s := range stats {
+				data.Browsing = append(data.Browsing, browsingExportRow{
+					Date:              s.Date,
+					Site:              s.AppName,
+					TotalDurationSecs: s.TotalDurationSecs,
+					OpenCount:         s.OpenCount,
+				})
+			}
+		case exportTableProductivity:
+			var days []core.DayProductivity
+			days, err = core.GetProductivityInRange(opts.From, opts.To)
+			for _, d := range days {
+				for _, c := range d.Categories {
+					data.Productivity = append(data.Productivity, productivityExportRow{
+						Date:              d.Date,
+						Category:          c.Category,
+						Rating:            c.Rating,
+						TotalDurationSecs: c.TotalSecs,
+						ProductivityScore: d.ProductivityScore,
+					})
+				}
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
 	}
+	return data, nil
+}
 
-	return nil
+type exportTarget struct {
+	path   string
+	tables []string
 }
 
-func exportSessions(filename string) error {
-	sessions, err := storage.GetAllSessions()
+func defaultExportDir() string {
+	userProfile := os.Getenv("USERPROFILE")
+	if userProfile == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			userProfile = home
+		}
+	}
+	exportDir := filepath.Join(userProfile, "Downloads")
+	if info, err := os.Stat(exportDir); userProfile == "" || err != nil || !info.IsDir() {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "."
+		}
+		return wd
+	}
+	return exportDir
+}
+
+func exportTargets(opts *exportOptions) ([]exportTarget, error) {
+	dir := ""
+	if opts.Out == "" {
+		dir = defaultExportDir()
+	} else if info, err := os.Stat(opts.Out); err == nil && info.IsDir() {
+		dir = opts.Out
+	}
+
+	if dir == "" {
+		if opts.Format == "csv" && len(opts.Tables) > 1 {
+			return nil, fmt.Errorf("CSV export of several tables needs a directory for --out, or a single --table")
+		}
+		return []exportTarget{{path: opts.Out, tables: opts.Tables}}, nil
+	}
+
+	timestamp := time.Now().Format("2006-01-02_150405")
+	suffix := ""
+	if opts.Encrypt {
+		suffix = ".enc"
+	}
+	if opts.Format != "csv" {
+		name := fmt.Sprintf("focusd_export_%s.%s%s", timestamp, opts.Format, suffix)
+		return []exportTarget{{path: filepath.Join(dir, name), tables: opts.Tables}}, nil
+	}
+
+	var targets []exportTarget
+	for _, table := range opts.Tables {
+		name := fmt.Sprintf("focusd_%s_%s.csv%s", table, timestamp, suffix)
+		targets = append(targets, exportTarget{path: filepath.Join(dir, name), tables: []string{table}})
+	}
+	return targets, nil
+}
+
+func writeExportFile(path string, opts *exportOptions, data *exportData, tables []string) error {
+	file, err := os.Create(path)
 	if err != nil {
 		return err
 	}
+	if err := writeExportTo(file, opts, data, tables); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func writeExportTo(w io.Writer, opts *exportOptions, data *exportData, tables []string) error {
+	if !opts.Encrypt {
+		return writeExport(w, opts, data, tables)
+	}
 
-	file, err := os.Create(filename)
+	var buf bytes.Buffer
+	if err := writeExport(&buf, opts, data, tables); err != nil {
+		return err
+	}
+	sealed, err := system.SealArchive(buf.Bytes(), opts.passphrase)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	_, err = w.Write(sealed)
+	return err
+}
+
+func readExportPassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv(exportPassphraseEnvVar); passphrase != "" {
+		return passphrase, nil
+	}
+	if !isInteractive() {
+		return "", fmt.Errorf("no passphrase given; set %s", exportPassphraseEnvVar)
+	}
+
+	fmt.Fprint(os.Stderr, "Export passphrase: ")
+	passphrase := readStdinLine()
+	if passphrase == "" {
+		return "", fmt.Errorf("passphrase cannot be empty")
+	}
+	if confirm {
+		fmt.Fprint(os.Stderr, "Repeat passphrase: ")
+		if readStdinLine() != passphrase {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+	return passphrase, nil
+}
+
+func writeExport(w io.Writer, opts *exportOptions, data *exportData, tables []string) error {
+	switch opts.Format {
+	case "json":
+		return writeExportJSON(w, opts, data, tables)
+	case "ndjson":
+		return writeExportNDJSON(w, data, tables)
+	default:
+		return writeExportCSV(w, data, tables[0])
+	}
+}
+
+func writeExportCSV(w io.Writer, data *exportData, table string) error {
+	writer := csv.NewWriter(w)
+
+	switch table {
+	case exportTableApps:
+		writer.Write([]string{"Date", "App Name", "Executable", "Duration (seconds)", "Open Count"})
+		for _, app := range data.Apps {
+			writer.Write([]string{
+				app.Date,
+				app.AppName,
+				app.ExeName,
+				strconv.Itoa(app.TotalDurationSecs),
+				strconv.Itoa(app.OpenCount),
+			})
+		}
+	case exportTableSessions:
+		writer.Write([]string{"Date", "App Name", "Executable", "Window Title", "Start Time", "End Time", "Duration (seconds)"})
+		for _, s := range data.Sessions {
+			endTime := ""
+			if !s.EndTime.IsZero() {
+				endTime = s.EndTime.Format(time.RFC3339)
+			}
+			writer.Write([]string{
+				s.Date,
+				s.AppName,
+				s.ExeName,
+				s.WindowTitle,
+				s.StartTime.Format(time.RFC3339),
+				endTime,
+				strconv.Itoa(s.DurationSecs),
+			})
+		}
+	case exportTableBrowsing:
+		writer.Write([]string{"Date", "Site", "Duration (seconds)", "Open Count"})
+		for _, b := range data.Browsing {
+			writer.Write([]string{
+				b.Date,
+				b.Site,
+				strconv.Itoa(b.TotalDurationSecs),
+				strconv.Itoa(b.OpenCount),
+			})
+		}
+	case exportTableProductivity:
+		writer.Write([]string{"Date", "Category", "Rating", "Duration (seconds)", "Productivity Score"})
+		for _, p := range data.Productivity {
+			writer.Write([]string{
+				p.Date,
+				p.Category,
+				p.Rating,
+				strconv.Itoa(p.TotalDurationSecs),
+				strconv.Itoa(p.ProductivityScore),
+			})
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeExportJSON(w io.Writer, opts *exportOptions, data *exportData, tables []string) error {
+	out := map[string]interface{}{
+		"exported_at": time.Now().Format(time.RFC3339),
+	}
+	if opts.From != "" {
+		out["from"] = opts.From
+	}
+	if opts.To != "" {
+		out["to"] = opts.To
+	}
+	for _, table := range tables {
+		switch table {
+		case exportTableApps:
+			out[table] = nonNil(data.Apps)
+		case exportTableSessions:
+			out[table] = nonNil(data.Sessions)
+		case exportTableBrowsing:
+			out[table] = nonNil(data.Browsing)
+		case exportTableProductivity:
+			out[table] = nonNil(data.Productivity)
+		}
+	}
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
 
-	writer.Write([]string{"Date", "App Name", "Executable", "Window Title", "Start Time", "End Time", "Duration (seconds)"})
+func nonNil[T any](rows []T) []T {
+	if rows == nil {
+		return []T{}
+	}
+	return rows
+}
 
-	for _, s := range sessions {
-		endTime := ""
-		if !s.EndTime.IsZero() {
-			endTime = s.EndTime.Format(time.RFC3339)
+func writeExportNDJSON(w io.Writer, data *exportData, tables []string) error {
+	for _, table := range tables {
+		var err error
+		switch table {
+		case exportTableApps:
+			err = writeNDJSONRows(w, table, data.Apps)
+		case exportTableSessions:
+			err = writeNDJSONRows(w, table, data.Sessions)
+		case exportTableBrowsing:
+			err = writeNDJSONRows(w, table, data.Browsing)
+		case exportTableProductivity:
+			err = writeNDJSONRows(w, table, data.Productivity)
+		}
+		if err != nil {
+			return err
 		}
-		writer.Write([]string{
-			s.Date,
-			s.AppName,
-			s.ExeName,
-			s.WindowTitle,
-			s.StartTime.Format(time.RFC3339),
-			endTime,
-			strconv.Itoa(s.DurationSecs),
-		})
 	}
+	return nil
+}
 
+func writeNDJSONRows[T any](w io.Writer, table string, rows []T) error {
+	prefix := []byte(`{"table":"` + table + `",`)
+	for _, row := range rows {
+		line, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(append(append(prefix, line[1:]...), '\n')); err != nil {
+			return err
+		}
+	}
 	return nil
 }
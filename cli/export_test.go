@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"focusd/storage"
+	"focusd/system"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseExportArgs(t *testing.T) {
+	tests := []struct {
+		args    string
+		want    exportOptions
+		wantErr string
+	}{
+		{args: "", want: exportOptions{Format: "csv", Tables: exportTables}},
+		{args: "--format JSON --table sessions --from 2026-03-01 --to 2026-03-31 --encrypt", want: exportOptions{Format: "json", From: "2026-03-01", To: "2026-03-31", Tables: []string{exportTableSessions}, Encrypt: true}},
+		{args: "--format csv --table apps --out -", want: exportOptions{Format: "csv", Out: "-", Tables: []string{exportTableApps}}},
+		{args: "--format xml", wantErr: "unknown format"},
+		{args: "--table everything", wantErr: "unknown table"},
+		{args: "--from 03/01/2026", wantErr: "invalid date"},
+		{args: "--from 2026-03-31 --to 2026-03-01", wantErr: "is after"},
+		{args: "--out -", wantErr: "single --table"},
+		{args: "sessions", wantErr: "unexpected argument"},
+	}
+	for _, tt := range tests {
+		args := append([]string{"focusd", "export"}, strings.Fields(tt.args)...)
+		got, err := parseExportArgs(args)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseExportArgs(%q) error = %v, want %q", tt.args, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseExportArgs(%q) error = %v", tt.args, err)
+			continue
+		}
+		if got.Format != tt.want.Format || got.From != tt.want.From || got.To != tt.want.To || got.Out != tt.want.Out ||
+			got.Encrypt != tt.want.Encrypt || !slices.Equal(got.Tables, tt.want.Tables) {
+			t.Errorf("parseExportArgs(%q) = %+v, want %+v", tt.args, *got, tt.want)
+		}
+	}
+}
+
+func TestExportTargetsSplitCSVTables(t *testing.T) {
+	dir := t.TempDir()
+	targets, err := exportTargets(&exportOptions{Format: "csv", Out: dir, Tables: exportTables, Encrypt: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != len(exportTables) {
+		t.Fatalf("got %d targets, want one per table", len(targets))
+	}
+	for i, target := range targets {
+		name := filepath.Base(target.path)
+		if filepath.Dir(target.path) != dir || !strings.HasPrefix(name, "focusd_"+exportTables[i]+"_") || !strings.HasSuffix(name, ".csv.enc") {
+			t.Errorf("target %d = %s, want focusd_%s_*.csv.enc in %s", i, target.path, exportTables[i], dir)
+		}
+	}
+
+	if _, err := exportTargets(&exportOptions{Format: "csv", Out: filepath.Join(dir, "all.csv"), Tables: exportTables}); err == nil {
+		t.Error("CSV export of every table to one file was accepted")
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	isolateDataDir(t)
+	if err := storage.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer storage.Close()
+
+	start := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	for _, s := range sampleExport().Sessions {
+		if err := storage.InsertSession(&s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	outside := &storage.Session{AppName: "Slack", ExeName: "slack", WindowTitle: "random", StartTime: start.AddDate(0, 0, 5), EndTime: start.AddDate(0, 0, 5).Add(time.Minute), DurationSecs: 60, Date: "2026-03-15"}
+	if err := storage.InsertSession(outside); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		format  string
+		encrypt bool
+	}{
+		{"csv", false},
+		{"json", false},
+		{"ndjson", false},
+		{"json", true},
+		{"csv", true},
+	} {
+		opts := &exportOptions{Format: tt.format, From: "2026-03-10", To: "2026-03-10", Tables: []string{exportTableSessions}, Encrypt: tt.encrypt, passphrase: "correct horse"}
+		data, err := loadExportData(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		if err := writeExportTo(&buf, opts, data, opts.Tables); err != nil {
+			t.Fatal(err)
+		}
+		if tt.encrypt && (!system.IsSealedArchive(buf.Bytes()) || bytes.Contains(buf.Bytes(), []byte("main.go"))) {
+			t.Errorf("%s: encrypted export is not sealed", tt.format)
+		}
+
+		path := writeTempFile(t, "export."+tt.format, buf.Bytes())
+		t.Setenv(exportPassphraseEnvVar, "correct horse")
+		sessions, invalid, err := readImportFile(path)
+		if err != nil {
+			t.Fatalf("%s encrypted=%v: %v", tt.format, tt.encrypt, err)
+		}
+		if invalid != 0 || len(sessions) != 2 {
+			t.Fatalf("%s encrypted=%v: read %d sessions, %d invalid; want 2, 0", tt.format, tt.encrypt, len(sessions), invalid)
+		}
+
+		report, err := planImport(sessions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Duplicates != 2 || len(report.Added) != 0 {
+			t.Errorf("%s encrypted=%v: re-importing an export added %d and skipped %d, want every row skipped", tt.format, tt.encrypt, len(report.Added), report.Duplicates)
+		}
+
+		if tt.encrypt {
+			t.Setenv(exportPassphraseEnvVar, "battery staple")
+			if _, _, err := readImportFile(path); !errors.Is(err, system.ErrWrongPassphrase) {
+				t.Errorf("%s: wrong passphrase error = %v, want ErrWrongPassphrase", tt.format, err)
+			}
+		}
+	}
+}
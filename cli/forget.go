@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"focusd/core"
+	"focusd/ipc"
+	"focusd/storage"
+	"focusd/system"
+	"focusd/ui"
+	"os"
+	"strings"
+)
+
+func HandleForgetCommand(args []string) {
+	var positional []string
+	whitelist, assumeYes := false, false
+	for _, arg := range args[2:] {
+		switch arg {
+		case "--whitelist", "--never-track":
+			whitelist = true
+		case "--yes", "-y":
+			assumeYes = true
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
+	if len(positional) != 2 || (positional[0] != "app" && positional[0] != "site") {
+		fmt.Println("Usage: focusd forget app <exe_name> [--whitelist] [--yes]")
+		fmt.Println("       focusd forget site <site or group> [--whitelist] [--yes]")
+		fmt.Println()
+		fmt.Println("Permanently deletes every record of the app or site.")
+		fmt.Println("--whitelist also stops it from being tracked in the future.")
+		os.Exit(1)
+	}
+	kind, target := positional[0], strings.TrimSpace(positional[1])
+
+	if err := storage.Init(); err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to initialize: %v", err))
+		os.Exit(1)
+	}
+	defer storage.Close()
+
+	if !storage.IsConsentGranted() {
+		ui.PrintError("focusd is not initialized. Run 'focusd init' first.")
+		os.Exit(1)
+	}
+
+	var preview *core.ForgetPreview
+	var err error
+	if kind == "app" {
+		preview, err = core.PreviewForgetApp(target)
+	} else {
+		preview, err = core.PreviewForgetSite(target)
+	}
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to read sessions: %v", err))
+		os.Exit(1)
+	}
+	printForgetPreview(preview)
+
+	ui.PrintWarn(fmt.Sprintf("This permanently deletes all tracked data for %s '%s'.", kind, target))
+	if !assumeYes {
+		fmt.Print("Type 'yes' to continue: ")
+		confirm, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.ToLower(strings.TrimSpace(confirm)) != "yes" {
+			ui.PrintInfo("Cancelled")
+			return
+		}
+	}
+
+	if whitelist {
+		var err error
+		if kind == "app" {
+			err = system.AddWhitelistApp(target)
+		} else {
+			err = system.AddWhitelistSite(target)
+		}
+		if err != nil {
+			ui.PrintError(fmt.Sprintf("Failed to whitelist %s: %v", target, err))
+			os.Exit(1)
+		}
+	}
+
+	var result *storage.ForgetResult
+	if kind == "app" {
+		ipc.SendRequest(ipc.Request{Command: ipc.CommandForgetApp, Target: target})
+		result, err = core.ForgetApp(target)
+	} else {
+		ipc.SendRequest(ipc.Request{Command: ipc.CommandForgetSite, Target: target})
+		result, err = core.ForgetSite(target)
+	}
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to forget %s: %v", target, err))
+		os.Exit(1)
+	}
+
+	ui.PrintOK(fmt.Sprintf("Deleted %d session(s) and %d daily/rollup row(s) for '%s'.", result.Sessions, result.AggregateRows, target))
+	if whitelist {
+		ui.PrintOK(fmt.Sprintf("'%s' added to the whitelist and will not be tracked.", target))
+	}
+}
+
+func printForgetPreview(preview *core.ForgetPreview) {
+	if preview.Sessions == 0 {
+		ui.PrintInfo("No sessions match. Only stored daily totals and rollups for it will be removed.")
+		return
+	}
+
+	ui.PrintInfo(fmt.Sprintf("%d session(s) match, %s in total.", preview.Sessions, ui.FormatDuration(preview.TotalSecs)))
+	for _, s := range preview.Sample {
+		fmt.Printf("  %s  %-20s  %-40s  %s\n", s.StartTime.Format("2006-01-02 15:04"), ui.TruncateString(s.AppName, 20), ui.TruncateString(s.WindowTitle, 40), ui.FormatDurationShort(s.DurationSecs))
+	}
+	if more := preview.Sessions - len(preview.Sample); more > 0 {
+		fmt.Printf("  ... and %d more\n", more)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"fmt"
+	"focusd/core"
+	"focusd/ui"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func HandleGroupsCommand(args []string) {
+	if len(args) < 3 {
+		RunGroupsList()
+		return
+	}
+
+	switch args[2] {
+	case "list":
+		RunGroupsList()
+	case "add":
+		RunGroupsAdd(args[3:])
+	case "remove":
+		if len(args) < 4 {
+			fmt.Println("Usage: focusd groups remove <name>")
+			os.Exit(1)
+		}
+		RunGroupsRemove(strings.Join(args[3:], " "))
+	case "test":
+		if len(args) < 4 {
+			fmt.Println("Usage: focusd groups test <window title>")
+			os.Exit(1)
+		}
+		RunGroupsTest(strings.Join(args[3:], " "))
+	default:
+		fmt.Printf("Unknown groups command: %s\n", args[2])
+		fmt.Println("Available: list, add, remove, test")
+		os.Exit(1)
+	}
+}
+
+func loadGroupRulesOrExit() []core.EffectiveGroupRule {
+	if err := core.ReloadGroupRules(); err != nil {
+		ui.PrintError(err.Error())
+		path, _ := core.GetGroupRulesPath()
+		fmt.Printf("  Fix %s and try again.\n", path)
+		os.Exit(1)
+	}
+	return core.GetGroupRules()
+}
+
+func RunGroupsList() {
+	rules := loadGroupRulesOrExit()
+
+	ui.PrintSectionHeader("Site Groups")
+	columns := []ui.TableColumn{
+		{Header: "Group", Width: 20},
+		{Header: "Priority", Width: 8},
+		{Header: "Source", Width: 8},
+		{Header: "Matches", Width: 40},
+	}
+
+	var rows [][]string
+	for _, r := range rules {
+		source := "built-in"
+		if r.Custom {
+			source = "custom"
+		}
+		matches := append([]string(nil), r.Patterns...)
+		for _, re := range r.Regexes {
+			matches = append(matches, "/"+strings.TrimPrefix(re.String(), "(?i)")+"/")
+		}
+		rows = append(rows, []string{r.Name, strconv.Itoa(r.Priority), source, strings.Join(matches, ", ")})
+	}
+	ui.PrintTable(columns, rows)
+	fmt.Println()
+
+	path, _ := core.GetGroupRulesPath()
+	fmt.Printf("  Custom rules: %s\n", path)
+	fmt.Println()
+}
+
+func RunGroupsAdd(args []string) {
+	isRegex := false
+	priority := 0
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--regex":
+			isRegex = true
+		case "--priority":
+			if i+1 >= len(args) {
+				ui.PrintError("--priority needs a value")
+				os.Exit(1)
+			}
+			p, err := strconv.Atoi(args[i+1])
+			if err != nil || p < 1 {
+				ui.PrintError(fmt.Sprintf("Invalid priority: %s", args[i+1]))
+				os.Exit(1)
+			}
+			priority = p
+			i++
+		default:
+			positional = append(positional, args[i])
+		}
+	}
+
+	if len(positional) != 2 {
+		fmt.Println("Usage: focusd groups add <name> <pattern> [--regex] [--priority N]")
+		fmt.Println()
+		fmt.Println("Patterns are case-insensitive substrings of the window title,")
+		fmt.Println("or regular expressions with --regex. Higher priority wins.")
+		os.Exit(1)
+	}
+
+	if err := core.AddGroupPattern(positional[0], positional[1], isRegex, priority); err != nil {
+		ui.PrintError(err.Error())
+		os.Exit(1)
+	}
+	ui.PrintOK(fmt.Sprintf("Titles matching %q now group under %s.", positional[1], positional[0]))
+}
+
+func RunGroupsRemove(name string) {
+	if err := core.RemoveGroup(name); err != nil {
+		ui.PrintError(err.Error())
+		os.Exit(1)
+	}
+	if core.IsBuiltinGroup(name) {
+		ui.PrintOK(fmt.Sprintf("Built-in group %s disabled.", name))
+		return
+	}
+	ui.PrintOK(fmt.Sprintf("Group %s removed.", name))
+}
+
+func RunGroupsTest(title string) {
+	loadGroupRulesOrExit()
+
+	category, matched := core.TestGroupRules(title)
+	fmt.Println()
+	ui.PrintKeyValue("Title", title)
+	if category == "" {
+		ui.PrintKeyValue("Group", "(none - shown as its own entry)")
+	} else {
+		ui.PrintKeyValue("Group", category)
+	}
+
+	if len(matched) > 0 {
+		fmt.Println()
+		fmt.Println("  Matching rules:")
+		for _, r := range matched {
+			source := "built-in"
+			if r.Custom {
+				source = "custom"
+			}
+			fmt.Printf("    • %s (priority %d, %s)\n", r.Name, r.Priority, source)
+		}
+	}
+	fmt.Println()
+}
@@ -0,0 +1,305 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"focusd/core"
+	"focusd/storage"
+	"focusd/system"
+	"focusd/ui"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type importReport struct {
+	Read       int
+	Invalid    int
+	Duplicates int
+	Added      []*storage.Session
+	Dates      []string
+}
+
+func RunImport(args []string) {
+	dryRun := false
+	var files []string
+	for _, arg := range args[2:] {
+		switch arg {
+		case "--dry-run", "-n":
+			dryRun = true
+		default:
+			files = append(files, arg)
+		}
+	}
+
+	if len(files) != 1 {
+		fmt.Println("Usage: focusd import <file> [--dry-run]")
+		fmt.Println()
+		fmt.Println("Accepts session exports in CSV, JSON or NDJSON format, including encrypted ones.")
+		os.Exit(1)
+	}
+
+	if err := storage.Init(); err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to initialize: %v", err))
+		os.Exit(1)
+	}
+	defer storage.Close()
+
+	if !storage.IsConsentGranted() {
+		ui.PrintError("focusd is not initialized. Run 'focusd init' first.")
+		os.Exit(1)
+	}
+
+	sessions, invalid, err := readImportFile(files[0])
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to read %s: %v", files[0], err))
+		os.Exit(1)
+	}
+
+	report, err := planImport(sessions)
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to check existing sessions: %v", err))
+		os.Exit(1)
+	}
+	report.Invalid += invalid
+
+	fmt.Println()
+	ui.PrintKeyValue("Sessions in file", strconv.Itoa(report.Read+invalid))
+	ui.PrintKeyValue("Already present", strconv.Itoa(report.Duplicates))
+	ui.PrintKeyValue("Invalid rows", strconv.Itoa(report.Invalid))
+	ui.PrintKeyValue("New sessions", strconv.Itoa(len(report.Added)))
+	if len(report.Dates) > 0 {
+		ui.PrintKeyValue("Days affected", fmt.Sprintf("%d (%s to %s)", len(report.Dates), report.Dates[0], report.Dates[len(report.Dates)-1]))
+	}
+	fmt.Println()
+
+	if dryRun {
+		ui.PrintInfo("Dry run: nothing was written.")
+		return
+	}
+
+	if len(report.Added) == 0 {
+		ui.PrintInfo("Nothing to import.")
+		return
+	}
+
+	if err := core.ImportSessions(report.Added); err != nil {
+		ui.PrintError(fmt.Sprintf("Import failed: %v", err))
+		os.Exit(1)
+	}
+
+	ui.PrintOK(fmt.Sprintf("Imported %d sessions across %d days.", len(report.Added), len(report.Dates)))
+}
+
+func planImport(sessions []*storage.Session) (*importReport, error) {
+	report := &importReport{Read: len(sessions)}
+	if len(sessions) == 0 {
+		return report, nil
+	}
+
+	minDate, maxDate := sessions[0].Date, sessions[0].Date
+	for _, s := range sessions {
+		if s.Date < minDate {
+			minDate = s.Date
+		}
+		if s.Date > maxDate {
+			maxDate = s.Date
+		}
+	}
+
+	existing, err := storage.GetSessionKeys(minDate, maxDate)
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make(map[string]bool)
+	for _, s := range sessions {
+		core.PrepareImportedSession(s)
+		key := storage.SessionKey(s.ExeName, s.StartTime.Unix())
+		if existing[key] {
+			report.Duplicates++
+			continue
+		}
+		existing[key] = true
+		report.Added = append(report.Added, s)
+		dates[s.Date] = true
+	}
+
+	for d := range dates {
+		report.Dates = append(report.Dates, d)
+	}
+	sort.Strings(report.Dates)
+	return report, nil
+}
+
+func readImportFile(path string) ([]*storage.Session, int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if system.IsSealedArchive(data) {
+		passphrase, err := readExportPassphrase(false)
+		if err != nil {
+			return nil, 0, err
+		}
+		if data, err = system.OpenArchive(data, passphrase); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(trimmed) == 0 {
+		return nil, 0, fmt.Errorf("file is empty")
+	}
+
+	var rows []storage.Session
+	switch trimmed[0] {
+	case '[', '{':
+		rows, err = parseImportJSON(trimmed)
+	default:
+		rows, err = parseImportCSV(trimmed)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var sessions []*storage.Session
+	invalid := 0
+	for i := range rows {
+		s, ok := normalizeImportedSession(rows[i])
+		if !ok {
+			invalid++
+			continue
+		}
+		sessions = append(sessions, storage.SplitSessionByDay(s)...)
+	}
+	return sessions, invalid, nil
+}
+
+func parseImportJSON(data []byte) ([]storage.Session, error) {
+	if data[0] == '[' {
+		var rows []storage.Session
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return nil, err
+		}
+		return rows, nil
+	}
+
+	var export struct {
+		Sessions *[]storage.Session `json:"sessions"`
+	}
+	if err := json.Unmarshal(data, &export); err == nil && export.Sessions != nil {
+		return *export.Sessions, nil
+	}
+
+	var rows []storage.Session
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		var row struct {
+			Table string `json:"table"`
+			storage.Session
+		}
+		if err := json.Unmarshal(text, &row); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if row.Table != "" && row.Table != exportTableSessions {
+			continue
+		}
+		rows = append(rows, row.Session)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("export has no sessions; only session data can be imported")
+	}
+	return rows, nil
+}
+
+func parseImportCSV(data []byte) ([]storage.Session, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	cols := make(map[string]int)
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, required := range []string{"executable", "start time"} {
+		if _, ok := cols[required]; !ok {
+			return nil, fmt.Errorf("not a sessions CSV export (missing %q column)", required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := cols[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []storage.Session
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		s := storage.Session{
+			AppName:     field(record, "app name"),
+			ExeName:     field(record, "executable"),
+			WindowTitle: field(record, "window title"),
+			Date:        field(record, "date"),
+		}
+		s.StartTime, _ = time.Parse(time.RFC3339, field(record, "start time"))
+		s.EndTime, _ = time.Parse(time.RFC3339, field(record, "end time"))
+		s.DurationSecs, _ = strconv.Atoi(field(record, "duration (seconds)"))
+		rows = append(rows, s)
+	}
+	return rows, nil
+}
+
+func normalizeImportedSession(s storage.Session) (*storage.Session, bool) {
+	if s.ExeName == "" || s.StartTime.IsZero() {
+		return nil, false
+	}
+
+	s.ID = 0
+	s.StartTime = s.StartTime.Local()
+	if s.DurationSecs <= 0 && s.EndTime.After(s.StartTime) {
+		s.DurationSecs = int(s.EndTime.Sub(s.StartTime).Seconds())
+	}
+	if s.DurationSecs <= 0 {
+		return nil, false
+	}
+	if s.EndTime.IsZero() {
+		s.EndTime = s.StartTime.Add(time.Duration(s.DurationSecs) * time.Second)
+	}
+	s.EndTime = s.EndTime.Local()
+	if s.AppName == "" {
+		s.AppName = s.ExeName
+	}
+	s.Date = s.StartTime.Format("2006-01-02")
+	return &s, true
+}
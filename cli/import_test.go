@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"bytes"
+	"focusd/storage"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func isolateDataDir(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("APPDATA", dir)
+}
+
+func writeTempFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func sampleExport() *exportData {
+	start := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	return &exportData{
+		Apps: []storage.AppDailyStat{{Date: "2026-03-10", AppName: "VS Code", ExeName: "code", TotalDurationSecs: 600, OpenCount: 1}},
+		Sessions: []storage.Session{
+			{AppName: "VS Code", ExeName: "code", WindowTitle: "main.go, focusd", StartTime: start, EndTime: start.Add(10 * time.Minute), DurationSecs: 600, Date: "2026-03-10"},
+			{AppName: "Slack", ExeName: "slack", WindowTitle: "general", StartTime: start.Add(time.Hour), EndTime: start.Add(70 * time.Minute), DurationSecs: 600, Date: "2026-03-10"},
+		},
+	}
+}
+
+func TestReadImportFileFormats(t *testing.T) {
+	data := sampleExport()
+	for _, tt := range []struct {
+		format string
+		tables []string
+	}{
+		{"csv", []string{exportTableSessions}},
+		{"json", []string{exportTableApps, exportTableSessions}},
+		{"ndjson", []string{exportTableApps, exportTableSessions}},
+	} {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeExportTo(&buf, &exportOptions{Format: tt.format}, data, tt.tables); err != nil {
+				t.Fatal(err)
+			}
+
+			sessions, invalid, err := readImportFile(writeTempFile(t, "export."+tt.format, buf.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if invalid != 0 || len(sessions) != len(data.Sessions) {
+				t.Fatalf("read %d sessions, %d invalid; want %d, 0", len(sessions), invalid, len(data.Sessions))
+			}
+			for i, want := range data.Sessions {
+				got := sessions[i]
+				if got.ExeName != want.ExeName || got.WindowTitle != want.WindowTitle || got.DurationSecs != want.DurationSecs || !got.StartTime.Equal(want.StartTime) {
+					t.Errorf("session %d = %+v, want %+v", i, *got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReadImportFileCountsInvalidRows(t *testing.T) {
+	csv := "Date,App Name,Executable,Window Title,Start Time,End Time,Duration (seconds)\n" +
+		"2026-03-10,VS Code,code,main.go,2026-03-10T09:00:00Z,2026-03-10T09:10:00Z,600\n" +
+		"2026-03-10,VS Code,code,main.go,,,600\n" +
+		"2026-03-10,,,main.go,2026-03-10T10:00:00Z,,600\n" +
+		"2026-03-10,VS Code,code,main.go,2026-03-10T11:00:00Z,,0\n"
+
+	sessions, invalid, err := readImportFile(writeTempFile(t, "sessions.csv", []byte(csv)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || invalid != 3 {
+		t.Errorf("read %d sessions, %d invalid; want 1, 3", len(sessions), invalid)
+	}
+
+	ndjson := `{"table":"apps","date":"2026-03-10"}` + "\n" + `{"table":"sessions",`
+	if _, _, err := readImportFile(writeTempFile(t, "broken.ndjson", []byte(ndjson))); err == nil {
+		t.Error("truncated NDJSON line was accepted")
+	}
+}
+
+func TestPlanImportSkipsDuplicates(t *testing.T) {
+	isolateDataDir(t)
+	if err := storage.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer storage.Close()
+
+	start := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	if err := storage.InsertSession(&storage.Session{
+		AppName: "Chrome", ExeName: "chrome", StartTime: start, EndTime: start.Add(time.Minute), DurationSecs: 60, Date: "2026-03-10",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	session := func(exe string, at time.Duration) *storage.Session {
+		return &storage.Session{
+			AppName: "Chrome", ExeName: exe, StartTime: start.Add(at), EndTime: start.Add(at + time.Minute), DurationSecs: 60, Date: "2026-03-10",
+		}
+	}
+	report, err := planImport([]*storage.Session{
+		session("Chrome.EXE", 0),
+		session("chrome", time.Hour),
+		session("CHROME", time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Duplicates != 2 || len(report.Added) != 1 {
+		t.Fatalf("duplicates = %d, added = %d; want 2 and 1", report.Duplicates, len(report.Added))
+	}
+	if len(report.Dates) != 1 || report.Dates[0] != "2026-03-10" {
+		t.Errorf("dates = %v, want [2026-03-10]", report.Dates)
+	}
+}
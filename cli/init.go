@@ -34,7 +34,8 @@ func RunInit() {
 	fmt.Println("  • No keystrokes are recorded")
 	fmt.Println("  • No network traffic is monitored")
 	fmt.Println("  • No data leaves your computer")
-	fmt.Println("  • All data stored locally in %APPDATA%\\focusd")
+	dataDir, _ := storage.GetDataDir()
+	fmt.Printf("  • All data stored locally in %s\n", dataDir)
 	fmt.Println("  • Data auto-deleted after 7 days (configurable)")
 	fmt.Println()
 
@@ -51,7 +52,7 @@ func RunInit() {
 	}
 
 	fmt.Println()
-	fmt.Print("Enable auto-start on Windows boot? [y/N]: ")
+	fmt.Print("Enable auto-start on login? [y/N]: ")
 	autoStartResp, _ := reader.ReadString('\n')
 	autoStart := strings.TrimSpace(strings.ToLower(autoStartResp)) == "y" || strings.TrimSpace(strings.ToLower(autoStartResp)) == "yes"
 
@@ -2,9 +2,11 @@ package cli
 
 import (
 	"fmt"
+	"focusd/core"
 	"focusd/system"
 	"focusd/ui"
 	"strconv"
+	"strings"
 )
 
 func RunLimits(args []string) {
@@ -13,44 +15,124 @@ func RunLimits(args []string) {
 		return
 	}
 
-	app := args[2]
+	if args[2] == "warnings" {
+		runLimitWarnings(args[3:])
+		return
+	}
 
 	if len(args) < 4 {
+		printLimitUsage()
+		return
+	}
 
-		ui.PrintError("Usage: focusd limit <app_name> <minutes>")
+	target := system.ParseLimitTarget(strings.Join(args[2:len(args)-1], " "))
+	minutes, err := strconv.Atoi(args[len(args)-1])
+	if err != nil {
+		ui.PrintError("Invalid minutes.")
+		printLimitUsage()
 		return
 	}
 
-	minutes, err := strconv.Atoi(args[3])
+	target, err = resolveLimitTarget(target, minutes)
 	if err != nil {
-		ui.PrintError("Invalid minutes. usage: focusd limit <app> <minutes>")
+		ui.PrintError(err.Error())
 		return
 	}
 
-	if err := system.SetAppTimeLimit(app, minutes); err != nil {
+	if err := system.SetTimeLimit(target, minutes); err != nil {
 		ui.PrintError(fmt.Sprintf("Failed to set limit: %v", err))
 		return
 	}
 
 	if minutes > 0 {
-		ui.PrintOK(fmt.Sprintf("Limit set: %s -> %d mins/day", app, minutes))
+		ui.PrintOK(fmt.Sprintf("Limit set: %s -> %d mins/day", target, minutes))
+	} else {
+		ui.PrintOK(fmt.Sprintf("Limit removed for %s", target))
+	}
+}
+
+func printLimitUsage() {
+	fmt.Println("Usage: focusd limit <target> <minutes>")
+	fmt.Println()
+	fmt.Println("  <target> is an app exe (discord), a site group (site:YouTube)")
+	fmt.Println("  or a category (category:Social). Use 0 minutes to remove a limit.")
+	fmt.Println()
+	fmt.Println("       focusd limit warnings <percent> <minutes>")
+	fmt.Println()
+	fmt.Println("  Warn once a day when a target reaches <percent> of its limit and when")
+	fmt.Println("  <minutes> remain. Use 0 to turn either warning off.")
+}
+
+func runLimitWarnings(args []string) {
+	if len(args) == 0 {
+		printLimitWarnings()
+		return
+	}
+	if len(args) != 2 {
+		printLimitUsage()
+		return
+	}
+
+	percent, err1 := strconv.Atoi(args[0])
+	minutes, err2 := strconv.Atoi(args[1])
+	if err1 != nil || err2 != nil {
+		ui.PrintError("Percent and minutes must be numbers.")
+		return
+	}
+	if err := system.SetLimitWarnings(percent, minutes); err != nil {
+		ui.PrintError(err.Error())
+		return
+	}
+	ui.PrintOK("Limit warnings updated.")
+	printLimitWarnings()
+}
+
+func printLimitWarnings() {
+	percent, minutes := system.GetLimitWarningPercent(), system.GetLimitWarningMinutes()
+	if percent > 0 {
+		fmt.Printf("  Warn at %d%% of a limit\n", percent)
+	} else {
+		fmt.Println("  Percent warning off")
+	}
+	if minutes > 0 {
+		fmt.Printf("  Warn when %d min remain\n", minutes)
 	} else {
-		ui.PrintOK(fmt.Sprintf("Limit removed for %s", app))
+		fmt.Println("  Minutes-left warning off")
+	}
+}
+
+func resolveLimitTarget(target system.LimitTarget, minutes int) (system.LimitTarget, error) {
+	if target.Name == "" {
+		return target, fmt.Errorf("limit target cannot be empty")
+	}
+	if minutes <= 0 || target.Kind != system.LimitKindCategory {
+		return target, nil
 	}
+	for _, c := range core.GetCategories() {
+		if strings.EqualFold(c.Name, target.Name) {
+			target.Name = c.Name
+			return target, nil
+		}
+	}
+	return target, fmt.Errorf("unknown category %q; see 'focusd categories'", target.Name)
 }
 
 func showLimits() {
 	ui.PrintHeader()
-	fmt.Println("App Time Limits:")
+	fmt.Println("Daily Time Limits:")
 	fmt.Println()
 
-	limits := system.GetAppTimeLimits()
+	limits := system.GetTimeLimits()
 	if len(limits) == 0 {
 		fmt.Println("  No limits set.")
+		fmt.Println()
+		printLimitUsage()
 		return
 	}
 
-	for app, min := range limits {
-		fmt.Printf("  %-20s : %d mins\n", app, min)
+	for _, l := range limits {
+		fmt.Printf("  %-30s : %d mins\n", l.Target, l.Minutes)
 	}
+	fmt.Println()
+	printLimitWarnings()
 }
@@ -21,7 +21,11 @@ func SetRetentionLogic(days int) error {
 		}
 	}
 
-	ui.PrintOK(fmt.Sprintf("Retention set to %d days.", days))
+	if err := storage.SetRetentionDays(days); err != nil {
+		return fmt.Errorf("failed to set retention: %w", err)
+	}
+
+	ui.PrintOK(fmt.Sprintf("Raw sessions kept for %d days.", storage.GetRetentionDays()))
 	return nil
 }
 
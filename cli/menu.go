@@ -4,13 +4,14 @@ import (
 	"bufio"
 	"fmt"
 	"focusd/core"
+	"focusd/ipc"
 	"focusd/storage"
 	"focusd/system"
 	"focusd/ui"
 	"os"
-	"os/exec"
 	"strconv"
 	"strings"
+	"time"
 )
 
 func RunInteractiveMenu() {
@@ -31,11 +32,7 @@ func RunInteractiveMenu() {
 		fmt.Println("║                    Password Required                     ║")
 		fmt.Println("╚══════════════════════════════════════════════════════════╝")
 		fmt.Println()
-		fmt.Print("Enter password: ")
-		pwd, _ := reader.ReadString('\n')
-		pwd = strings.TrimSpace(pwd)
-		if !system.CheckPassword(pwd) {
-			ui.PrintError("Incorrect password!")
+		if !promptPassword(reader, "Enter password: ") {
 			fmt.Println("\nPress Enter to exit...")
 			reader.ReadString('\n')
 			return
@@ -105,10 +102,7 @@ func printCurrentStatus() {
 		return
 	}
 
-	isRunning := false
-	if system.GetProcessCount(system.DaemonProcessName) > 1 {
-		isRunning = true
-	}
+	isRunning := isDaemonRunning()
 
 	if isRunning {
 		ui.PrintStatus("Daemon", "RUNNING", true)
@@ -164,7 +158,7 @@ func handleMenuStart(reader *bufio.Reader) {
 		return
 	}
 
-	if system.GetProcessCount(system.DaemonProcessName) > 1 {
+	if isDaemonRunning() {
 		ui.PrintInfo("Tracker is already running!")
 	} else {
 		StartDaemonProcess()
@@ -218,7 +212,7 @@ func handleMenuExport(reader *bufio.Reader) {
 		waitForEnterWithReader(reader)
 		return
 	}
-	RunExport()
+	RunExport(nil)
 	waitForEnterWithReader(reader)
 }
 
@@ -286,7 +280,7 @@ func handleMenuSettings(reader *bufio.Reader) {
 
 		switch input {
 		case "1":
-			fmt.Print("Enter retention days (1-30): ")
+			fmt.Printf("Enter session retention days (%d-%d): ", storage.MinRetentionDays, storage.MaxRetentionDays)
 			daysStr, _ := reader.ReadString('\n')
 			daysStr = strings.TrimSpace(daysStr)
 			if days, err := strconv.Atoi(daysStr); err == nil {
@@ -351,21 +345,22 @@ func handleClearData(reader *bufio.Reader) {
 
 		switch input {
 		case "1":
-			if err := storage.ClearLastHourData(); err != nil {
+			if err := clearDataSince(time.Now().Add(-1 * time.Hour)); err != nil {
 				ui.PrintError(fmt.Sprintf("Failed: %v", err))
 			} else {
 				ui.PrintOK("Cleared last hour of data")
 			}
 			waitForEnterWithReader(reader)
 		case "2":
-			if err := storage.ClearLast24HoursData(); err != nil {
+			if err := clearDataSince(time.Now().Add(-24 * time.Hour)); err != nil {
 				ui.PrintError(fmt.Sprintf("Failed: %v", err))
 			} else {
 				ui.PrintOK("Cleared last 24 hours of data")
 			}
 			waitForEnterWithReader(reader)
 		case "3":
-			if err := storage.ClearTodayData(); err != nil {
+			y, m, d := time.Now().Date()
+			if err := clearDataSince(time.Date(y, m, d, 0, 0, 0, 0, time.Local)); err != nil {
 				ui.PrintError(fmt.Sprintf("Failed: %v", err))
 			} else {
 				ui.PrintOK("Cleared today's data")
@@ -376,7 +371,7 @@ func handleClearData(reader *bufio.Reader) {
 			confirm, _ := reader.ReadString('\n')
 			confirm = strings.TrimSpace(confirm)
 			if strings.ToLower(confirm) == "yes" {
-				if err := storage.ClearAllTrackingData(); err != nil {
+				if err := clearAllData(); err != nil {
 					ui.PrintError(fmt.Sprintf("Failed: %v", err))
 				} else {
 					ui.PrintOK("Cleared all tracking data")
@@ -393,7 +388,7 @@ func handleClearData(reader *bufio.Reader) {
 			if err != nil || hours < 1 {
 				ui.PrintError("Invalid number. Enter a positive number.")
 			} else {
-				if err := storage.ClearLastNHoursData(hours); err != nil {
+				if err := clearDataSince(time.Now().Add(-time.Duration(hours) * time.Hour)); err != nil {
 					ui.PrintError(fmt.Sprintf("Failed: %v", err))
 				} else {
 					ui.PrintOK(fmt.Sprintf("Cleared last %d hour(s) of data", hours))
@@ -406,6 +401,16 @@ func handleClearData(reader *bufio.Reader) {
 	}
 }
 
+func clearDataSince(since time.Time) error {
+	ipc.SendRequest(ipc.Request{Command: ipc.CommandDiscardSince, Since: since})
+	return core.ClearDataSince(since)
+}
+
+func clearAllData() error {
+	ipc.SendRequest(ipc.Request{Command: ipc.CommandDiscardSince})
+	return core.ClearAllData()
+}
+
 func handleMenuCustomize(reader *bufio.Reader) {
 	for {
 		ui.ClearScreen()
@@ -536,11 +541,23 @@ func handleWhitelist(reader *bufio.Reader) {
 			fmt.Println("  No apps whitelisted yet.")
 		}
 
+		sites := system.GetWhitelistSites()
+		if len(sites) > 0 {
+			fmt.Println()
+			fmt.Println("  Whitelisted sites:")
+			for _, p := range sites {
+				fmt.Printf("    • %s\n", p)
+			}
+		}
+
 		fmt.Println()
 		fmt.Println("  1. Add app to whitelist")
 		if len(whitelist) > 0 {
 			fmt.Println("  2. Remove app from whitelist")
 		}
+		if len(sites) > 0 {
+			fmt.Println("  3. Remove site from whitelist")
+		}
 		fmt.Println()
 		fmt.Println("  0. Back")
 		fmt.Println()
@@ -584,7 +601,7 @@ func handleWhitelist(reader *bufio.Reader) {
 			} else {
 				found := false
 				for _, a := range whitelist {
-					if strings.EqualFold(a, name) || strings.EqualFold(a, name+".exe") {
+					if a == system.CanonicalExeName(name) {
 						system.RemoveWhitelistApp(a)
 						ui.PrintOK(fmt.Sprintf("Removed %s from whitelist", a))
 						found = true
@@ -596,6 +613,19 @@ func handleWhitelist(reader *bufio.Reader) {
 				}
 			}
 			waitForEnterWithReader(reader)
+		case "3":
+			if len(sites) == 0 {
+				continue
+			}
+			fmt.Print("\n  Enter site to remove: ")
+			name, _ := reader.ReadString('\n')
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			system.RemoveWhitelistSite(name)
+			ui.PrintOK(fmt.Sprintf("Removed %s from site whitelist", name))
+			waitForEnterWithReader(reader)
 		case "0", "":
 			return
 		}
@@ -621,18 +651,20 @@ func handleFocusTools(reader *bufio.Reader) {
 			ui.PrintStatus("Break Reminder", fmt.Sprintf("Every %d min", system.GetBreakReminderMinutes()), true)
 		}
 
-		limits := system.GetAppTimeLimits()
+		limits := system.GetTimeLimits()
 		if len(limits) > 0 {
-			ui.PrintStatus("App Limits", fmt.Sprintf("%d apps", len(limits)), true)
+			ui.PrintStatus("Time Limits", fmt.Sprintf("%d targets", len(limits)), true)
 		}
 
 		ui.PrintStatus("Snooze Duration", fmt.Sprintf("%d min", system.GetSnoozeDurationMinutes()), false)
+		ui.PrintStatus("Idle After", fmt.Sprintf("%d min", system.GetIdleThresholdMinutes()), false)
 
 		fmt.Println()
 		fmt.Printf("     %s1.%s Pomodoro Timer\n", ui.Cyan, ui.Reset)
 		fmt.Printf("     %s2.%s Break Reminder\n", ui.Cyan, ui.Reset)
-		fmt.Printf("     %s3.%s App Time Limits\n", ui.Cyan, ui.Reset)
+		fmt.Printf("     %s3.%s Time Limits\n", ui.Cyan, ui.Reset)
 		fmt.Printf("     %s4.%s Snooze Duration\n", ui.Cyan, ui.Reset)
+		fmt.Printf("     %s5.%s Idle Detection\n", ui.Cyan, ui.Reset)
 		fmt.Println()
 		fmt.Printf("     %s0.%s Back\n", ui.Dim, ui.Reset)
 		fmt.Println()
@@ -649,6 +681,8 @@ func handleFocusTools(reader *bufio.Reader) {
 			handleAppTimeLimits(reader)
 		case "4":
 			handleSnoozeDuration(reader)
+		case "5":
+			handleIdleDetection(reader)
 		case "0", "":
 			return
 		}
@@ -765,24 +799,28 @@ func handleAppTimeLimits(reader *bufio.Reader) {
 	for {
 		ui.ClearScreen()
 		fmt.Println()
-		fmt.Println("─────────────────── App Time Limits ───────────────────")
+		fmt.Println("───────────────────── Time Limits ─────────────────────")
 		fmt.Println()
-		fmt.Println("  Set daily time limits per app. Notification shows once per day.")
+		fmt.Println("  Set daily time limits per app, site group or category.")
 		fmt.Println()
 
-		limits := system.GetAppTimeLimits()
+		limits := system.GetTimeLimits()
 		if len(limits) > 0 {
 			fmt.Println("  Current limits:")
-			for app, mins := range limits {
-				fmt.Printf("    • %s: %d min/day\n", app, mins)
+			for _, l := range limits {
+				fmt.Printf("    • %s: %d min/day\n", l.Target, l.Minutes)
 			}
 		} else {
 			fmt.Println("  No limits set.")
 		}
 
 		fmt.Println()
-		fmt.Println("  1. Add app limit")
-		fmt.Println("  2. Remove app limit")
+		printLimitWarnings()
+
+		fmt.Println()
+		fmt.Println("  1. Add limit")
+		fmt.Println("  2. Remove limit")
+		fmt.Println("  3. Warning thresholds")
 		fmt.Println()
 		fmt.Println("  0. Back")
 		fmt.Println()
@@ -792,7 +830,7 @@ func handleAppTimeLimits(reader *bufio.Reader) {
 
 		switch input {
 		case "1":
-			fmt.Print("Enter app exe name (e.g., discord or discord.exe): ")
+			fmt.Print("Enter app exe, site:<group> or category:<name> (e.g., discord, site:YouTube): ")
 			name, _ := reader.ReadString('\n')
 			name = strings.TrimSpace(name)
 			if name == "" {
@@ -804,25 +842,27 @@ func handleAppTimeLimits(reader *bufio.Reader) {
 			mins, _ := reader.ReadString('\n')
 			mins = strings.TrimSpace(mins)
 			if m, err := strconv.Atoi(mins); err == nil && m > 0 {
-				system.SetAppTimeLimit(name, m)
-				ui.PrintOK(fmt.Sprintf("Limit set: %s max %d min/day", name, m))
+				target, err := resolveLimitTarget(system.ParseLimitTarget(name), m)
+				if err != nil {
+					ui.PrintError(err.Error())
+				} else if err := system.SetTimeLimit(target, m); err != nil {
+					ui.PrintError(fmt.Sprintf("Failed to set limit: %v", err))
+				} else {
+					ui.PrintOK(fmt.Sprintf("Limit set: %s max %d min/day", target, m))
+				}
 			} else {
 				ui.PrintError("Invalid minutes. Enter a positive number.")
 			}
 			waitForEnterWithReader(reader)
 		case "2":
 			if len(limits) == 0 {
-				ui.PrintError("No app limits to remove")
+				ui.PrintError("No limits to remove")
 				waitForEnterWithReader(reader)
 				continue
 			}
-			fmt.Println("\n  Select app limit to remove:")
-			apps := make([]string, 0, len(limits))
-			i := 1
-			for app, mins := range limits {
-				fmt.Printf("    %d. %s (%d min/day)\n", i, app, mins)
-				apps = append(apps, app)
-				i++
+			fmt.Println("\n  Select limit to remove:")
+			for i, l := range limits {
+				fmt.Printf("    %d. %s (%d min/day)\n", i+1, l.Target, l.Minutes)
 			}
 			fmt.Print("\n  Enter number (or name): ")
 			name, _ := reader.ReadString('\n')
@@ -830,26 +870,41 @@ func handleAppTimeLimits(reader *bufio.Reader) {
 			if name == "" {
 				continue
 			}
-			if num, err := strconv.Atoi(name); err == nil && num >= 1 && num <= len(apps) {
-				removed := apps[num-1]
-				system.RemoveAppTimeLimit(removed)
+			if num, err := strconv.Atoi(name); err == nil && num >= 1 && num <= len(limits) {
+				removed := limits[num-1].Target
+				system.SetTimeLimit(removed, 0)
 				ui.PrintOK(fmt.Sprintf("Removed limit for %s", removed))
 			} else {
 				found := false
-				normalizedName := strings.ToLower(name)
-				if !strings.HasSuffix(normalizedName, ".exe") {
-					normalizedName += ".exe"
-				}
-				if _, ok := limits[normalizedName]; ok {
-					system.RemoveAppTimeLimit(normalizedName)
-					ui.PrintOK(fmt.Sprintf("Removed limit for %s", normalizedName))
-					found = true
+				target := system.ParseLimitTarget(name)
+				for _, l := range limits {
+					if l.Target.Kind == target.Kind && strings.EqualFold(l.Target.Name, target.Name) {
+						system.SetTimeLimit(l.Target, 0)
+						ui.PrintOK(fmt.Sprintf("Removed limit for %s", l.Target))
+						found = true
+						break
+					}
 				}
 				if !found {
-					ui.PrintError(fmt.Sprintf("'%s' not found in app limits", name))
+					ui.PrintError(fmt.Sprintf("'%s' not found in limits", name))
 				}
 			}
 			waitForEnterWithReader(reader)
+		case "3":
+			fmt.Print("Warn at percent of limit (0 = off): ")
+			pct, _ := reader.ReadString('\n')
+			fmt.Print("Warn when minutes remain (0 = off): ")
+			mins, _ := reader.ReadString('\n')
+			p, err1 := strconv.Atoi(strings.TrimSpace(pct))
+			m, err2 := strconv.Atoi(strings.TrimSpace(mins))
+			if err1 != nil || err2 != nil {
+				ui.PrintError("Enter numbers for both values.")
+			} else if err := system.SetLimitWarnings(p, m); err != nil {
+				ui.PrintError(err.Error())
+			} else {
+				ui.PrintOK("Limit warnings updated.")
+			}
+			waitForEnterWithReader(reader)
 		case "0", "":
 			return
 		}
@@ -865,6 +920,7 @@ func handlePasswordSettings(reader *bufio.Reader) {
 
 		if system.IsPasswordEnabled() {
 			fmt.Println("  Status: PASSWORD SET")
+			fmt.Printf("  Recovery codes left: %d\n", system.RecoveryCodesRemaining())
 		} else {
 			fmt.Println("  Status: No password")
 		}
@@ -872,6 +928,7 @@ func handlePasswordSettings(reader *bufio.Reader) {
 		fmt.Println()
 		fmt.Println("  1. Set password")
 		fmt.Println("  2. Remove password")
+		fmt.Println("  3. Regenerate recovery codes")
 		fmt.Println()
 		fmt.Println("  0. Back")
 		fmt.Println()
@@ -885,31 +942,60 @@ func handlePasswordSettings(reader *bufio.Reader) {
 			pwd, _ := reader.ReadString('\n')
 			pwd = strings.TrimSpace(pwd)
 			if pwd != "" {
-				system.SetPassword(pwd)
-				ui.PrintOK("Password set! Menu will now require password.")
+				if err := system.SetPassword(pwd); err != nil {
+					ui.PrintError(fmt.Sprintf("Failed to set password: %v", err))
+				} else {
+					ui.PrintOK("Password set! Menu and protected commands will now require it.")
+					showNewRecoveryCodes()
+				}
 			}
 			waitForEnterWithReader(reader)
 		case "2":
 			if system.IsPasswordEnabled() {
-				fmt.Print("Enter current password to remove: ")
-				pwd, _ := reader.ReadString('\n')
-				pwd = strings.TrimSpace(pwd)
-				if system.CheckPassword(pwd) {
+				if promptPassword(reader, "Enter current password to remove: ") {
 					system.SetPassword("")
 					ui.PrintOK("Password removed")
-				} else {
-					ui.PrintError("Incorrect password")
 				}
 			} else {
 				ui.PrintInfo("No password set")
 			}
 			waitForEnterWithReader(reader)
+		case "3":
+			if !system.IsPasswordEnabled() {
+				ui.PrintInfo("No password set")
+			} else if promptPassword(reader, "Enter current password: ") {
+				showNewRecoveryCodes()
+			}
+			waitForEnterWithReader(reader)
 		case "0", "":
 			return
 		}
 	}
 }
 
+func promptPassword(reader *bufio.Reader, prompt string) bool {
+	if err := system.UserConfigError(); err != nil {
+		ui.PrintError(fmt.Sprintf("Cannot check the password: %v", err))
+		return false
+	}
+	for {
+		if passwordLocked() {
+			return false
+		}
+
+		fmt.Print(prompt)
+		pwd, _ := reader.ReadString('\n')
+		pwd = strings.TrimSpace(pwd)
+		if pwd == "" {
+			return false
+		}
+		if checkPassword(pwd) {
+			return true
+		}
+		ui.PrintError("Incorrect password!")
+	}
+}
+
 func handleMenuUninstall(reader *bufio.Reader) {
 	fmt.Println()
 	RunUninstall()
@@ -924,10 +1010,7 @@ func waitForEnterWithReader(reader *bufio.Reader) {
 func showStatusInMenu() {
 	ui.PrintHeader()
 
-	isRunning := false
-	if system.GetProcessCount(system.DaemonProcessName) > 1 {
-		isRunning = true
-	}
+	isRunning := isDaemonRunning()
 
 	if isRunning {
 		ui.PrintOK("Daemon: RUNNING")
@@ -1014,7 +1097,56 @@ func handleSnoozeDuration(reader *bufio.Reader) {
 	waitForEnterWithReader(reader)
 }
 
+func handleIdleDetection(reader *bufio.Reader) {
+	for {
+		ui.ClearScreen()
+		fmt.Println()
+		fmt.Println("─────────────────── Idle Detection ───────────────────")
+		fmt.Println()
+		fmt.Printf("  Idle after: %d min without keyboard/mouse input\n", system.GetIdleThresholdMinutes())
+		if system.GetRecordIdleSessions() {
+			fmt.Println("  Idle periods: RECORDED as \"Idle\" sessions")
+		} else {
+			fmt.Println("  Idle periods: NOT RECORDED")
+		}
+		fmt.Println()
+		fmt.Println("  1. Set idle threshold")
+		fmt.Println("  2. Toggle recording idle sessions")
+		fmt.Println()
+		fmt.Println("  0. Back")
+		fmt.Println()
+		fmt.Print("Enter choice: ")
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+
+		switch input {
+		case "1":
+			fmt.Print("Idle after how many minutes: ")
+			mins, _ := reader.ReadString('\n')
+			mins = strings.TrimSpace(mins)
+			if m, err := strconv.Atoi(mins); err == nil && m > 0 {
+				system.SetIdleThresholdMinutes(m)
+				ui.PrintOK(fmt.Sprintf("Tracking stops after %d min without input", m))
+			} else {
+				ui.PrintError("Invalid number. Enter a positive number.")
+			}
+			waitForEnterWithReader(reader)
+		case "2":
+			enabled := !system.GetRecordIdleSessions()
+			system.SetRecordIdleSessions(enabled)
+			if enabled {
+				ui.PrintOK("Idle periods will be recorded as \"Idle\" sessions")
+			} else {
+				ui.PrintOK("Idle periods will not be recorded")
+			}
+			waitForEnterWithReader(reader)
+		case "0", "":
+			return
+		}
+	}
+}
+
 func handleStarOnGitHub() {
 	repoURL := fmt.Sprintf("https://github.com/%s/%s", system.RepoOwner, system.RepoName)
-	exec.Command("cmd", "/c", "start", repoURL).Start()
+	system.OpenURL(repoURL)
 }
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"focusd/ipc"
 	"focusd/storage"
 	"focusd/ui"
 )
@@ -17,7 +18,7 @@ func RunPause() {
 		return
 	}
 
-	if err := storage.SetPaused(true); err != nil {
+	if err := setPaused(true); err != nil {
 		ui.PrintError(fmt.Sprintf("Failed to pause tracking: %v", err))
 		return
 	}
@@ -37,10 +38,21 @@ func RunResume() {
 		return
 	}
 
-	if err := storage.SetPaused(false); err != nil {
+	if err := setPaused(false); err != nil {
 		ui.PrintError(fmt.Sprintf("Failed to resume tracking: %v", err))
 		return
 	}
 
 	ui.PrintOK("Tracking resumed.")
 }
+
+func setPaused(paused bool) error {
+	command := ipc.CommandResume
+	if paused {
+		command = ipc.CommandPause
+	}
+	if _, err := ipc.Send(command); err == nil {
+		return nil
+	}
+	return storage.SetPaused(paused)
+}
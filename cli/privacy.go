@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"fmt"
+	"focusd/system"
+	"focusd/ui"
+	"os"
+	"sort"
+	"strings"
+)
+
+func HandlePrivacyCommand(args []string) {
+	if len(args) < 3 {
+		RunPrivacyStatus()
+		return
+	}
+
+	switch args[2] {
+	case "status":
+		RunPrivacyStatus()
+	case "mode":
+		if len(args) < 4 {
+			fmt.Printf("Usage: focusd privacy mode <%s>\n", strings.Join(system.TitleModes, "|"))
+			os.Exit(1)
+		}
+		RunPrivacyMode(args[3])
+	case "redact":
+		handleRedact(args)
+	default:
+		fmt.Printf("Unknown privacy command: %s\n", args[2])
+		fmt.Println("Available: status, mode, redact")
+		os.Exit(1)
+	}
+}
+
+func RunPrivacyStatus() {
+	ui.PrintSectionHeader("Window Title Privacy")
+	printTitlePrivacy()
+	fmt.Println()
+	fmt.Println("  Modes:")
+	fmt.Println("    full      Store the whole title (after redaction)")
+	fmt.Println("    redacted  Store only the cleaned title or site category")
+	fmt.Println("    hashed    Store a keyed hash of the cleaned title")
+	fmt.Println("    none      Store no title at all")
+	fmt.Println()
+	fmt.Println("  Change with: focusd privacy mode <mode>")
+	fmt.Println("  Redact with: focusd privacy redact add <regex|preset>")
+	fmt.Println()
+}
+
+func printTitlePrivacy() {
+	ui.PrintKeyValue("Title mode", system.GetTitlePrivacyMode())
+
+	redactions := system.GetTitleRedactions()
+	if len(redactions) == 0 {
+		ui.PrintKeyValue("Redactions", "none")
+	} else {
+		ui.PrintKeyValue("Redactions", fmt.Sprintf("%d pattern(s)", len(redactions)))
+		for _, p := range redactions {
+			fmt.Printf("    • %s\n", p)
+		}
+	}
+
+	history := system.GetTitlePrivacyHistory()
+	if len(history) > 0 {
+		fmt.Println("  Mode changes:")
+		for i := len(history) - 1; i >= 0 && i >= len(history)-5; i-- {
+			fmt.Printf("    %s  -> %s\n", history[i].ChangedAt.Format("2006-01-02 15:04"), history[i].Mode)
+		}
+	}
+}
+
+func RunPrivacyMode(mode string) {
+	if err := system.SetTitlePrivacyMode(mode); err != nil {
+		ui.PrintError(err.Error())
+		os.Exit(1)
+	}
+	ui.PrintOK(fmt.Sprintf("Title mode set to %s.", system.GetTitlePrivacyMode()))
+	fmt.Println("Applies to new sessions; existing history is unchanged.")
+}
+
+func handleRedact(args []string) {
+	if len(args) < 4 || args[3] == "list" {
+		ui.PrintSectionHeader("Title Redactions")
+		redactions := system.GetTitleRedactions()
+		if len(redactions) == 0 {
+			fmt.Println("  No redaction patterns.")
+		}
+		for _, p := range redactions {
+			fmt.Printf("   • %s\n", p)
+		}
+		fmt.Println()
+
+		var presets []string
+		for name := range system.RedactionPresets {
+			presets = append(presets, name)
+		}
+		sort.Strings(presets)
+		fmt.Printf("  Presets: %s\n", strings.Join(presets, ", "))
+		fmt.Println()
+		return
+	}
+
+	if len(args) < 5 {
+		fmt.Printf("Usage: focusd privacy redact %s <regex|preset>\n", args[3])
+		os.Exit(1)
+	}
+
+	switch args[3] {
+	case "add":
+		pattern, err := system.AddTitleRedaction(args[4])
+		if err != nil {
+			ui.PrintError(err.Error())
+			os.Exit(1)
+		}
+		ui.PrintOK(fmt.Sprintf("Redacting: %s", pattern))
+	case "remove":
+		removed, err := system.RemoveTitleRedaction(args[4])
+		if err != nil {
+			ui.PrintError(err.Error())
+			os.Exit(1)
+		}
+		if !removed {
+			ui.PrintInfo("No such redaction pattern.")
+			return
+		}
+		ui.PrintOK("Redaction removed.")
+	default:
+		fmt.Printf("Unknown redact command: %s\n", args[3])
+		fmt.Println("Available: list, add, remove")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"focusd/core"
+	"focusd/storage"
+	"focusd/ui"
+	"io"
+	"os"
+	"time"
+)
+
+func RunRebuildAggregates(args []string) {
+	fs := flag.NewFlagSet("rebuild-aggregates", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	from := fs.String("from", "", "")
+	to := fs.String("to", "", "")
+
+	usage := func(msg string) {
+		ui.PrintError(msg)
+		fmt.Println("Usage: focusd rebuild-aggregates [--from YYYY-MM-DD] [--to YYYY-MM-DD]")
+		os.Exit(1)
+	}
+
+	if err := fs.Parse(args[2:]); err != nil {
+		usage(err.Error())
+	}
+	for _, d := range []string{*from, *to} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			usage(fmt.Sprintf("invalid date %q, expected YYYY-MM-DD", d))
+		}
+	}
+
+	if err := storage.Init(); err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to initialize: %v", err))
+		os.Exit(1)
+	}
+	defer storage.Close()
+
+	dates, err := storage.GetSessionDates(*from, *to)
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to read sessions: %v", err))
+		os.Exit(1)
+	}
+	if len(dates) == 0 {
+		ui.PrintInfo("No sessions in that range; nothing to rebuild.")
+		return
+	}
+
+	if err := core.RebuildAggregates(dates); err != nil {
+		ui.PrintError(fmt.Sprintf("Rebuild failed: %v", err))
+		os.Exit(1)
+	}
+
+	ui.PrintOK(fmt.Sprintf("Rebuilt daily totals for %d day(s): %s to %s.", len(dates), dates[0], dates[len(dates)-1]))
+	fmt.Printf("Days older than %d days have no raw sessions and were left unchanged.\n", storage.GetRetentionDays())
+}
@@ -3,13 +3,14 @@ package cli
 import (
 	"bufio"
 	"fmt"
+	"focusd/storage"
 	"focusd/system"
 	"focusd/ui"
 	"os"
 	"strings"
 )
 
-func RunResetPassword() {
+func RunResetPassword(args []string) {
 	reader := bufio.NewReader(os.Stdin)
 
 	fmt.Println()
@@ -23,21 +24,72 @@ func RunResetPassword() {
 		return
 	}
 
-	fmt.Println("  [!] Warning: This will remove your password protection.")
+	if system.RecoveryCodesRemaining() == 0 {
+		ui.PrintError("No recovery codes are left for this password.")
+		if path, err := system.GetUserConfigPath(); err == nil && path != "" {
+			fmt.Printf("  To remove the password, delete the \"password_hash\" line from %s.\n", path)
+		}
+		os.Exit(1)
+	}
+
+	if err := storage.Init(); err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to open database: %v", err))
+		os.Exit(1)
+	}
+	defer storage.Close()
+
+	if passwordLocked() {
+		os.Exit(1)
+	}
+
+	code := ""
+	if len(args) > 2 {
+		code = args[2]
+	} else {
+		fmt.Print("Enter a recovery code: ")
+		code, _ = reader.ReadString('\n')
+	}
+
+	used := system.UseRecoveryCode(strings.TrimSpace(code))
+	recordPasswordAttempt(used)
+	if !used {
+		ui.PrintError("Invalid recovery code.")
+		os.Exit(1)
+	}
+	ui.PrintOK("Recovery code accepted. It cannot be used again.")
 	fmt.Println()
-	fmt.Print("Type 'yes' to confirm password reset: ")
-	confirm, _ := reader.ReadString('\n')
-	confirm = strings.TrimSpace(confirm)
 
-	if strings.ToLower(confirm) != "yes" {
-		ui.PrintInfo("Cancelled")
+	fmt.Print("Enter new password (leave empty to remove protection): ")
+	pwd, _ := reader.ReadString('\n')
+	pwd = strings.TrimSpace(pwd)
+
+	if err := system.SetPassword(pwd); err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to reset password: %v", err))
+		os.Exit(1)
+	}
+
+	if pwd == "" {
+		ui.PrintOK("Password removed. Commands and menu are now unlocked.")
 		return
 	}
 
-	if err := system.ClearPassword(); err != nil {
-		ui.PrintError(fmt.Sprintf("Failed to reset password: %v", err))
+	ui.PrintOK("Password changed.")
+	showNewRecoveryCodes()
+}
+
+func showNewRecoveryCodes() {
+	codes, err := system.GenerateRecoveryCodes()
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to generate recovery codes: %v", err))
 		return
 	}
 
-	ui.PrintOK("Password has been reset. Menu is now unlocked.")
+	fmt.Println()
+	ui.PrintWarn("Recovery codes (each works once with 'focusd reset-password'):")
+	fmt.Println()
+	for _, code := range codes {
+		fmt.Printf("    %s\n", code)
+	}
+	fmt.Println()
+	fmt.Println("  Store these somewhere safe. They will not be shown again.")
 }
@@ -6,6 +6,7 @@ import (
 	"focusd/ui"
 	"os"
 	"strconv"
+	"strings"
 )
 
 func RunRetentionStatus() {
@@ -15,27 +16,71 @@ func RunRetentionStatus() {
 	}
 	defer storage.Close()
 
-	days := storage.GetRetentionDays()
-	ui.PrintInfo(fmt.Sprintf("Data retention: %d days", days))
-	fmt.Printf("Data older than %d days is automatically deleted.\n", days)
-	fmt.Printf("Allowed range: %d-%d days. Default: %d days.\n",
-		storage.MinRetentionDays, storage.MaxRetentionDays, storage.DefaultRetentionDays)
+	ui.PrintSectionHeader("Data Retention")
+	ui.PrintKeyValue("Raw sessions", fmt.Sprintf("%d days", storage.GetRetentionDays()))
+	ui.PrintKeyValue("Daily totals", fmt.Sprintf("%d days", storage.GetDailyRetentionDays()))
+	ui.PrintKeyValue("Rollups", formatRollupRetention(storage.GetRollupRetentionDays()))
+	fmt.Println()
+	fmt.Println("Weekly and monthly rollups are computed from daily totals before anything is pruned.")
+	fmt.Println()
+	fmt.Println("Usage: focusd retention set <sessions|daily|rollups> <days>")
+	fmt.Printf("  sessions  %d-%d days (default %d)\n", storage.MinRetentionDays, storage.MaxRetentionDays, storage.DefaultRetentionDays)
+	fmt.Printf("  daily     %d-%d days (default %d)\n", storage.MinDailyRetentionDays, storage.MaxDailyRetentionDays, storage.DefaultDailyRetentionDays)
+	fmt.Println("  rollups   days, or 'forever' (default forever)")
 }
 
-func RunRetentionSet(daysStr string) {
+func formatRollupRetention(days int) string {
+	if days == 0 {
+		return "forever"
+	}
+	return fmt.Sprintf("%d days", days)
+}
+
+func RunRetentionSet(args []string) {
 	if err := storage.Init(); err != nil {
 		ui.PrintError(fmt.Sprintf("Failed to initialize: %v", err))
 		os.Exit(1)
 	}
 	defer storage.Close()
 
-	days, err := strconv.Atoi(daysStr)
-	if err != nil {
-		ui.PrintError("Invalid number of days. Please provide a number between 1 and 30.")
+	tier, value := "sessions", ""
+	switch len(args) {
+	case 1:
+		value = args[0]
+	case 2:
+		tier, value = strings.ToLower(args[0]), strings.ToLower(args[1])
+	default:
+		fmt.Println("Usage: focusd retention set [sessions|daily|rollups] <days>")
 		os.Exit(1)
 	}
 
-	if err := SetRetentionLogic(days); err != nil {
+	if tier == "rollups" && value == "forever" {
+		value = "0"
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		ui.PrintError(fmt.Sprintf("Invalid number of days: %s", value))
+		os.Exit(1)
+	}
+
+	switch tier {
+	case "sessions", "raw":
+		err = SetRetentionLogic(days)
+	case "daily":
+		err = storage.SetDailyRetentionDays(days)
+		if err == nil {
+			ui.PrintOK(fmt.Sprintf("Daily totals kept for %d days.", storage.GetDailyRetentionDays()))
+		}
+	case "rollups":
+		err = storage.SetRollupRetentionDays(days)
+		if err == nil {
+			ui.PrintOK(fmt.Sprintf("Weekly/monthly rollups kept: %s.", formatRollupRetention(days)))
+		}
+	default:
+		err = fmt.Errorf("unknown retention tier: %s", tier)
+	}
+
+	if err != nil {
 		ui.PrintError(err.Error())
 		os.Exit(1)
 	}
@@ -48,10 +93,19 @@ func RunRetentionReset() {
 	}
 	defer storage.Close()
 
+	if err := storage.SetRollupRetentionDays(storage.DefaultRollupRetentionDays); err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to reset retention: %v", err))
+		os.Exit(1)
+	}
+	if err := storage.SetConfig(storage.ConfigKeyDailyRetentionDays, strconv.Itoa(storage.DefaultDailyRetentionDays)); err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to reset retention: %v", err))
+		os.Exit(1)
+	}
 	if err := storage.SetRetentionDays(storage.DefaultRetentionDays); err != nil {
 		ui.PrintError(fmt.Sprintf("Failed to reset retention: %v", err))
 		os.Exit(1)
 	}
 
-	ui.PrintOK(fmt.Sprintf("Retention reset to %d days (default).", storage.DefaultRetentionDays))
+	ui.PrintOK(fmt.Sprintf("Retention reset to defaults: sessions %d days, daily totals %d days, rollups forever.",
+		storage.DefaultRetentionDays, storage.DefaultDailyRetentionDays))
 }
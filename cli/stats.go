@@ -99,8 +99,34 @@ func DisplayStats(summary *core.DailySummary) {
 
 	ui.PrintStatus("Total Screen Time", ui.FormatDuration(summary.TotalAppTime), false)
 	ui.PrintStatus("Apps Used", fmt.Sprintf("%d", summary.AppCount), false)
+	ui.PrintStatus("Productivity", fmt.Sprintf("%d/100", summary.ProductivityScore), summary.ProductivityScore >= 50)
 	fmt.Println()
 
+	if len(summary.Categories) > 0 {
+		ui.PrintSectionHeader("Categories")
+		columns := []ui.TableColumn{
+			{Header: "Category", Width: 30},
+			{Header: "Rating", Width: 20},
+			{Header: "Time", Width: 12},
+			{Header: "Share", Width: 8},
+		}
+		total := 0
+		for _, c := range summary.Categories {
+			total += c.TotalSecs
+		}
+		var rows [][]string
+		for _, c := range summary.Categories {
+			rows = append(rows, []string{
+				c.Category,
+				c.Rating,
+				ui.FormatDurationShort(c.TotalSecs),
+				fmt.Sprintf("%d%%", c.TotalSecs*100/total),
+			})
+		}
+		ui.PrintTable(columns, rows)
+		fmt.Println()
+	}
+
 	ui.PrintSectionHeader("Top Apps")
 	if len(summary.TopApps) == 0 {
 		fmt.Println("  No app data.")
@@ -3,8 +3,8 @@ package cli
 import (
 	"fmt"
 	"focusd/core"
+	"focusd/ipc"
 	"focusd/storage"
-	"focusd/system"
 	"focusd/ui"
 	"os"
 	"time"
@@ -24,10 +24,7 @@ func RunStatus() {
 
 	ui.PrintHeader()
 
-	isRunning := false
-	if system.GetProcessCount(system.DaemonProcessName) > 1 {
-		isRunning = true
-	}
+	isRunning := isDaemonRunning()
 
 	if isRunning {
 		ui.PrintOK("Daemon: RUNNING")
@@ -44,6 +41,21 @@ func RunStatus() {
 		ui.PrintInfo("Tracking: INACTIVE (daemon not running)")
 	}
 
+	if isRunning {
+		if state, err := ipc.GetState(); err == nil {
+			if state.WindowError != "" {
+				ui.PrintWarn("Windows: " + state.WindowError)
+			}
+			if state.IdleError != "" {
+				ui.PrintWarn("Idle: " + state.IdleError)
+			}
+		}
+	}
+
+	fmt.Println()
+
+	ui.PrintSectionHeader("Privacy")
+	printTitlePrivacy()
 	fmt.Println()
 
 	summary, err := core.GetDailySummary(storage.Today())
@@ -59,6 +71,7 @@ func RunStatus() {
 	fmt.Println()
 	fmt.Printf("  Total App Time:     %s\n", ui.FormatDuration(summary.TotalAppTime))
 	fmt.Printf("  Apps Used:          %d\n", summary.AppCount)
+	fmt.Printf("  Productivity:       %d/100\n", summary.ProductivityScore)
 	fmt.Println()
 
 	if len(summary.TopApps) > 0 {
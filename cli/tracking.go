@@ -2,13 +2,14 @@ package cli
 
 import (
 	"fmt"
+	"focusd/api"
 	"focusd/core"
+	"focusd/ipc"
 	"focusd/storage"
 	"focusd/system"
 	"focusd/ui"
 	"os"
 	"os/exec"
-	"syscall"
 	"time"
 )
 
@@ -27,6 +28,12 @@ func RunBackgroundTracking() {
 	}
 
 	tracker := core.NewTracker()
+	if srv := startIPCServer(tracker); srv != nil {
+		defer srv.Close()
+	}
+	if srv := startAPIServer(tracker); srv != nil {
+		defer srv.Stop()
+	}
 	tracker.Start()
 }
 
@@ -46,7 +53,7 @@ func StartDaemonProcess() {
 		return
 	}
 
-	if system.GetProcessCount(system.DaemonProcessName) > 1 {
+	if isDaemonRunning() {
 		ui.PrintInfo("focusd is already running.")
 		return
 	}
@@ -58,9 +65,7 @@ func StartDaemonProcess() {
 	}
 
 	cmd := exec.Command(exePath, "--daemon")
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | 0x00000008,
-	}
+	cmd.SysProcAttr = system.DetachedProcAttr()
 
 	if err := cmd.Start(); err != nil {
 		ui.PrintError(fmt.Sprintf("Failed to start background process: %v", err))
@@ -76,18 +81,51 @@ func StartDaemonProcess() {
 }
 
 func RunStop() {
-	if system.GetProcessCount(system.DaemonProcessName) <= 1 {
+	if !isDaemonRunning() {
 		ui.PrintInfo("focusd is not running.")
 		return
 	}
 
-	if err := system.KillProcess(system.DaemonProcessName); err != nil {
+	if err := stopDaemon(); err != nil {
 		ui.PrintWarn("Could not stop focusd. It may still be running.")
 	} else {
 		ui.PrintOK("focusd stopped")
 	}
 }
 
+func RunReload() {
+	if !isDaemonRunning() {
+		ui.PrintInfo("focusd is not running. Changes will apply on next start.")
+		return
+	}
+
+	if _, err := ipc.Send(ipc.CommandReloadConfig); err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to reload configuration: %v", err))
+		fmt.Println("The daemon is still using the previous configuration.")
+		return
+	}
+	ui.PrintOK("Configuration reloaded.")
+}
+
+func isDaemonRunning() bool {
+	if ipc.IsDaemonReachable() {
+		return true
+	}
+	return system.GetProcessCount(system.DaemonProcessName) > 1
+}
+
+func stopDaemon() error {
+	if _, err := ipc.Send(ipc.CommandStop); err == nil {
+		for i := 0; i < 50; i++ {
+			if !isDaemonRunning() {
+				return nil
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+	return system.KillOtherInstances(system.DaemonProcessName)
+}
+
 func RunDaemon() {
 	time.Sleep(3 * time.Second)
 
@@ -117,5 +155,104 @@ func RunDaemon() {
 	storage.EnforceRetention()
 
 	tracker := core.NewTracker()
+	if srv := startIPCServer(tracker); srv != nil {
+		defer srv.Close()
+	}
+	if srv := startAPIServer(tracker); srv != nil {
+		defer srv.Stop()
+	}
 	tracker.Start()
 }
+
+func stopOtherInstances() {
+	if _, err := ipc.Send(ipc.CommandStop); err == nil {
+		for i := 0; i < 50; i++ {
+			if system.GetProcessCount(system.DaemonProcessName) <= 1 {
+				return
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+	system.KillOtherInstances(system.DaemonProcessName)
+}
+
+func startIPCServer(tracker *core.Tracker) *ipc.Server {
+	srv, err := ipc.NewServer(ipcHandler(tracker))
+	if err != nil {
+		reportDaemonError(fmt.Sprintf("Commands cannot reach focusd: %v", err))
+		return nil
+	}
+	return srv
+}
+
+func ipcHandler(tracker *core.Tracker) ipc.Handler {
+	return func(req ipc.Request) ipc.Response {
+		switch req.Command {
+		case ipc.CommandStop:
+			tracker.Stop()
+		case ipc.CommandPause:
+			if err := tracker.SetPaused(true); err != nil {
+				return ipc.Response{Error: err.Error()}
+			}
+		case ipc.CommandResume:
+			if err := tracker.SetPaused(false); err != nil {
+				return ipc.Response{Error: err.Error()}
+			}
+		case ipc.CommandReloadConfig:
+			if err := tracker.ReloadConfig(); err != nil {
+				return ipc.Response{Error: err.Error()}
+			}
+		case ipc.CommandDiscardSince:
+			tracker.DiscardSince(req.Since)
+		case ipc.CommandForgetApp:
+			tracker.ForgetApp(req.Target)
+		case ipc.CommandForgetSite:
+			tracker.ForgetSite(req.Target)
+		case ipc.CommandGetState:
+		default:
+			return ipc.Response{Error: fmt.Sprintf("unknown command %q", req.Command)}
+		}
+
+		state := &ipc.State{
+			PID:     os.Getpid(),
+			Version: system.Version,
+			Paused:  tracker.IsPaused(),
+			Idle:    tracker.IsIdle(),
+			Session: tracker.CurrentSessionAsStored(),
+		}
+		if err := tracker.IdleError(); err != nil {
+			state.IdleError = err.Error()
+		}
+		if err := tracker.WindowError(); err != nil {
+			state.WindowError = err.Error()
+		}
+		return ipc.Response{OK: true, State: state}
+	}
+}
+
+func startAPIServer(tracker *core.Tracker) *api.Server {
+	if !system.GetAPIEnabled() {
+		return nil
+	}
+
+	token, err := api.LoadOrCreateToken()
+	if err != nil {
+		reportDaemonError(fmt.Sprintf("The local API is off: %v", err))
+		return nil
+	}
+
+	srv := api.NewServer(tracker, system.GetAPIPort(), token)
+	if err := srv.Start(); err != nil {
+		reportDaemonError(fmt.Sprintf("The local API is off: %v", err))
+		return nil
+	}
+	return srv
+}
+
+// reportDaemonError shows a startup failure both on stderr, for a daemon run
+// in the foreground, and as a desktop notification, since the background
+// daemon has no console.
+func reportDaemonError(msg string) {
+	ui.PrintError(msg)
+	go system.ShowMessage("focusd", msg)
+}
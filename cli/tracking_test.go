@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"focusd/core"
+	"focusd/ipc"
+	"focusd/storage"
+	"focusd/system"
+	"net"
+	"testing"
+	"time"
+)
+
+type noWindows struct{}
+
+func (noWindows) ForegroundWindow() (*system.WindowInfo, error) { return nil, nil }
+
+type silentNotifier struct{}
+
+func (silentNotifier) Notify(title, message string) bool { return true }
+
+func (silentNotifier) NotifyWithAction(title, message string, callback func(disable bool)) bool {
+	return true
+}
+
+type neverIdle struct{}
+
+func (neverIdle) LastInputTime() (time.Time, error) { return time.Now(), nil }
+
+func newTestTracker(t *testing.T) *core.Tracker {
+	t.Helper()
+	isolateDataDir(t)
+	if err := system.ReloadUserConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { storage.Close() })
+
+	return core.NewTrackerWithOptions(core.TrackerOptions{
+		Windows:    noWindows{},
+		Notifier:   silentNotifier{},
+		IdleSource: neverIdle{},
+	})
+}
+
+func sendIPC(t *testing.T, handler ipc.Handler, req ipc.Request) ipc.Response {
+	t.Helper()
+	client, server := net.Pipe()
+	defer client.Close()
+	go ipc.ServeConn(server, handler)
+
+	client.SetDeadline(time.Now().Add(2 * time.Second))
+	data, _ := json.Marshal(req)
+	if _, err := client.Write(append(data, '\n')); err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(client).ReadBytes('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp ipc.Response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestIPCHandlerPauseResume(t *testing.T) {
+	tracker := newTestTracker(t)
+	handler := ipcHandler(tracker)
+
+	resp := sendIPC(t, handler, ipc.Request{Command: ipc.CommandPause})
+	if !resp.OK || resp.State == nil || !resp.State.Paused {
+		t.Fatalf("pause response = %+v, want OK and paused", resp)
+	}
+	if !storage.IsPaused() {
+		t.Error("pause was not persisted")
+	}
+
+	resp = sendIPC(t, handler, ipc.Request{Command: ipc.CommandResume})
+	if !resp.OK || resp.State.Paused {
+		t.Errorf("resume response = %+v, want OK and not paused", resp)
+	}
+}
+
+func TestIPCHandlerForget(t *testing.T) {
+	tracker := newTestTracker(t)
+	handler := ipcHandler(tracker)
+
+	for _, req := range []ipc.Request{
+		{Command: ipc.CommandForgetApp, Target: "slack"},
+		{Command: ipc.CommandForgetSite, Target: "YouTube"},
+		{Command: ipc.CommandGetState},
+	} {
+		resp := sendIPC(t, handler, req)
+		if !resp.OK || resp.State == nil || resp.State.Session != nil {
+			t.Errorf("%s response = %+v, want OK with no current session", req.Command, resp)
+		}
+	}
+}
+
+func TestIPCHandlerUnknownCommand(t *testing.T) {
+	tracker := newTestTracker(t)
+
+	resp := sendIPC(t, ipcHandler(tracker), ipc.Request{Command: "launch-missiles"})
+	if resp.OK || resp.Error != `unknown command "launch-missiles"` || resp.State != nil {
+		t.Errorf("unknown command response = %+v", resp)
+	}
+}
+
+func TestIPCHandlerStop(t *testing.T) {
+	tracker := newTestTracker(t)
+
+	if resp := sendIPC(t, ipcHandler(tracker), ipc.Request{Command: ipc.CommandStop}); !resp.OK {
+		t.Fatalf("stop response = %+v", resp)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		tracker.Start()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tracker kept running after stop")
+	}
+}
@@ -44,9 +44,9 @@ func RunUninstall() {
 		ui.PrintOK("Removed PATH entry")
 	}
 
-	if system.GetProcessCount(system.DaemonProcessName) > 1 {
+	if isDaemonRunning() {
 		ui.PrintInfo("Stopping running focusd processes...")
-		system.KillOtherInstances(system.DaemonProcessName)
+		stopOtherInstances()
 		time.Sleep(1 * time.Second)
 	}
 
@@ -59,7 +59,7 @@ func RunUninstall() {
 		os.Remove(filepath.Join(dataDir, "focusd.db-wal"))
 		os.Remove(filepath.Join(dataDir, "config.json"))
 		os.Remove(filepath.Join(dataDir, "pomodoro.json"))
-		os.Remove(filepath.Join(dataDir, "FocusDaemon.vbs"))
+		os.Remove(system.GetLauncherPath())
 		os.Remove(filepath.Join(dataDir, "FocusDaemon.exe"))
 	}
 
@@ -83,6 +83,6 @@ func RunUninstall() {
 	ui.PrintOK("Uninstall complete!")
 	fmt.Println()
 	fmt.Println("focusd has been removed.")
-	fmt.Println("If you see any remaining files in %APPDATA%/focusd, you can delete them after a restart.")
+	fmt.Printf("If you see any remaining files in %s, you can delete them after a restart.\n", dataDir)
 	fmt.Println()
 }
@@ -12,7 +12,6 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
-	"syscall"
 	"time"
 )
 
@@ -49,10 +48,10 @@ func RunUpdate() {
 		return
 	}
 
-	daemonWasRunning := system.GetProcessCount(system.DaemonProcessName) > 1
+	daemonWasRunning := isDaemonRunning()
 	if daemonWasRunning {
 		ui.PrintStatus("Stopping focusd daemon...", "", false)
-		system.KillOtherInstances(system.DaemonProcessName)
+		stopOtherInstances()
 		time.Sleep(500 * time.Millisecond)
 	}
 
@@ -84,9 +83,7 @@ func restartDaemon() {
 	}
 
 	cmd := exec.Command(installedExe, "--daemon")
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | 0x00000008,
-	}
+	cmd.SysProcAttr = system.DetachedProcAttr()
 	cmd.Start()
 }
 
@@ -128,8 +125,14 @@ func fetchChecksum(version string) (string, error) {
 		return "", err
 	}
 
-	line := strings.TrimSpace(string(body))
-	parts := strings.Fields(line)
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	for _, line := range lines {
+		parts := strings.Fields(line)
+		if len(parts) >= 2 && parts[len(parts)-1] == system.ReleaseAssetName {
+			return strings.ToLower(parts[0]), nil
+		}
+	}
+	parts := strings.Fields(lines[0])
 	if len(parts) >= 1 {
 		return strings.ToLower(parts[0]), nil
 	}
@@ -153,10 +156,10 @@ func calculateFileHash(filePath string) (string, error) {
 func performUpdate(version string) error {
 	ui.PrintStatus("Downloading update...", "0%", false)
 
-	downloadURL := fmt.Sprintf("https://github.com/%s/%s/releases/download/v%s/focusd.exe",
-		system.RepoOwner, system.RepoName, version)
+	downloadURL := fmt.Sprintf("https://github.com/%s/%s/releases/download/v%s/%s",
+		system.RepoOwner, system.RepoName, version, system.ReleaseAssetName)
 
-	tmpFile, err := os.CreateTemp("", "focusd-update-*.exe")
+	tmpFile, err := os.CreateTemp("", "focusd-update-*")
 	if err != nil {
 		return fmt.Errorf("failed to create temp file: %w", err)
 	}
@@ -8,15 +8,17 @@ import (
 )
 
 type DailySummary struct {
-	Date         string
-	TotalAppTime int
-	AppCount     int
-	TopApps      []storage.AppDailyStat
-	TopSites     []storage.AppDailyStat
-	GroupedSites []GroupedBrowserStat
-	RangeMessage string
-	RangeStart   string
-	RangeEnd     string
+	Date              string                 `json:"date"`
+	TotalAppTime      int                    `json:"total_app_time_secs"`
+	AppCount          int                    `json:"app_count"`
+	TopApps           []storage.AppDailyStat `json:"top_apps"`
+	TopSites          []storage.AppDailyStat `json:"top_sites"`
+	GroupedSites      []GroupedBrowserStat   `json:"grouped_sites"`
+	Categories        []CategoryStat         `json:"categories"`
+	ProductivityScore int                    `json:"productivity_score"`
+	RangeMessage      string                 `json:"range_message,omitempty"`
+	RangeStart        string                 `json:"range_start,omitempty"`
+	RangeEnd          string                 `json:"range_end,omitempty"`
 }
 
 func GetDailySummary(date string) (*DailySummary, error) {
@@ -30,6 +32,8 @@ func GetDailySummary(date string) (*DailySummary, error) {
 	summary := createSummary(date, apps)
 	summary.TopSites = limitStats(sites, 10)
 	summary.GroupedSites = groupSitesFromStats(sites)
+	summary.Categories = CategorizeUsage(apps, sites)
+	summary.ProductivityScore = ProductivityScore(summary.Categories)
 
 	return summary, nil
 }
@@ -61,13 +65,14 @@ func GetPeriodSummary(days int) (*DailySummary, error) {
 				maxDate = s.Date
 			}
 
-			existing, exists := appMap[s.ExeName]
+			key := s.ExeName + "|" + s.AppName
+			existing, exists := appMap[key]
 			if !exists {
 				existing = storage.AppDailyStat{ExeName: s.ExeName, AppName: s.AppName}
 			}
 			existing.TotalDurationSecs += s.TotalDurationSecs
 			existing.OpenCount += s.OpenCount
-			appMap[s.ExeName] = existing
+			appMap[key] = existing
 		}
 	}
 
@@ -94,6 +99,8 @@ func GetPeriodSummary(days int) (*DailySummary, error) {
 	summary := createSummary(label, apps)
 	summary.TopSites = limitStats(sites, 10)
 	summary.GroupedSites = groupSitesFromStats(sites)
+	summary.Categories = CategorizeUsage(apps, sites)
+	summary.ProductivityScore = ProductivityScore(summary.Categories)
 	summary.RangeStart = minDate
 	summary.RangeEnd = maxDate
 
@@ -9,9 +9,24 @@ import (
 type appPattern struct {
 	Name     string
 	Patterns []string
+	Regexes  []*regexp.Regexp
 	Priority int
 }
 
+func (p *appPattern) matches(titleLower, title string) bool {
+	for _, pattern := range p.Patterns {
+		if strings.Contains(titleLower, strings.ToLower(pattern)) {
+			return true
+		}
+	}
+	for _, re := range p.Regexes {
+		if re.MatchString(title) {
+			return true
+		}
+	}
+	return false
+}
+
 var appPatterns = []appPattern{
 	{Name: "YouTube", Patterns: []string{"youtube.com", "youtu.be", "- youtube", "| youtube"}, Priority: 100},
 	{Name: "GitHub", Patterns: []string{"github.com", "· github", "- github", "| github", "/github"}, Priority: 100},
@@ -106,6 +121,10 @@ var defaultBrowserTitles = map[string]bool{
 }
 
 func ExtractAppCategory(title string) string {
+	return extractAppCategory(title, GetGroupRules())
+}
+
+func extractAppCategory(title string, rules []EffectiveGroupRule) string {
 	if title == "" {
 		return ""
 	}
@@ -118,14 +137,11 @@ func ExtractAppCategory(title string) string {
 
 	var bestMatch *appPattern
 
-	for i := range appPatterns {
-		app := &appPatterns[i]
-		for _, pattern := range app.Patterns {
-			if strings.Contains(titleLower, strings.ToLower(pattern)) {
-				if bestMatch == nil || app.Priority > bestMatch.Priority {
-					bestMatch = app
-				}
-				break
+	for i := range rules {
+		app := &rules[i].appPattern
+		if app.matches(titleLower, title) {
+			if bestMatch == nil || app.Priority > bestMatch.Priority {
+				bestMatch = app
 			}
 		}
 	}
@@ -138,14 +154,14 @@ func ExtractAppCategory(title string) string {
 }
 
 type GroupedBrowserStat struct {
-	Category   string
-	TotalSecs  int
-	SubEntries []SubEntry
+	Category   string     `json:"category"`
+	TotalSecs  int        `json:"total_secs"`
+	SubEntries []SubEntry `json:"sub_entries,omitempty"`
 }
 
 type SubEntry struct {
-	Title    string
-	Duration int
+	Title    string `json:"title"`
+	Duration int    `json:"duration_secs"`
 }
 
 func GroupBrowserStats(stats []struct {
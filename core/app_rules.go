@@ -0,0 +1,350 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"focusd/storage"
+	"focusd/system"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type AppRule struct {
+	Exe   string `json:"exe"`
+	Path  string `json:"path,omitempty"`
+	Title string `json:"title,omitempty"`
+	Name  string `json:"name"`
+}
+
+type AppRulesFile struct {
+	Rules []AppRule `json:"rules"`
+}
+
+type compiledAppRule struct {
+	AppRule
+	exe   string
+	path  string
+	title *regexp.Regexp
+}
+
+type AppIdentity struct {
+	ExeName string
+	AppName string
+}
+
+var (
+	appRules   []compiledAppRule
+	appRulesMu sync.RWMutex
+)
+
+func GetAppRulesPath() (string, error) {
+	dataDir, err := system.GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "apps.json"), nil
+}
+
+func LoadAppRulesFile() (*AppRulesFile, error) {
+	path, err := GetAppRulesPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &AppRulesFile{Rules: []AppRule{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file AppRulesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid apps.json: %w", err)
+	}
+	return &file, nil
+}
+
+func SaveAppRulesFile(file *AppRulesFile) error {
+	if _, err := compileAppRules(file); err != nil {
+		return err
+	}
+
+	path, err := GetAppRulesPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	return ReloadAppRules()
+}
+
+func normalizeRuleExe(exe string) string {
+	return system.CanonicalExeName(exe)
+}
+
+func exeMatches(ruleExe, exeName string) bool {
+	return ruleExe == system.CanonicalExeName(exeName)
+}
+
+func compileAppRules(file *AppRulesFile) ([]compiledAppRule, error) {
+	var specific, general []compiledAppRule
+	for i, r := range file.Rules {
+		label := fmt.Sprintf("rule %d", i+1)
+		if r.Name != "" {
+			label = fmt.Sprintf("rule %d (%q)", i+1, r.Name)
+		}
+
+		c := compiledAppRule{AppRule: r, exe: normalizeRuleExe(r.Exe), path: strings.ToLower(r.Path)}
+		if c.exe == "" {
+			return nil, fmt.Errorf("%s: exe is required", label)
+		}
+		if strings.TrimSpace(r.Name) == "" {
+			return nil, fmt.Errorf("%s: name is required", label)
+		}
+		if r.Title != "" {
+			re, err := regexp.Compile(r.Title)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid title regex %q: %v", label, r.Title, err)
+			}
+			c.title = re
+		}
+
+		if c.isSpecific() {
+			specific = append(specific, c)
+		} else {
+			general = append(general, c)
+		}
+	}
+	return append(specific, general...), nil
+}
+
+func (r *compiledAppRule) isSpecific() bool {
+	return r.path != "" || r.title != nil
+}
+
+func (r *compiledAppRule) matches(exeName, exePath, title string) bool {
+	if !exeMatches(r.exe, exeName) {
+		return false
+	}
+	if r.path != "" && !strings.Contains(strings.ToLower(exePath), r.path) {
+		return false
+	}
+	if r.title != nil && !r.title.MatchString(title) {
+		return false
+	}
+	return true
+}
+
+// canRecheck reports whether a stored session still carries what the rule
+// matches on. Paths are never stored, and hashed or dropped titles cannot be
+// matched, so sessions named by such rules keep their name.
+func (r *compiledAppRule) canRecheck(title string) bool {
+	return r.path == "" && title != "" && !isTitleHash(title)
+}
+
+func specificRuleNames(rules []compiledAppRule, exeName string) []string {
+	var names []string
+	for _, r := range rules {
+		if r.isSpecific() && exeMatches(r.exe, exeName) {
+			names = append(names, r.Name)
+		}
+	}
+	return names
+}
+
+func ruleNamed(rules []compiledAppRule, exeName, name string) *compiledAppRule {
+	for i := range rules {
+		if rules[i].Name == name && exeMatches(rules[i].exe, exeName) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+func ReloadAppRules() error {
+	file, err := LoadAppRulesFile()
+	if err != nil {
+		return err
+	}
+	rules, err := compileAppRules(file)
+	if err != nil {
+		return fmt.Errorf("invalid apps.json: %w", err)
+	}
+
+	appRulesMu.Lock()
+	appRules = rules
+	appRulesMu.Unlock()
+	return nil
+}
+
+func getAppRules() []compiledAppRule {
+	appRulesMu.RLock()
+	rules := appRules
+	appRulesMu.RUnlock()
+	if rules != nil {
+		return rules
+	}
+
+	if err := ReloadAppRules(); err != nil {
+		appRulesMu.Lock()
+		appRules = []compiledAppRule{}
+		appRulesMu.Unlock()
+	}
+
+	appRulesMu.RLock()
+	defer appRulesMu.RUnlock()
+	return appRules
+}
+
+func ResolveApp(exeName, exePath, title string) AppIdentity {
+	return resolveApp(getAppRules(), exeName, exePath, title)
+}
+
+func resolveApp(rules []compiledAppRule, exeName, exePath, title string) AppIdentity {
+	exe := system.CanonicalExeName(exeName)
+	for i := range rules {
+		if rules[i].matches(exe, exePath, title) {
+			return AppIdentity{ExeName: exe, AppName: rules[i].Name}
+		}
+	}
+	return AppIdentity{ExeName: exe, AppName: getAppName(exeName)}
+}
+
+func RenameApp(exeName, name string) error {
+	file, err := LoadAppRulesFile()
+	if err != nil {
+		return err
+	}
+
+	exe := normalizeRuleExe(exeName)
+	for i, r := range file.Rules {
+		if r.Path == "" && r.Title == "" && exeMatches(normalizeRuleExe(r.Exe), exe) {
+			file.Rules[i].Name = name
+			return SaveAppRulesFile(file)
+		}
+	}
+
+	file.Rules = append(file.Rules, AppRule{Exe: exe, Name: name})
+	return SaveAppRulesFile(file)
+}
+
+func AddAppRule(rule AppRule) error {
+	file, err := LoadAppRulesFile()
+	if err != nil {
+		return err
+	}
+	rule.Exe = normalizeRuleExe(rule.Exe)
+	file.Rules = append(file.Rules, rule)
+	return SaveAppRulesFile(file)
+}
+
+// RenameAppHistory applies a display name to stored rows of exeName, leaving
+// the apps split off from it by path or title rules alone.
+func RenameAppHistory(exeName, name string) (int, error) {
+	return storage.RenameAppAggregates(exeName, name, specificRuleNames(getAppRules(), normalizeRuleExe(exeName)))
+}
+
+func RemoveAppRules(nameOrExe string) (int, error) {
+	file, err := LoadAppRulesFile()
+	if err != nil {
+		return 0, err
+	}
+
+	var kept []AppRule
+	removed := 0
+	for _, r := range file.Rules {
+		if strings.EqualFold(r.Name, nameOrExe) || exeMatches(normalizeRuleExe(r.Exe), nameOrExe) {
+			removed++
+			continue
+		}
+		kept = append(kept, r)
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+
+	file.Rules = kept
+	if file.Rules == nil {
+		file.Rules = []AppRule{}
+	}
+	return removed, SaveAppRulesFile(file)
+}
+
+func ApplyAppRules() (int, error) {
+	sessions, err := storage.GetAllSessions()
+	if err != nil {
+		return 0, err
+	}
+	rules := getAppRules()
+
+	stored := make(map[string]string)
+	for _, s := range sessions {
+		stored[s.ExeName+"|"+strings.ToLower(s.AppName)] = s.AppName
+	}
+
+	var updates []storage.SessionIdentity
+	dates := make(map[string]bool)
+	for _, s := range sessions {
+		if s.ExeName == IdleExeName {
+			continue
+		}
+		if r := ruleNamed(rules, s.ExeName, s.AppName); r != nil && r.isSpecific() && !r.canRecheck(s.WindowTitle) {
+			continue
+		}
+
+		name := ResolveApp(s.ExeName, "", s.WindowTitle).AppName
+		if existing, ok := stored[s.ExeName+"|"+strings.ToLower(name)]; ok {
+			name = existing
+		}
+		if strings.EqualFold(name, s.AppName) {
+			continue
+		}
+		updates = append(updates, storage.SessionIdentity{ID: s.ID, ExeName: s.ExeName, AppName: name})
+		dates[s.Date] = true
+	}
+
+	if len(updates) > 0 {
+		if err := storage.UpdateSessionIdentities(updates); err != nil {
+			return 0, err
+		}
+
+		var dateList []string
+		for d := range dates {
+			dateList = append(dateList, d)
+		}
+		sort.Strings(dateList)
+		if err := RebuildAggregates(dateList); err != nil {
+			return len(updates), err
+		}
+	}
+
+	for _, r := range rules {
+		if r.isSpecific() {
+			continue
+		}
+		if _, err := storage.RenameAppAggregates(r.exe, r.Name, specificRuleNames(rules, r.exe)); err != nil {
+			return len(updates), err
+		}
+	}
+	return len(updates), nil
+}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"focusd/storage"
+	"focusd/system"
+	"testing"
+	"time"
+)
+
+func appTotals(t *testing.T, date string) map[string]int {
+	t.Helper()
+	stats, err := storage.GetAppStatsForDate(date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	totals := make(map[string]int)
+	for _, s := range stats {
+		totals[s.ExeName+"/"+s.AppName] += s.TotalDurationSecs
+	}
+	return totals
+}
+
+func openAppRulesDB(t *testing.T) {
+	t.Helper()
+	isolateDataDir(t)
+	if err := storage.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		storage.Close()
+		appRulesMu.Lock()
+		appRules = nil
+		appRulesMu.Unlock()
+	})
+}
+
+func TestApplyAppRulesRevertsRemovedRules(t *testing.T) {
+	openAppRulesDB(t)
+
+	base := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	date := base.Format("2006-01-02")
+	for i, title := range []string{"Minecraft 1.21", "IntelliJ IDEA"} {
+		start := base.Add(time.Duration(i) * time.Minute)
+		if err := storage.InsertSession(&storage.Session{
+			AppName: "Javaw", ExeName: "javaw", WindowTitle: title,
+			StartTime: start, EndTime: start.Add(time.Minute), DurationSecs: 60, Date: date,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := RebuildAggregates([]string{date}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AddAppRule(AppRule{Exe: "javaw", Title: "^Minecraft", Name: "Minecraft"}); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := ApplyAppRules(); err != nil || n != 1 {
+		t.Fatalf("ApplyAppRules after adding the rule = %d, %v; want 1 session renamed", n, err)
+	}
+	if got := appTotals(t, date); got["javaw/Minecraft"] != 60 || got["javaw/Javaw"] != 60 {
+		t.Errorf("totals with the rule = %v, want 60s each for Minecraft and Javaw", got)
+	}
+
+	if err := RenameApp("javaw", "Java"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RenameAppHistory("javaw", "Java"); err != nil {
+		t.Fatal(err)
+	}
+	if got := appTotals(t, date); got["javaw/Minecraft"] != 60 || got["javaw/Java"] != 60 {
+		t.Errorf("totals after renaming javaw = %v, want Minecraft kept apart from Java", got)
+	}
+
+	if _, err := RemoveAppRules("Minecraft"); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := ApplyAppRules(); err != nil || n != 1 {
+		t.Fatalf("ApplyAppRules after removing the rule = %d, %v; want 1 session reverted", n, err)
+	}
+	if got := appTotals(t, date); len(got) != 1 || got["javaw/Java"] != 120 {
+		t.Errorf("totals without the rule = %v, want 120s on javaw/Java", got)
+	}
+}
+
+func TestApplyAppRulesKeepsBuiltinNames(t *testing.T) {
+	openAppRulesDB(t)
+
+	base := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	apps := map[string]string{"WINWORD.EXE": "Microsoft Word", "Teams.exe": "Microsoft Teams", "POWERPNT.EXE": "PowerPoint"}
+	for exe, name := range apps {
+		if err := storage.InsertSession(&storage.Session{
+			AppName: name, ExeName: exe, StartTime: base, EndTime: base.Add(time.Minute), DurationSecs: 60, Date: "2026-03-10",
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n, err := ApplyAppRules(); err != nil || n != 0 {
+		t.Fatalf("ApplyAppRules with no rules = %d, %v; want nothing renamed", n, err)
+	}
+	sessions, err := storage.GetAllSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make(map[string]string)
+	for exe, name := range apps {
+		want[system.CanonicalExeName(exe)] = name
+	}
+	for _, s := range sessions {
+		if s.AppName != want[s.ExeName] {
+			t.Errorf("%s renamed to %q, want %q", s.ExeName, s.AppName, want[s.ExeName])
+		}
+	}
+}
+
+func TestGetAppNameIgnoresCase(t *testing.T) {
+	tests := map[string]string{
+		"WINWORD.EXE":                           "Microsoft Word",
+		"winword":                               "Microsoft Word",
+		system.CanonicalExeName("POWERPNT.EXE"): "PowerPoint",
+		"Teams.exe":                             "Microsoft Teams",
+		"terminal":                              "Windows Terminal",
+		"myapp.Exe":                             "Myapp",
+	}
+	for exe, want := range tests {
+		if got := getAppName(exe); got != want {
+			t.Errorf("getAppName(%q) = %q, want %q", exe, got, want)
+		}
+	}
+}
@@ -0,0 +1,630 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"focusd/storage"
+	"focusd/system"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	RatingProductive  = "productive"
+	RatingNeutral     = "neutral"
+	RatingDistracting = "distracting"
+
+	CategoryBrowsing      = "Browsing"
+	CategoryUncategorized = "Uncategorized"
+)
+
+var Ratings = []string{RatingProductive, RatingNeutral, RatingDistracting}
+
+var builtinCategories = map[string]string{
+	"Development":         RatingProductive,
+	"Productivity":        RatingProductive,
+	"Learning":            RatingProductive,
+	"Communication":       RatingNeutral,
+	"Reference":           RatingNeutral,
+	"Utilities":           RatingNeutral,
+	CategoryBrowsing:      RatingNeutral,
+	CategoryUncategorized: RatingNeutral,
+	"Social":              RatingDistracting,
+	"Entertainment":       RatingDistracting,
+	"Shopping":            RatingDistracting,
+}
+
+var builtinAppCategories = map[string]string{
+	"code":                  "Development",
+	"cursor":                "Development",
+	"zed":                   "Development",
+	"devenv":                "Development",
+	"idea64":                "Development",
+	"pycharm64":             "Development",
+	"webstorm64":            "Development",
+	"goland64":              "Development",
+	"rider64":               "Development",
+	"studio64":              "Development",
+	"notepad++":             "Development",
+	"sublime_text":          "Development",
+	"atom":                  "Development",
+	"nvim":                  "Development",
+	"vim":                   "Development",
+	"windowsterminal":       "Development",
+	"wt":                    "Development",
+	"terminal":              "Development",
+	"cmd":                   "Development",
+	"powershell":            "Development",
+	"pwsh":                  "Development",
+	"gnome-terminal-server": "Development",
+	"konsole":               "Development",
+	"alacritty":             "Development",
+	"kitty":                 "Development",
+	"winword":               "Productivity",
+	"excel":                 "Productivity",
+	"powerpnt":              "Productivity",
+	"onenote":               "Productivity",
+	"soffice.bin":           "Productivity",
+	"acrobat":               "Productivity",
+	"notion":                "Productivity",
+	"obsidian":              "Productivity",
+	"figma":                 "Productivity",
+	"outlook":               "Communication",
+	"thunderbird":           "Communication",
+	"slack":                 "Communication",
+	"teams":                 "Communication",
+	"ms-teams":              "Communication",
+	"zoom":                  "Communication",
+	"telegram":              "Communication",
+	"whatsapp":              "Communication",
+	"signal":                "Communication",
+	"discord":               "Social",
+	"spotify":               "Entertainment",
+	"vlc":                   "Entertainment",
+	"mpv":                   "Entertainment",
+	"steam":                 "Entertainment",
+	"epicgameslauncher":     "Entertainment",
+	"explorer":              "Utilities",
+	"nautilus":              "Utilities",
+	"dolphin":               "Utilities",
+	"taskmgr":               "Utilities",
+	"systemsettings":        "Utilities",
+}
+
+var builtinSiteCategories = map[string]string{
+	"GitHub":          "Development",
+	"GitLab":          "Development",
+	"StackOverflow":   "Development",
+	"LeetCode":        "Development",
+	"HackerRank":      "Development",
+	"Codeforces":      "Development",
+	"Dev.to":          "Development",
+	"GeeksforGeeks":   "Development",
+	"W3Schools":       "Development",
+	"MDN":             "Development",
+	"VS Code":         "Development",
+	"CodePen":         "Development",
+	"Replit":          "Development",
+	"Vercel":          "Development",
+	"Netlify":         "Development",
+	"AWS":             "Development",
+	"Google Cloud":    "Development",
+	"Azure":           "Development",
+	"Google Drive":    "Productivity",
+	"Google Docs":     "Productivity",
+	"Google Sheets":   "Productivity",
+	"Notion":          "Productivity",
+	"Figma":           "Productivity",
+	"Canva":           "Productivity",
+	"Trello":          "Productivity",
+	"Asana":           "Productivity",
+	"Jira":            "Productivity",
+	"ChatGPT":         "Productivity",
+	"Claude":          "Productivity",
+	"Google Gemini":   "Productivity",
+	"Perplexity":      "Productivity",
+	"Unstop":          "Productivity",
+	"Internshala":     "Productivity",
+	"Naukri":          "Productivity",
+	"Coursera":        "Learning",
+	"Udemy":           "Learning",
+	"Khan Academy":    "Learning",
+	"Medium":          "Learning",
+	"Wikipedia":       "Learning",
+	"Slack":           "Communication",
+	"WhatsApp":        "Communication",
+	"Telegram":        "Communication",
+	"Gmail":           "Communication",
+	"Outlook":         "Communication",
+	"Google Meet":     "Communication",
+	"Zoom":            "Communication",
+	"Microsoft Teams": "Communication",
+	"LinkedIn":        "Communication",
+	"Google Search":   "Reference",
+	"Bing":            "Reference",
+	"DuckDuckGo":      "Reference",
+	"Discord":         "Social",
+	"Instagram":       "Social",
+	"Twitter/X":       "Social",
+	"Facebook":        "Social",
+	"Reddit":          "Social",
+	"TikTok":          "Social",
+	"Quora":           "Social",
+	"Pinterest":       "Social",
+	"Snapchat":        "Social",
+	"YouTube":         "Entertainment",
+	"Netflix":         "Entertainment",
+	"Prime Video":     "Entertainment",
+	"Hotstar":         "Entertainment",
+	"JioCinema":       "Entertainment",
+	"Spotify":         "Entertainment",
+	"Twitch":          "Entertainment",
+	"Amazon":          "Shopping",
+	"Flipkart":        "Shopping",
+	"Myntra":          "Shopping",
+	"Swiggy":          "Shopping",
+	"Zomato":          "Shopping",
+	"Uber":            "Shopping",
+	"Ola":             "Shopping",
+}
+
+type CategoriesFile struct {
+	Ratings map[string]string `json:"ratings"`
+	Apps    map[string]string `json:"apps"`
+	Sites   map[string]string `json:"sites"`
+}
+
+type Category struct {
+	Name   string
+	Rating string
+	Custom bool
+}
+
+type CategoryStat struct {
+	Category  string `json:"category"`
+	Rating    string `json:"rating"`
+	TotalSecs int    `json:"total_secs"`
+}
+
+type DayProductivity struct {
+	Date              string         `json:"date"`
+	ProductivityScore int            `json:"productivity_score"`
+	Categories        []CategoryStat `json:"categories"`
+}
+
+type categorySet struct {
+	categories map[string]Category
+	apps       map[string]string
+	sites      map[string]string
+}
+
+var (
+	categories   *categorySet
+	categoriesMu sync.RWMutex
+)
+
+func GetCategoriesPath() (string, error) {
+	dataDir, err := system.GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "categories.json"), nil
+}
+
+func LoadCategoriesFile() (*CategoriesFile, error) {
+	path, err := GetCategoriesPath()
+	if err != nil {
+		return nil, err
+	}
+
+	file := &CategoriesFile{}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, file); err != nil {
+			return nil, fmt.Errorf("invalid categories.json: %w", err)
+		}
+	}
+
+	if file.Ratings == nil {
+		file.Ratings = make(map[string]string)
+	}
+	if file.Apps == nil {
+		file.Apps = make(map[string]string)
+	}
+	if file.Sites == nil {
+		file.Sites = make(map[string]string)
+	}
+	return file, nil
+}
+
+func SaveCategoriesFile(file *CategoriesFile) error {
+	if _, err := buildCategories(file); err != nil {
+		return err
+	}
+
+	path, err := GetCategoriesPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	return ReloadCategories()
+}
+
+func isRating(rating string) bool {
+	for _, r := range Ratings {
+		if r == rating {
+			return true
+		}
+	}
+	return false
+}
+
+func categoryAppKey(name string) string {
+	return strings.TrimSuffix(system.CanonicalExeName(name), ".exe")
+}
+
+func buildCategories(file *CategoriesFile) (*categorySet, error) {
+	set := &categorySet{
+		categories: make(map[string]Category),
+		apps:       make(map[string]string),
+		sites:      make(map[string]string),
+	}
+	for name, rating := range builtinCategories {
+		set.categories[strings.ToLower(name)] = Category{Name: name, Rating: rating}
+	}
+	for exe, category := range builtinAppCategories {
+		set.apps[exe] = category
+	}
+	for site, category := range builtinSiteCategories {
+		set.sites[strings.ToLower(site)] = category
+	}
+	if file == nil {
+		return set, nil
+	}
+
+	for name, rating := range file.Ratings {
+		name = strings.TrimSpace(name)
+		rating = strings.ToLower(strings.TrimSpace(rating))
+		if name == "" {
+			return nil, fmt.Errorf("ratings: category name cannot be empty")
+		}
+		if !isRating(rating) {
+			return nil, fmt.Errorf("ratings: category %q has unknown rating %q (use %s)", name, rating, strings.Join(Ratings, ", "))
+		}
+		if existing, ok := set.categories[strings.ToLower(name)]; ok {
+			name = existing.Name
+		}
+		set.categories[strings.ToLower(name)] = Category{Name: name, Rating: rating, Custom: true}
+	}
+
+	resolve := func(section, key, category string) (string, error) {
+		c, ok := set.categories[strings.ToLower(strings.TrimSpace(category))]
+		if !ok {
+			return "", fmt.Errorf("%s: %q uses unknown category %q", section, key, category)
+		}
+		return c.Name, nil
+	}
+	for app, category := range file.Apps {
+		name, err := resolve("apps", app, category)
+		if err != nil {
+			return nil, err
+		}
+		set.apps[categoryAppKey(app)] = name
+	}
+	for site, category := range file.Sites {
+		name, err := resolve("sites", site, category)
+		if err != nil {
+			return nil, err
+		}
+		set.sites[strings.ToLower(strings.TrimSpace(site))] = name
+	}
+	return set, nil
+}
+
+func ReloadCategories() error {
+	file, err := LoadCategoriesFile()
+	if err != nil {
+		return err
+	}
+	set, err := buildCategories(file)
+	if err != nil {
+		return fmt.Errorf("invalid categories.json: %w", err)
+	}
+
+	categoriesMu.Lock()
+	categories = set
+	categoriesMu.Unlock()
+	return nil
+}
+
+func getCategories() *categorySet {
+	categoriesMu.RLock()
+	set := categories
+	categoriesMu.RUnlock()
+	if set != nil {
+		return set
+	}
+
+	if err := ReloadCategories(); err != nil {
+		set, _ = buildCategories(nil)
+		categoriesMu.Lock()
+		categories = set
+		categoriesMu.Unlock()
+		return set
+	}
+
+	categoriesMu.RLock()
+	defer categoriesMu.RUnlock()
+	return categories
+}
+
+func GetCategories() []Category {
+	set := getCategories()
+	list := make([]Category, 0, len(set.categories))
+	for _, c := range set.categories {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+func CategoryRating(category string) string {
+	if c, ok := getCategories().categories[strings.ToLower(category)]; ok {
+		return c.Rating
+	}
+	return RatingNeutral
+}
+
+func CategoryForApp(exeName, appName string) string {
+	return getCategories().appCategory(exeName, appName, storage.IsBrowser(exeName))
+}
+
+func CategoryForSite(title string) string {
+	return getCategories().siteCategory(ExtractAppCategory(title), title)
+}
+
+func (set *categorySet) appCategory(exeName, appName string, isBrowser bool) string {
+	for _, key := range []string{categoryAppKey(appName), categoryAppKey(exeName)} {
+		if category, ok := set.apps[key]; ok {
+			return category
+		}
+	}
+	if isBrowser {
+		return CategoryBrowsing
+	}
+	return CategoryUncategorized
+}
+
+func (set *categorySet) siteCategory(group, title string) string {
+	if group == "" {
+		group = title
+	}
+	if category, ok := set.sites[strings.ToLower(group)]; ok {
+		return category
+	}
+	return CategoryBrowsing
+}
+
+func SetCategoryRating(name, rating string) error {
+	file, err := LoadCategoriesFile()
+	if err != nil {
+		return err
+	}
+
+	name = strings.TrimSpace(name)
+	for key := range file.Ratings {
+		if strings.EqualFold(key, name) {
+			delete(file.Ratings, key)
+		}
+	}
+	if rating != "" {
+		file.Ratings[name] = strings.ToLower(strings.TrimSpace(rating))
+	} else if !isBuiltinCategory(name) {
+		for _, m := range []map[string]string{file.Apps, file.Sites} {
+			for key, category := range m {
+				if strings.EqualFold(category, name) {
+					delete(m, key)
+				}
+			}
+		}
+	}
+	return SaveCategoriesFile(file)
+}
+
+func isBuiltinCategory(name string) bool {
+	for c := range builtinCategories {
+		if strings.EqualFold(c, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func SetAppCategory(nameOrExe, category string) error {
+	file, err := LoadCategoriesFile()
+	if err != nil {
+		return err
+	}
+
+	key := categoryAppKey(nameOrExe)
+	for k := range file.Apps {
+		if categoryAppKey(k) == key {
+			delete(file.Apps, k)
+		}
+	}
+	if category != "" {
+		file.Apps[strings.TrimSpace(nameOrExe)] = category
+	}
+	return SaveCategoriesFile(file)
+}
+
+func SetSiteCategory(group, category string) error {
+	file, err := LoadCategoriesFile()
+	if err != nil {
+		return err
+	}
+
+	group = strings.TrimSpace(group)
+	for k := range file.Sites {
+		if strings.EqualFold(k, group) {
+			delete(file.Sites, k)
+		}
+	}
+	if category != "" {
+		file.Sites[group] = category
+	}
+	return SaveCategoriesFile(file)
+}
+
+type usageCategorizer struct {
+	isBrowser    func(exeName string) bool
+	appCategory  func(exeName, appName string, isBrowser bool) string
+	siteCategory func(title string) string
+}
+
+// totals counts browser time through the sites it was spent on. Whatever the
+// sites do not cover goes to the category the browser itself is assigned, so
+// browser time is never counted both as an app and as sites.
+func (c usageCategorizer) totals(apps, sites []storage.AppDailyStat) map[string]int {
+	totals := make(map[string]int)
+	browserCategorySecs := make(map[string]int)
+	browserSecs, siteSecs := 0, 0
+
+	for _, a := range apps {
+		isBrowser := c.isBrowser(a.ExeName)
+		category := c.appCategory(a.ExeName, a.AppName, isBrowser)
+		if isBrowser {
+			browserCategorySecs[category] += a.TotalDurationSecs
+			browserSecs += a.TotalDurationSecs
+			continue
+		}
+		totals[category] += a.TotalDurationSecs
+	}
+
+	for _, s := range sites {
+		totals[c.siteCategory(s.AppName)] += s.TotalDurationSecs
+		siteSecs += s.TotalDurationSecs
+	}
+	if browserSecs <= siteSecs {
+		return totals
+	}
+
+	categories := make([]string, 0, len(browserCategorySecs))
+	for category := range browserCategorySecs {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	remaining := browserSecs - siteSecs
+	left := remaining
+	for i, category := range categories {
+		share := left
+		if i < len(categories)-1 {
+			share = remaining * browserCategorySecs[category] / browserSecs
+		}
+		totals[category] += share
+		left -= share
+	}
+	return totals
+}
+
+func CategorizeUsage(apps, sites []storage.AppDailyStat) []CategoryStat {
+	set := getCategories()
+	totals := usageCategorizer{
+		isBrowser:    storage.IsBrowser,
+		appCategory:  set.appCategory,
+		siteCategory: CategoryForSite,
+	}.totals(apps, sites)
+
+	var stats []CategoryStat
+	for category, secs := range totals {
+		if secs <= 0 {
+			continue
+		}
+		stats = append(stats, CategoryStat{Category: category, Rating: CategoryRating(category), TotalSecs: secs})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].TotalSecs != stats[j].TotalSecs {
+			return stats[i].TotalSecs > stats[j].TotalSecs
+		}
+		return stats[i].Category < stats[j].Category
+	})
+	return stats
+}
+
+func ProductivityScore(stats []CategoryStat) int {
+	total, weighted := 0, 0.0
+	for _, s := range stats {
+		total += s.TotalSecs
+		switch s.Rating {
+		case RatingProductive:
+			weighted += float64(s.TotalSecs)
+		case RatingNeutral:
+			weighted += float64(s.TotalSecs) / 2
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return int(math.Round(weighted * 100 / float64(total)))
+}
+
+func GetProductivityInRange(startDate, endDate string) ([]DayProductivity, error) {
+	apps, err := storage.GetAppStatsInRange(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	sites, err := storage.GetBrowserStatsInRange(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	appsByDate := make(map[string][]storage.AppDailyStat)
+	sitesByDate := make(map[string][]storage.AppDailyStat)
+	var dates []string
+	for _, a := range apps {
+		if _, ok := appsByDate[a.Date]; !ok {
+			dates = append(dates, a.Date)
+		}
+		appsByDate[a.Date] = append(appsByDate[a.Date], a)
+	}
+	for _, s := range sites {
+		sitesByDate[s.Date] = append(sitesByDate[s.Date], s)
+	}
+	sort.Strings(dates)
+
+	var days []DayProductivity
+	for _, date := range dates {
+		stats := CategorizeUsage(appsByDate[date], sitesByDate[date])
+		days = append(days, DayProductivity{
+			Date:              date,
+			ProductivityScore: ProductivityScore(stats),
+			Categories:        stats,
+		})
+	}
+	return days, nil
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"focusd/storage"
+	"reflect"
+	"testing"
+)
+
+func TestUsageCategorizerCountsBrowserTimeOnce(t *testing.T) {
+	browserCategories := map[string]string{}
+	categorizer := usageCategorizer{
+		isBrowser: func(exe string) bool { return exe == "chrome" || exe == "firefox" },
+		appCategory: func(exe, app string, isBrowser bool) string {
+			if category, ok := browserCategories[exe]; ok {
+				return category
+			}
+			if isBrowser {
+				return CategoryBrowsing
+			}
+			return "Development"
+		},
+		siteCategory: func(title string) string {
+			if title == "GitHub" {
+				return "Development"
+			}
+			return "Entertainment"
+		},
+	}
+
+	app := func(exe string, secs int) storage.AppDailyStat {
+		return storage.AppDailyStat{ExeName: exe, AppName: exe, TotalDurationSecs: secs}
+	}
+	site := func(title string, secs int) storage.AppDailyStat {
+		return storage.AppDailyStat{AppName: title, TotalDurationSecs: secs}
+	}
+
+	tests := []struct {
+		name       string
+		categories map[string]string
+		apps       []storage.AppDailyStat
+		sites      []storage.AppDailyStat
+		want       map[string]int
+	}{
+		{
+			name:  "uncovered browser time is browsing",
+			apps:  []storage.AppDailyStat{app("code", 600), app("chrome", 500)},
+			sites: []storage.AppDailyStat{site("GitHub", 200), site("YouTube", 100)},
+			want:  map[string]int{"Development": 800, "Entertainment": 100, CategoryBrowsing: 200},
+		},
+		{
+			name:       "browser assigned a category",
+			categories: map[string]string{"chrome": "Work"},
+			apps:       []storage.AppDailyStat{app("chrome", 500)},
+			sites:      []storage.AppDailyStat{site("GitHub", 200), site("YouTube", 100)},
+			want:       map[string]int{"Development": 200, "Entertainment": 100, "Work": 200},
+		},
+		{
+			name:       "sites cover all browser time",
+			categories: map[string]string{"chrome": "Work"},
+			apps:       []storage.AppDailyStat{app("chrome", 300)},
+			sites:      []storage.AppDailyStat{site("GitHub", 300)},
+			want:       map[string]int{"Development": 300},
+		},
+		{
+			name:       "remainder split across browsers",
+			categories: map[string]string{"chrome": "Work"},
+			apps:       []storage.AppDailyStat{app("chrome", 300), app("firefox", 100)},
+			sites:      []storage.AppDailyStat{site("YouTube", 200)},
+			want:       map[string]int{"Entertainment": 200, "Work": 150, CategoryBrowsing: 50},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			browserCategories = tt.categories
+			got := categorizer.totals(tt.apps, tt.sites)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("totals = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
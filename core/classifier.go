@@ -10,6 +10,27 @@ var browserSuffixes = []string{
 	"google chrome", "mozilla firefox", "microsoft edge", "brave",
 	"opera", "vivaldi", "thorium", "librewolf", "chromium",
 	"zen browser", "arc", "internet explorer", "personal", "work",
+	"private browsing", "incognito", "inprivate",
+}
+
+var privateWindowMarkers = []string{
+	"inprivate", "incognito", "private browsing", "(private)", "private window",
+}
+
+var browserDisplayNames = map[string]string{
+	"chrome":    "Chrome",
+	"msedge":    "Edge",
+	"firefox":   "Firefox",
+	"brave":     "Brave",
+	"opera":     "Opera",
+	"vivaldi":   "Vivaldi",
+	"librewolf": "LibreWolf",
+	"zen":       "Zen",
+	"floorp":    "Floorp",
+	"waterfox":  "Waterfox",
+	"chromium":  "Chromium",
+	"thorium":   "Thorium",
+	"iexplore":  "Internet Explorer",
 }
 
 func IsBrowser(exeName string) bool {
@@ -98,6 +119,35 @@ func stripNotificationCount(title string) string {
 	return title
 }
 
+func IsPrivateWindow(rawTitle string) bool {
+	lower := strings.ToLower(rawTitle)
+	if strings.Contains(lower, "[inprivate]") {
+		return true
+	}
+
+	suffix := lower
+	for _, sep := range []string{" - ", " — ", " | "} {
+		if idx := strings.LastIndex(lower, sep); idx != -1 && len(lower)-idx-len(sep) < len(suffix) {
+			suffix = lower[idx+len(sep):]
+		}
+	}
+	for _, marker := range privateWindowMarkers {
+		if strings.Contains(suffix, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func PrivateWindowTitle(exeName string) string {
+	base := strings.TrimSuffix(strings.ToLower(exeName), ".exe")
+	name, ok := browserDisplayNames[base]
+	if !ok {
+		name = getAppName(exeName)
+	}
+	return name + " (Private)"
+}
+
 func CleanWindowTitle(rawTitle, exeName string) string {
 	if rawTitle == "" {
 		return "Unknown Tab"
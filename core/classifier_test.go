@@ -0,0 +1,51 @@
+package core
+
+import "testing"
+
+func TestIsPrivateWindow(t *testing.T) {
+	tests := []struct {
+		title string
+		want  bool
+	}{
+		{"New Tab - [InPrivate] - Microsoft Edge", true},
+		{"Bing - [INPRIVATE] - Microsoft Edge", true},
+		{"Mozilla Firefox Private Browsing", true},
+		{"Cats - Mozilla Firefox Private Browsing", true},
+		{"New Tab - Google Chrome (Incognito)", true},
+		{"GitHub — Firefox Private Browsing", true},
+		{"Docs | Private Window", true},
+		{"Start Page - Vivaldi (Private)", true},
+
+		{"Private Browsing in Firefox - explained - Mozilla Firefox", false},
+		{"Keeping your data private - Blog - Google Chrome", false},
+		{"InPrivate mode guide - Microsoft Edge", false},
+		{"Incognito mode - Wikipedia - Google Chrome", false},
+		{"private", false},
+		{"New Tab - Google Chrome", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsPrivateWindow(tt.title); got != tt.want {
+			t.Errorf("IsPrivateWindow(%q) = %v, want %v", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestPrivateWindowTitle(t *testing.T) {
+	tests := []struct {
+		exe  string
+		want string
+	}{
+		{"chrome", "Chrome (Private)"},
+		{"msedge.exe", "Edge (Private)"},
+		{"MSEDGE.EXE", "Edge (Private)"},
+		{"Firefox.exe", "Firefox (Private)"},
+		{"librewolf", "LibreWolf (Private)"},
+		{"iexplore.exe", "Internet Explorer (Private)"},
+	}
+	for _, tt := range tests {
+		if got := PrivateWindowTitle(tt.exe); got != tt.want {
+			t.Errorf("PrivateWindowTitle(%q) = %q, want %q", tt.exe, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"errors"
+	"focusd/storage"
+	"focusd/system"
+	"os"
+	"sync"
+	"time"
+)
+
+type watchedFile struct {
+	path    func() (string, error)
+	reload  func() error
+	modTime time.Time
+	size    int64
+}
+
+type ConfigWatcher struct {
+	mu    sync.Mutex
+	files []*watchedFile
+}
+
+func NewConfigWatcher() *ConfigWatcher {
+	w := &ConfigWatcher{
+		files: []*watchedFile{
+			{path: system.GetUserConfigPath, reload: system.ReloadUserConfig},
+			{path: storage.GetBrowserConfigPath, reload: storage.ReloadBrowserConfig},
+			{path: GetGroupRulesPath, reload: ReloadGroupRules},
+			{path: GetAppRulesPath, reload: ReloadAppRules},
+			{path: GetCategoriesPath, reload: ReloadCategories},
+		},
+	}
+	for _, f := range w.files {
+		f.modTime, f.size = statFile(f.path)
+	}
+	return w
+}
+
+func statFile(path func() (string, error)) (time.Time, int64) {
+	p, err := path()
+	if err != nil || p == "" {
+		return time.Time{}, 0
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		return time.Time{}, 0
+	}
+	return info.ModTime(), info.Size()
+}
+
+func (w *ConfigWatcher) Check() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var errs []error
+	for _, f := range w.files {
+		modTime, size := statFile(f.path)
+		if modTime.Equal(f.modTime) && size == f.size {
+			continue
+		}
+		f.modTime, f.size = modTime, size
+
+		if err := f.reload(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (w *ConfigWatcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var errs []error
+	for _, f := range w.files {
+		f.modTime, f.size = statFile(f.path)
+		if err := f.reload(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,25 @@
+package core
+
+import (
+	"focusd/storage"
+	"time"
+)
+
+func siteForSession(s storage.Session) (string, bool) {
+	if s.ExeName == IdleExeName || !storage.IsBrowser(s.ExeName) {
+		return "", false
+	}
+	if s.Site != "" {
+		return s.Site, true
+	}
+	site := storedSite(s.WindowTitle, s.ExeName)
+	return site, site != ""
+}
+
+func ClearDataSince(since time.Time) error {
+	return storage.DeleteSessionsSince(since, siteForSession)
+}
+
+func ClearAllData() error {
+	return storage.ClearAllTrackingData()
+}
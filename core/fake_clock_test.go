@@ -0,0 +1,120 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+func newFakeClock(start time.Time) *fakeClock {
+	return &fakeClock{now: start}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTicker{
+		clock:    c,
+		interval: d,
+		next:     c.now.Add(d),
+		ch:       make(chan time.Time),
+		done:     make(chan struct{}),
+	}
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+func (c *fakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	c.now = t
+	c.mu.Unlock()
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		t := c.nextDue(target)
+		if t == nil {
+			break
+		}
+		t.fire()
+	}
+
+	c.Set(target)
+}
+
+func (c *fakeClock) nextDue(target time.Time) *fakeTicker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var due *fakeTicker
+	for _, t := range c.tickers {
+		if t.next.After(target) {
+			continue
+		}
+		if due == nil || t.next.Before(due.next) {
+			due = t
+		}
+	}
+	if due != nil {
+		c.now = due.next
+	}
+	return due
+}
+
+func (c *fakeClock) removeTicker(t *fakeTicker) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, existing := range c.tickers {
+		if existing == t {
+			c.tickers = append(c.tickers[:i], c.tickers[i+1:]...)
+			return
+		}
+	}
+}
+
+type fakeTicker struct {
+	clock    *fakeClock
+	interval time.Duration
+	next     time.Time
+	ch       chan time.Time
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTicker) Stop() {
+	t.stopOnce.Do(func() {
+		t.clock.removeTicker(t)
+		close(t.done)
+	})
+}
+
+func (t *fakeTicker) fire() {
+	t.clock.mu.Lock()
+	at := t.next
+	t.next = t.next.Add(t.interval)
+	t.clock.mu.Unlock()
+
+	select {
+	case t.ch <- at:
+	case <-t.done:
+	}
+}
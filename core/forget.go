@@ -0,0 +1,138 @@
+package core
+
+import (
+	"focusd/storage"
+	"focusd/system"
+	"strings"
+)
+
+func appNameVariants(exeName string) []string {
+	name := strings.ToLower(strings.TrimSpace(exeName))
+	if name == "" {
+		return nil
+	}
+	if strings.HasSuffix(name, ".exe") {
+		return []string{name, strings.TrimSuffix(name, ".exe")}
+	}
+	return []string{name, name + ".exe"}
+}
+
+func matchesApp(variants []string, exeName string) bool {
+	for _, v := range variants {
+		if strings.EqualFold(exeName, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesSite(pattern, title string, siteGroup func(string) string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "" || title == "" {
+		return false
+	}
+	if strings.ToLower(siteGroup(title)) == pattern {
+		return true
+	}
+	return strings.Contains(strings.ToLower(title), pattern)
+}
+
+// isSameSite is the strict match used for deletion: the stored site key or
+// its group must equal the name given, so "news" does not forget every title
+// that happens to contain the word.
+func isSameSite(name, site string, siteGroup func(string) string) bool {
+	name = strings.TrimSpace(name)
+	if name == "" || site == "" {
+		return false
+	}
+	return strings.EqualFold(site, name) || strings.EqualFold(siteGroup(site), name)
+}
+
+func IsSiteWhitelisted(rawTitle, exeName string) bool {
+	return isSiteWhitelisted(rawTitle, exeName, system.GetWhitelistSites(), ExtractAppCategory)
+}
+
+func isSiteWhitelisted(rawTitle, exeName string, patterns []string, siteGroup func(string) string) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+	title := CleanWindowTitle(rawTitle, exeName)
+	for _, p := range patterns {
+		if matchesSite(p, title, siteGroup) {
+			return true
+		}
+	}
+	return false
+}
+
+type ForgetPreview struct {
+	Sessions  int
+	TotalSecs int
+	Sample    []storage.Session
+}
+
+const forgetSampleSize = 5
+
+func appMatcher(exeName string) func(storage.Session) bool {
+	variants := appNameVariants(exeName)
+	return func(s storage.Session) bool {
+		return matchesApp(variants, s.ExeName)
+	}
+}
+
+func siteMatcher(name string) func(storage.Session) bool {
+	return func(s storage.Session) bool {
+		site, ok := siteForSession(s)
+		return ok && isSameSite(name, site, ExtractAppCategory)
+	}
+}
+
+func PreviewForgetApp(exeName string) (*ForgetPreview, error) {
+	return previewForget(appMatcher(exeName))
+}
+
+func PreviewForgetSite(name string) (*ForgetPreview, error) {
+	return previewForget(siteMatcher(name))
+}
+
+func previewForget(match func(storage.Session) bool) (*ForgetPreview, error) {
+	sessions, err := storage.GetAllSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &ForgetPreview{}
+	for _, s := range sessions {
+		if !match(s) {
+			continue
+		}
+		preview.Sessions++
+		preview.TotalSecs += s.DurationSecs
+		if len(preview.Sample) < forgetSampleSize {
+			preview.Sample = append(preview.Sample, s)
+		}
+	}
+	return preview, nil
+}
+
+func ForgetApp(exeName string) (*storage.ForgetResult, error) {
+	result, err := storage.ForgetSessions(appMatcher(exeName), siteForSession)
+	if err != nil {
+		return nil, err
+	}
+
+	result.AggregateRows, err = storage.DeleteAppAggregates(appNameVariants(exeName))
+	return result, err
+}
+
+func ForgetSite(name string) (*storage.ForgetResult, error) {
+	result, err := storage.ForgetSessions(siteMatcher(name), siteForSession)
+	if err != nil {
+		return nil, err
+	}
+
+	result.AggregateRows, err = storage.DeleteSiteAggregates(func(site string) bool {
+		return isSameSite(name, site, ExtractAppCategory)
+	})
+	return result, err
+}
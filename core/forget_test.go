@@ -0,0 +1,115 @@
+package core
+
+import (
+	"focusd/storage"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsSameSite(t *testing.T) {
+	siteGroup := func(site string) string {
+		if strings.HasSuffix(site, " - YouTube") {
+			return "YouTube"
+		}
+		return ""
+	}
+
+	tests := []struct {
+		name string
+		site string
+		want bool
+	}{
+		{"Hacker News", "Hacker News", true},
+		{"hacker news", "Hacker News", true},
+		{"  Hacker News ", "Hacker News", true},
+		{"news", "Hacker News", false},
+		{"Hacker", "Hacker News", false},
+		{"youtube", "Cat videos - YouTube", true},
+		{"cat videos", "Cat videos - YouTube", false},
+		{"", "Hacker News", false},
+		{"Hacker News", "", false},
+	}
+	for _, tt := range tests {
+		if got := isSameSite(tt.name, tt.site, siteGroup); got != tt.want {
+			t.Errorf("isSameSite(%q, %q) = %v, want %v", tt.name, tt.site, got, tt.want)
+		}
+	}
+}
+
+func TestForgetSiteOnlyDeletesThatSite(t *testing.T) {
+	openAppRulesDB(t)
+
+	base := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	for i, site := range []string{"Hacker News", "Good news everyone", "Hacker News", "Hacker Newsletter"} {
+		start := base.Add(time.Duration(i) * time.Hour)
+		if err := storage.InsertSession(&storage.Session{
+			AppName: "Chrome", ExeName: "chrome", WindowTitle: site, Site: site,
+			StartTime: start, EndTime: start.Add(10 * time.Minute), DurationSecs: 600, Date: "2026-03-10",
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	preview, err := PreviewForgetSite("news")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Sessions != 0 {
+		t.Errorf("PreviewForgetSite(news) matched %d sessions, want 0", preview.Sessions)
+	}
+
+	preview, err = PreviewForgetSite("hacker news")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Sessions != 2 || preview.TotalSecs != 1200 || len(preview.Sample) != 2 {
+		t.Errorf("PreviewForgetSite(hacker news) = %+v, want 2 sessions and 1200s", preview)
+	}
+
+	result, err := ForgetSite("hacker news")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Sessions != 2 {
+		t.Errorf("ForgetSite deleted %d sessions, want 2", result.Sessions)
+	}
+
+	sessions, err := storage.GetAllSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var left []string
+	for _, s := range sessions {
+		left = append(left, s.Site)
+	}
+	if strings.Join(left, ",") != "Hacker Newsletter,Good news everyone" {
+		t.Errorf("sessions left = %q, want the two other sites", left)
+	}
+}
+
+func TestPreviewForgetAppSamplesNewestFirst(t *testing.T) {
+	openAppRulesDB(t)
+
+	base := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	for i := range forgetSampleSize + 2 {
+		start := base.Add(time.Duration(i) * time.Hour)
+		if err := storage.InsertSession(&storage.Session{
+			AppName: "Slack", ExeName: "slack", WindowTitle: "general",
+			StartTime: start, EndTime: start.Add(time.Minute), DurationSecs: 60, Date: "2026-03-10",
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	preview, err := PreviewForgetApp("Slack.exe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Sessions != forgetSampleSize+2 || len(preview.Sample) != forgetSampleSize {
+		t.Fatalf("preview = %d sessions, %d sampled; want %d and %d", preview.Sessions, len(preview.Sample), forgetSampleSize+2, forgetSampleSize)
+	}
+	if !preview.Sample[0].StartTime.After(preview.Sample[1].StartTime) {
+		t.Error("sample is not newest first")
+	}
+}
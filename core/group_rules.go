@@ -0,0 +1,283 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"focusd/system"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const defaultGroupPriority = 100
+
+type GroupRule struct {
+	Name       string   `json:"name"`
+	Substrings []string `json:"substrings,omitempty"`
+	Regex      []string `json:"regex,omitempty"`
+	Priority   int      `json:"priority,omitempty"`
+	Disabled   bool     `json:"disabled,omitempty"`
+}
+
+type GroupRulesFile struct {
+	Rules []GroupRule `json:"rules"`
+}
+
+type EffectiveGroupRule struct {
+	appPattern
+	Custom bool
+}
+
+var (
+	groupRules   []EffectiveGroupRule
+	groupRulesMu sync.RWMutex
+)
+
+func GetGroupRulesPath() (string, error) {
+	dataDir, err := system.GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "groups.json"), nil
+}
+
+func LoadGroupRulesFile() (*GroupRulesFile, error) {
+	path, err := GetGroupRulesPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &GroupRulesFile{Rules: []GroupRule{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file GroupRulesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid groups.json: %w", err)
+	}
+	return &file, nil
+}
+
+func SaveGroupRulesFile(file *GroupRulesFile) error {
+	if _, err := buildGroupRules(file); err != nil {
+		return err
+	}
+
+	path, err := GetGroupRulesPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	return ReloadGroupRules()
+}
+
+func compileGroupRule(i int, r GroupRule) (appPattern, error) {
+	label := fmt.Sprintf("rule %d", i+1)
+	if strings.TrimSpace(r.Name) != "" {
+		label = fmt.Sprintf("rule %d (%q)", i+1, r.Name)
+	}
+
+	if strings.TrimSpace(r.Name) == "" {
+		return appPattern{}, fmt.Errorf("%s: name is required", label)
+	}
+	if r.Priority < 0 {
+		return appPattern{}, fmt.Errorf("%s: priority must not be negative", label)
+	}
+	if !r.Disabled && len(r.Substrings) == 0 && len(r.Regex) == 0 {
+		return appPattern{}, fmt.Errorf("%s: needs at least one substring or regex", label)
+	}
+
+	p := appPattern{Name: strings.TrimSpace(r.Name), Priority: r.Priority}
+	if p.Priority == 0 {
+		p.Priority = defaultGroupPriority
+	}
+	for _, s := range r.Substrings {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s == "" {
+			return appPattern{}, fmt.Errorf("%s: empty substring", label)
+		}
+		p.Patterns = append(p.Patterns, s)
+	}
+	for _, expr := range r.Regex {
+		if _, err := regexp.Compile(expr); err != nil {
+			return appPattern{}, fmt.Errorf("%s: invalid regex %q: %v", label, expr, err)
+		}
+		p.Regexes = append(p.Regexes, regexp.MustCompile("(?i)"+expr))
+	}
+	return p, nil
+}
+
+func buildGroupRules(file *GroupRulesFile) ([]EffectiveGroupRule, error) {
+	overridden := make(map[string]bool)
+	var rules []EffectiveGroupRule
+
+	if file != nil {
+		seen := make(map[string]int)
+		for i, r := range file.Rules {
+			p, err := compileGroupRule(i, r)
+			if err != nil {
+				return nil, err
+			}
+			key := strings.ToLower(p.Name)
+			if prev, ok := seen[key]; ok {
+				return nil, fmt.Errorf("rule %d (%q): duplicate of rule %d", i+1, r.Name, prev+1)
+			}
+			seen[key] = i
+			overridden[key] = true
+
+			if !r.Disabled {
+				rules = append(rules, EffectiveGroupRule{appPattern: p, Custom: true})
+			}
+		}
+	}
+
+	for _, p := range appPatterns {
+		if !overridden[strings.ToLower(p.Name)] {
+			rules = append(rules, EffectiveGroupRule{appPattern: p})
+		}
+	}
+	return rules, nil
+}
+
+func ReloadGroupRules() error {
+	file, err := LoadGroupRulesFile()
+	if err != nil {
+		return err
+	}
+	rules, err := buildGroupRules(file)
+	if err != nil {
+		return fmt.Errorf("invalid groups.json: %w", err)
+	}
+
+	groupRulesMu.Lock()
+	groupRules = rules
+	groupRulesMu.Unlock()
+	return nil
+}
+
+func GetGroupRules() []EffectiveGroupRule {
+	groupRulesMu.RLock()
+	rules := groupRules
+	groupRulesMu.RUnlock()
+	if rules != nil {
+		return rules
+	}
+
+	if err := ReloadGroupRules(); err != nil {
+		rules, _ = buildGroupRules(nil)
+		groupRulesMu.Lock()
+		groupRules = rules
+		groupRulesMu.Unlock()
+		return rules
+	}
+
+	groupRulesMu.RLock()
+	defer groupRulesMu.RUnlock()
+	return groupRules
+}
+
+func IsBuiltinGroup(name string) bool {
+	for _, p := range appPatterns {
+		if strings.EqualFold(p.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func AddGroupPattern(name, pattern string, isRegex bool, priority int) error {
+	file, err := LoadGroupRulesFile()
+	if err != nil {
+		return err
+	}
+
+	idx := -1
+	for i, r := range file.Rules {
+		if strings.EqualFold(r.Name, name) {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		rule := GroupRule{Name: strings.TrimSpace(name)}
+		for _, p := range appPatterns {
+			if strings.EqualFold(p.Name, name) {
+				rule = GroupRule{Name: p.Name, Substrings: append([]string(nil), p.Patterns...), Priority: p.Priority}
+				break
+			}
+		}
+		file.Rules = append(file.Rules, rule)
+		idx = len(file.Rules) - 1
+	}
+
+	rule := &file.Rules[idx]
+	rule.Disabled = false
+	if isRegex {
+		rule.Regex = append(rule.Regex, pattern)
+	} else {
+		rule.Substrings = append(rule.Substrings, pattern)
+	}
+	if priority > 0 {
+		rule.Priority = priority
+	}
+	return SaveGroupRulesFile(file)
+}
+
+func RemoveGroup(name string) error {
+	file, err := LoadGroupRulesFile()
+	if err != nil {
+		return err
+	}
+
+	var updated []GroupRule
+	found := false
+	for _, r := range file.Rules {
+		if strings.EqualFold(r.Name, name) {
+			found = true
+			continue
+		}
+		updated = append(updated, r)
+	}
+
+	if IsBuiltinGroup(name) {
+		updated = append(updated, GroupRule{Name: name, Disabled: true})
+		found = true
+	}
+	if !found {
+		return fmt.Errorf("no group named %q", name)
+	}
+
+	file.Rules = updated
+	return SaveGroupRulesFile(file)
+}
+
+func TestGroupRules(title string) (string, []EffectiveGroupRule) {
+	var matched []EffectiveGroupRule
+	for _, r := range GetGroupRules() {
+		if r.matches(strings.ToLower(title), title) {
+			matched = append(matched, r)
+		}
+	}
+	return ExtractAppCategory(title), matched
+}
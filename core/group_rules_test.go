@@ -0,0 +1,145 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func resetGroupRules(t *testing.T) {
+	t.Helper()
+	isolateDataDir(t)
+	clear := func() {
+		groupRulesMu.Lock()
+		groupRules = nil
+		groupRulesMu.Unlock()
+	}
+	clear()
+	t.Cleanup(clear)
+}
+
+func TestExtractAppCategoryBuiltin(t *testing.T) {
+	rules, err := buildGroupRules(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Cat videos - YouTube", "YouTube"},
+		{"youtube.com/watch?v=123", "YouTube"},
+		{"focusd/core at main · 0xarchit/focusd · GitHub", "GitHub"},
+		{"EC2 Management Console - console.aws.amazon.com", "AWS"},
+		{"Headphones - Amazon.in", "Amazon"},
+		{"New Tab", "Browser (Idle)"},
+		{"Quarterly report.docx", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := extractAppCategory(tt.title, rules); got != tt.want {
+			t.Errorf("extractAppCategory(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestBuildGroupRulesOverridesBuiltins(t *testing.T) {
+	rules, err := buildGroupRules(&GroupRulesFile{Rules: []GroupRule{
+		{Name: "youtube", Substrings: []string{"piped.video"}},
+		{Name: "Reddit", Disabled: true},
+		{Name: "Work", Substrings: []string{"JIRA.MYCORP"}, Regex: []string{`^PROJ-\d+`}, Priority: 150},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Some video - piped.video", "youtube"},
+		{"Cat videos - YouTube", ""},
+		{"r/golang - Reddit", ""},
+		{"Board - jira.mycorp", "Work"},
+		{"proj-42 fix login", "Work"},
+		{"PROJ-42 - Jira", "Work"},
+		{"Pull requests - GitHub", "GitHub"},
+	}
+	for _, tt := range tests {
+		if got := extractAppCategory(tt.title, rules); got != tt.want {
+			t.Errorf("extractAppCategory(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+	for _, r := range rules {
+		if r.Name == "Work" && (!r.Custom || r.Priority != 150) {
+			t.Errorf("Work rule = %+v, want custom with priority 150", r)
+		}
+		if r.Name == "GitHub" && (r.Custom || r.Priority != defaultGroupPriority) {
+			t.Errorf("GitHub rule = %+v, want the builtin", r)
+		}
+	}
+}
+
+func TestBuildGroupRulesRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		rules   []GroupRule
+		wantErr string
+	}{
+		{[]GroupRule{{Name: " ", Substrings: []string{"x"}}}, "name is required"},
+		{[]GroupRule{{Name: "Work", Substrings: []string{"x"}, Priority: -1}}, "must not be negative"},
+		{[]GroupRule{{Name: "Work"}}, "at least one substring or regex"},
+		{[]GroupRule{{Name: "Work", Substrings: []string{"  "}}}, "empty substring"},
+		{[]GroupRule{{Name: "Work", Regex: []string{"("}}}, "invalid regex"},
+		{[]GroupRule{{Name: "Work", Substrings: []string{"a"}}, {Name: "work", Substrings: []string{"b"}}}, "duplicate of rule 1"},
+	}
+	for _, tt := range tests {
+		_, err := buildGroupRules(&GroupRulesFile{Rules: tt.rules})
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("buildGroupRules(%+v) error = %v, want %q", tt.rules, err, tt.wantErr)
+		}
+	}
+}
+
+func TestAddAndRemoveGroups(t *testing.T) {
+	resetGroupRules(t)
+
+	if err := AddGroupPattern("Work", "tracker.mycorp", false, 150); err != nil {
+		t.Fatal(err)
+	}
+	if got := ExtractAppCategory("Board - tracker.mycorp"); got != "Work" {
+		t.Errorf("after adding Work, group = %q, want Work", got)
+	}
+
+	if err := AddGroupPattern("youtube", "piped.video", false, 0); err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"Some video - piped.video", "Cat videos - YouTube"} {
+		if got := ExtractAppCategory(title); got != "YouTube" {
+			t.Errorf("after extending YouTube, group of %q = %q, want YouTube", title, got)
+		}
+	}
+
+	if err := RemoveGroup("YouTube"); err != nil {
+		t.Fatal(err)
+	}
+	if got := ExtractAppCategory("Cat videos - YouTube"); got != "" {
+		t.Errorf("after removing YouTube, group = %q, want none", got)
+	}
+	if err := RemoveGroup("Work"); err != nil {
+		t.Fatal(err)
+	}
+	if got := ExtractAppCategory("Board - tracker.mycorp"); got != "" {
+		t.Errorf("after removing Work, group = %q, want none", got)
+	}
+	if err := RemoveGroup("Nope"); err == nil {
+		t.Error("removing an unknown group succeeded")
+	}
+
+	file, err := LoadGroupRulesFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Rules) != 1 || file.Rules[0].Name != "YouTube" || !file.Rules[0].Disabled {
+		t.Errorf("groups.json rules = %+v, want only a disabled YouTube", file.Rules)
+	}
+}
@@ -0,0 +1,198 @@
+package core
+
+import (
+	"fmt"
+	"focusd/storage"
+	"focusd/system"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// limitAlerts tracks which limit alerts were delivered today. A warning only
+// counts as fired once the notifier shows it, and fired warnings are kept in
+// the repository so a restart does not repeat them.
+type limitAlerts struct {
+	mu       sync.Mutex
+	repo     storage.Repository
+	date     string
+	fired    map[string]bool
+	exceeded map[string]bool
+	snoozed  map[string]time.Time
+}
+
+func newLimitAlerts(repo storage.Repository) *limitAlerts {
+	return &limitAlerts{
+		repo:     repo,
+		fired:    make(map[string]bool),
+		exceeded: make(map[string]bool),
+		snoozed:  make(map[string]time.Time),
+	}
+}
+
+func (a *limitAlerts) reset(date string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.date == date {
+		return
+	}
+	a.date = date
+	a.fired = make(map[string]bool)
+	a.exceeded = make(map[string]bool)
+	a.snoozed = make(map[string]time.Time)
+
+	stored, err := a.repo.GetConfig(storage.ConfigKeyLimitWarningsFired)
+	if err != nil {
+		return
+	}
+	lines := strings.Split(stored, "\n")
+	if lines[0] != date {
+		return
+	}
+	for _, key := range lines[1:] {
+		a.fired[key] = true
+	}
+}
+
+func (a *limitAlerts) markFired(keys ...string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, key := range keys {
+		a.fired[key] = true
+	}
+
+	lines := []string{a.date}
+	for key := range a.fired {
+		lines = append(lines, key)
+	}
+	sort.Strings(lines[1:])
+	a.repo.SetConfig(storage.ConfigKeyLimitWarningsFired, strings.Join(lines, "\n"))
+}
+
+// exceededDue reports whether an exceeded alert should be shown for key. It is
+// shown once per stretch of use over the limit; markExceeded records delivery.
+func (a *limitAlerts) exceededDue(key string, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.exceeded[key] {
+		return false
+	}
+	if now.Before(a.snoozed[key]) {
+		a.exceeded[key] = true
+		return false
+	}
+	return true
+}
+
+func (a *limitAlerts) markExceeded(key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.exceeded[key] = true
+}
+
+func (a *limitAlerts) leave(key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.exceeded, key)
+}
+
+func (a *limitAlerts) snooze(key string, until time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.snoozed[key] = until
+}
+
+// warning returns the message for every warning threshold that has been
+// crossed and not yet delivered, along with the keys to mark once it is shown.
+func (a *limitAlerts) warning(key, label string, usedSecs, limitMinutes, pct, mins int) (string, []string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	limitSecs := limitMinutes * 60
+	remaining := limitSecs - usedSecs
+
+	var messages, keys []string
+	if mins > 0 && mins < limitMinutes && remaining <= mins*60 && !a.fired[key+"|minutes"] {
+		messages = append(messages, fmt.Sprintf("%s: %d min left of your %d min daily limit.", label, (remaining+59)/60, limitMinutes))
+		keys = append(keys, key+"|minutes")
+	}
+	if pct > 0 && usedSecs*100 >= limitSecs*pct && !a.fired[key+"|percent"] {
+		messages = append(messages, fmt.Sprintf("%s: %d of %d min used today.", label, usedSecs/60, limitMinutes))
+		keys = append(keys, key+"|percent")
+	}
+	return strings.Join(messages, "\n"), keys
+}
+
+func LimitNotificationTitle(target system.LimitTarget) string {
+	switch target.Kind {
+	case system.LimitKindSite:
+		return "Site Time Limit"
+	case system.LimitKindCategory:
+		return "Category Time Limit"
+	}
+	return "App Time Limit"
+}
+
+func LimitLabel(target system.LimitTarget, appName string) string {
+	switch target.Kind {
+	case system.LimitKindSite:
+		return target.Name
+	case system.LimitKindCategory:
+		return target.Name + " (category)"
+	}
+	if appName != "" {
+		return appName
+	}
+	return target.Name
+}
+
+func limitUsageSecs(cfg TrackerConfig, repo storage.Repository, target system.LimitTarget, date string) int {
+	apps, _ := repo.GetAppStatsForDate(date)
+
+	total := 0
+	switch target.Kind {
+	case system.LimitKindApp:
+		for _, a := range apps {
+			if system.CanonicalExeName(a.ExeName) == target.Name {
+				total += a.TotalDurationSecs
+			}
+		}
+	case system.LimitKindSite:
+		sites, _ := repo.GetBrowserStatsForDate(date)
+		for _, s := range sites {
+			if matchesSite(target.Name, s.AppName, cfg.SiteGroup) {
+				total += s.TotalDurationSecs
+			}
+		}
+	case system.LimitKindCategory:
+		sites, _ := repo.GetBrowserStatsForDate(date)
+		totals := usageCategorizer{
+			isBrowser:    repo.IsBrowser,
+			appCategory:  cfg.AppCategory,
+			siteCategory: cfg.SiteCategory,
+		}.totals(apps, sites)
+		for category, secs := range totals {
+			if strings.EqualFold(category, target.Name) {
+				total += secs
+			}
+		}
+	}
+	return total
+}
+
+func limitAppliesTo(cfg TrackerConfig, target system.LimitTarget, exeName, appName, siteTitle string, isBrowser bool) bool {
+	switch target.Kind {
+	case system.LimitKindApp:
+		return system.CanonicalExeName(exeName) == target.Name
+	case system.LimitKindSite:
+		return isBrowser && matchesSite(target.Name, siteTitle, cfg.SiteGroup)
+	case system.LimitKindCategory:
+		category := cfg.AppCategory(exeName, appName, isBrowser)
+		if isBrowser && siteTitle != "" {
+			category = cfg.SiteCategory(siteTitle)
+		}
+		return strings.EqualFold(category, target.Name)
+	}
+	return false
+}
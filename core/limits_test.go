@@ -0,0 +1,117 @@
+package core
+
+import (
+	"focusd/system"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLimitAlertsWarningThresholds(t *testing.T) {
+	tests := []struct {
+		name    string
+		used    int
+		limit   int
+		pct     int
+		mins    int
+		want    []string
+		wantLen int
+	}{
+		{name: "below both", used: 30 * 60, limit: 60, pct: 80, mins: 5},
+		{name: "percent only", used: 50 * 60, limit: 60, pct: 80, mins: 5, want: []string{"50 of 60 min used"}, wantLen: 1},
+		{name: "minutes only", used: 56 * 60, limit: 60, pct: 95, mins: 5, want: []string{"4 min left"}, wantLen: 1},
+		{name: "both in the same tick", used: 56 * 60, limit: 60, pct: 80, mins: 5, want: []string{"4 min left", "56 of 60 min used"}, wantLen: 2},
+		{name: "minutes not below limit", used: 4 * 60, limit: 5, pct: 0, mins: 5},
+		{name: "warnings disabled", used: 59 * 60, limit: 60},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alerts := newLimitAlerts(newMemoryRepository(time.Now))
+			alerts.reset("2026-03-10")
+
+			msg, keys := alerts.warning("app:code", "VS Code", tt.used, tt.limit, tt.pct, tt.mins)
+			if len(keys) != tt.wantLen {
+				t.Fatalf("warning keys = %v, want %d", keys, tt.wantLen)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(msg, w) {
+					t.Errorf("warning = %q, want it to contain %q", msg, w)
+				}
+			}
+		})
+	}
+}
+
+func TestLimitAlertsFireOnlyOnceDelivered(t *testing.T) {
+	repo := newMemoryRepository(time.Now)
+	alerts := newLimitAlerts(repo)
+	alerts.reset("2026-03-10")
+
+	first, keys := alerts.warning("app:code", "VS Code", 56*60, 60, 80, 5)
+	again, _ := alerts.warning("app:code", "VS Code", 56*60, 60, 80, 5)
+	if first == "" || again != first {
+		t.Fatalf("undelivered warning = %q then %q, want it repeated until shown", first, again)
+	}
+
+	alerts.markFired(keys...)
+	if msg, _ := alerts.warning("app:code", "VS Code", 57*60, 60, 80, 5); msg != "" {
+		t.Errorf("warning after delivery = %q, want none", msg)
+	}
+
+	restarted := newLimitAlerts(repo)
+	restarted.reset("2026-03-10")
+	if msg, _ := restarted.warning("app:code", "VS Code", 57*60, 60, 80, 5); msg != "" {
+		t.Errorf("warning after restart = %q, want none", msg)
+	}
+
+	restarted.reset("2026-03-11")
+	if msg, _ := restarted.warning("app:code", "VS Code", 57*60, 60, 80, 5); msg == "" {
+		t.Error("no warning on the next day, want the thresholds to reset")
+	}
+}
+
+func TestLimitAlertsExceededOncePerStretch(t *testing.T) {
+	alerts := newLimitAlerts(newMemoryRepository(time.Now))
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	alerts.reset("2026-03-10")
+
+	if !alerts.exceededDue("app:code", now) || !alerts.exceededDue("app:code", now) {
+		t.Fatal("exceeded alert not due until it is delivered")
+	}
+	alerts.markExceeded("app:code")
+	if alerts.exceededDue("app:code", now) {
+		t.Error("exceeded alert due again in the same stretch")
+	}
+
+	alerts.leave("app:code")
+	alerts.snooze("app:code", now.Add(time.Hour))
+	if alerts.exceededDue("app:code", now.Add(time.Minute)) {
+		t.Error("exceeded alert due while snoozed")
+	}
+}
+
+func TestReplaySiteLimitMatchesRawTitle(t *testing.T) {
+	isolateDataDir(t)
+	base := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	config := &staticConfig{
+		TitleMode: system.TitleModeHashed,
+		HashKey:   []byte("key"),
+		Limits: []system.TimeLimit{
+			{Target: system.LimitTarget{Kind: system.LimitKindSite, Name: "youtube"}, Minutes: 1},
+		},
+	}
+
+	result := replay(config, base, []replayStep{
+		{At: 0, Window: window("chrome", "Cats - YouTube - Google Chrome")},
+	}, 2*time.Minute)
+
+	exceeded := 0
+	for _, n := range result.Notifications {
+		if n.Title == LimitNotificationTitle(config.Limits[0].Target) {
+			exceeded++
+		}
+	}
+	if exceeded != 1 {
+		t.Errorf("notifications = %+v, want one site limit alert", result.Notifications)
+	}
+}
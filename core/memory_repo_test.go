@@ -0,0 +1,299 @@
+package core
+
+import (
+	"errors"
+	"focusd/storage"
+	"focusd/system"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var testBrowsers = map[string]bool{
+	system.CanonicalExeName("chrome"):  true,
+	system.CanonicalExeName("firefox"): true,
+	system.CanonicalExeName("msedge"):  true,
+}
+
+var errConfigNotFound = errors.New("config key not found")
+
+type memoryRepository struct {
+	mu           sync.Mutex
+	now          func() time.Time
+	nextID       int64
+	sessions     []storage.Session
+	appsDaily    map[string]*storage.AppDailyStat
+	browserDaily map[string]*storage.AppDailyStat
+	active       *storage.ActiveSessionRecord
+	config       map[string]string
+}
+
+func newMemoryRepository(now func() time.Time) *memoryRepository {
+	if now == nil {
+		now = time.Now
+	}
+	return &memoryRepository{
+		now:          now,
+		appsDaily:    make(map[string]*storage.AppDailyStat),
+		browserDaily: make(map[string]*storage.AppDailyStat),
+		config:       make(map[string]string),
+	}
+}
+
+func (r *memoryRepository) today() string {
+	return r.now().Format("2006-01-02")
+}
+
+func (r *memoryRepository) InsertSession(s *storage.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	row := *s
+	row.ID = r.nextID
+	r.sessions = append(r.sessions, row)
+	return nil
+}
+
+func (r *memoryRepository) InsertSessionsBatch(sessions []*storage.Session) error {
+	for _, s := range sessions {
+		r.InsertSession(s)
+	}
+	return nil
+}
+
+func (r *memoryRepository) UpdateAppDaily(date, appName, exeName string, durationSecs int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	exeName = system.CanonicalExeName(exeName)
+	key := date + "|" + exeName + "|" + appName
+	stat, ok := r.appsDaily[key]
+	if !ok {
+		stat = &storage.AppDailyStat{Date: date, AppName: appName, ExeName: exeName}
+		r.appsDaily[key] = stat
+	}
+	stat.TotalDurationSecs += durationSecs
+	stat.OpenCount++
+	return nil
+}
+
+func (r *memoryRepository) GetAppStatsForDate(date string) ([]storage.AppDailyStat, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return filterStats(r.appsDaily, date), nil
+}
+
+func (r *memoryRepository) GetAppUsageTodayMinutes(exeName string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prefix := r.today() + "|" + system.CanonicalExeName(exeName) + "|"
+	secs := 0
+	for key, stat := range r.appsDaily {
+		if strings.HasPrefix(key, prefix) {
+			secs += stat.TotalDurationSecs
+		}
+	}
+	return secs / 60
+}
+
+func (r *memoryRepository) GetAllSessions() ([]storage.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sessions := make([]storage.Session, len(r.sessions))
+	copy(sessions, r.sessions)
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].StartTime.After(sessions[j].StartTime)
+	})
+	return sessions, nil
+}
+
+func (r *memoryRepository) GetSessionsPaginated(limit, offset int, startDate, endDate string) ([]storage.Session, int, error) {
+	all, _ := r.GetAllSessions()
+	var filtered []storage.Session
+	for _, s := range all {
+		if startDate != "" && s.Date < startDate {
+			continue
+		}
+		if endDate != "" && s.Date > endDate {
+			continue
+		}
+		filtered = append(filtered, s)
+	}
+	total := len(filtered)
+	if offset >= total {
+		return nil, total, nil
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return filtered[offset:end], total, nil
+}
+
+func (r *memoryRepository) GetAllAppStats() ([]storage.AppDailyStat, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return filterStats(r.appsDaily, ""), nil
+}
+
+func (r *memoryRepository) GetAllBrowserStats() ([]storage.AppDailyStat, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return filterStats(r.browserDaily, ""), nil
+}
+
+func (r *memoryRepository) UpdateBrowserDaily(date, domainOrTitle string, durationSecs int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := date + "|" + domainOrTitle
+	stat, ok := r.browserDaily[key]
+	if !ok {
+		stat = &storage.AppDailyStat{Date: date, AppName: domainOrTitle}
+		r.browserDaily[key] = stat
+	}
+	stat.TotalDurationSecs += durationSecs
+	stat.OpenCount++
+	return nil
+}
+
+func (r *memoryRepository) GetBrowserStatsForDate(date string) ([]storage.AppDailyStat, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return filterStats(r.browserDaily, date), nil
+}
+
+func (r *memoryRepository) SaveActiveSession(s *storage.ActiveSessionRecord) error {
+	if s == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	record := *s
+	r.active = &record
+	return nil
+}
+
+func (r *memoryRepository) ClearActiveSession() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.active = nil
+	return nil
+}
+
+func (r *memoryRepository) RecoverActiveSession() ([]*storage.Session, error) {
+	r.mu.Lock()
+	s := r.active
+	r.active = nil
+	r.mu.Unlock()
+
+	if s == nil {
+		return nil, nil
+	}
+
+	duration := int(s.LastSeen.Sub(s.StartTime).Seconds())
+	if duration < 1 {
+		return nil, nil
+	}
+
+	return storage.SplitSessionByDay(&storage.Session{
+		AppName:      s.AppName,
+		ExeName:      s.ExeName,
+		WindowTitle:  s.WindowTitle,
+		StartTime:    s.StartTime,
+		EndTime:      s.LastSeen,
+		DurationSecs: duration,
+		Date:         s.Date,
+	}), nil
+}
+
+func (r *memoryRepository) GetConfig(key string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	value, ok := r.config[key]
+	if !ok {
+		return "", errConfigNotFound
+	}
+	return value, nil
+}
+
+func (r *memoryRepository) SetConfig(key, value string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.config[key] = value
+	return nil
+}
+
+func (r *memoryRepository) IsConsentGranted() bool {
+	value, _ := r.GetConfig(storage.ConfigKeyConsent)
+	return value == "true"
+}
+
+func (r *memoryRepository) SetConsent(granted bool) error {
+	if granted {
+		return r.SetConfig(storage.ConfigKeyConsent, "true")
+	}
+	return r.SetConfig(storage.ConfigKeyConsent, "false")
+}
+
+func (r *memoryRepository) IsPaused() bool {
+	value, _ := r.GetConfig(storage.ConfigKeyPaused)
+	return value == "true"
+}
+
+func (r *memoryRepository) SetPaused(paused bool) error {
+	if paused {
+		return r.SetConfig(storage.ConfigKeyPaused, "true")
+	}
+	return r.SetConfig(storage.ConfigKeyPaused, "false")
+}
+
+func (r *memoryRepository) EnforceRetention() error {
+	return nil
+}
+
+func (r *memoryRepository) IsBrowser(exeName string) bool {
+	return testBrowsers[system.CanonicalExeName(exeName)]
+}
+
+func (r *memoryRepository) Close() error {
+	return nil
+}
+
+var _ storage.Repository = (*memoryRepository)(nil)
+
+func filterStats(stats map[string]*storage.AppDailyStat, date string) []storage.AppDailyStat {
+	var result []storage.AppDailyStat
+	for _, s := range stats {
+		if date == "" || s.Date == date {
+			result = append(result, *s)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Date != result[j].Date {
+			return result[i].Date > result[j].Date
+		}
+		return result[i].TotalDurationSecs > result[j].TotalDurationSecs
+	})
+	return result
+}
+
+type fakeIdleSource struct {
+	mu        sync.Mutex
+	lastInput time.Time
+}
+
+func newFakeIdleSource(lastInput time.Time) *fakeIdleSource {
+	return &fakeIdleSource{lastInput: lastInput}
+}
+
+func (s *fakeIdleSource) LastInputTime() (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastInput, nil
+}
+
+func (s *fakeIdleSource) SetLastInput(t time.Time) {
+	s.mu.Lock()
+	s.lastInput = t
+	s.mu.Unlock()
+}
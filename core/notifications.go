@@ -1,10 +1,9 @@
 package core
 
 import (
+	"focusd/system"
 	"sync"
-	"syscall"
 	"time"
-	"unsafe"
 )
 
 var (
@@ -14,29 +13,13 @@ var (
 	isNotificationVisible bool
 )
 
-var (
-	user32          = syscall.NewLazyDLL("user32.dll")
-	procMessageBoxW = user32.NewProc("MessageBoxW")
-)
-
-const (
-	MB_OK              = 0x00000000
-	MB_OKCANCEL        = 0x00000001
-	MB_YESNO           = 0x00000004
-	MB_ICONINFORMATION = 0x00000040
-	MB_ICONWARNING     = 0x00000030
-	MB_SYSTEMMODAL     = 0x00001000
-	MB_SETFOREGROUND   = 0x00010000
-	IDOK               = 1
-	IDYES              = 6
-	IDNO               = 7
-)
-
-func ShowNotification(title, message string) {
+// ShowNotification reports whether the notification was shown; it is dropped
+// during the cooldown or while another one is still open.
+func ShowNotification(title, message string) bool {
 	notificationMutex.Lock()
 	if time.Since(lastNotificationTime) < notificationCooldown || isNotificationVisible {
 		notificationMutex.Unlock()
-		return
+		return false
 	}
 	lastNotificationTime = time.Now()
 	isNotificationVisible = true
@@ -49,22 +32,16 @@ func ShowNotification(title, message string) {
 			notificationMutex.Unlock()
 		}()
 
-		titlePtr, _ := syscall.UTF16PtrFromString(title)
-		messagePtr, _ := syscall.UTF16PtrFromString(message)
-		procMessageBoxW.Call(
-			0,
-			uintptr(unsafe.Pointer(messagePtr)),
-			uintptr(unsafe.Pointer(titlePtr)),
-			uintptr(MB_OK|MB_ICONINFORMATION|MB_SETFOREGROUND),
-		)
+		system.GetPlatform().Notify(title, message)
 	}()
+	return true
 }
 
-func ShowNotificationWithAction(title, message string, callback func(disable bool)) {
+func ShowNotificationWithAction(title, message string, callback func(disable bool)) bool {
 	notificationMutex.Lock()
 	if time.Since(lastNotificationTime) < notificationCooldown || isNotificationVisible {
 		notificationMutex.Unlock()
-		return
+		return false
 	}
 	lastNotificationTime = time.Now()
 	isNotificationVisible = true
@@ -77,17 +54,29 @@ func ShowNotificationWithAction(title, message string, callback func(disable boo
 			notificationMutex.Unlock()
 		}()
 
-		titlePtr, _ := syscall.UTF16PtrFromString(title)
-		fullMessage := message + "\n\n[OK] Disable this reminder\n[Cancel] Just close"
-		messagePtr, _ := syscall.UTF16PtrFromString(fullMessage)
-		ret, _, _ := procMessageBoxW.Call(
-			0,
-			uintptr(unsafe.Pointer(messagePtr)),
-			uintptr(unsafe.Pointer(titlePtr)),
-			uintptr(MB_OKCANCEL|MB_ICONWARNING|MB_SETFOREGROUND),
-		)
+		disable := system.GetPlatform().Confirm(title, message)
 		if callback != nil {
-			callback(ret == IDOK)
+			callback(disable)
 		}
 	}()
+	return true
+}
+
+type Notifier interface {
+	Notify(title, message string) bool
+	NotifyWithAction(title, message string, callback func(disable bool)) bool
+}
+
+type desktopNotifier struct{}
+
+func NewDesktopNotifier() Notifier {
+	return desktopNotifier{}
+}
+
+func (desktopNotifier) Notify(title, message string) bool {
+	return ShowNotification(title, message)
+}
+
+func (desktopNotifier) NotifyWithAction(title, message string, callback func(disable bool)) bool {
+	return ShowNotificationWithAction(title, message, callback)
 }
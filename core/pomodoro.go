@@ -2,6 +2,7 @@ package core
 
 import (
 	"encoding/json"
+	"focusd/storage"
 	"os"
 	"path/filepath"
 	"time"
@@ -15,11 +16,11 @@ type PomodoroState struct {
 }
 
 func getPomodoroPath() string {
-	appData := os.Getenv("APPDATA")
-	if appData == "" {
+	dataDir, err := storage.GetDataDir()
+	if err != nil {
 		return ""
 	}
-	return filepath.Join(appData, "focusd", "pomodoro.json")
+	return filepath.Join(dataDir, "pomodoro.json")
 }
 
 func loadPomodoroStateFresh() *PomodoroState {
@@ -118,6 +119,10 @@ func IsPomodoroComplete() bool {
 }
 
 func CheckPomodoroAndNotify() {
+	checkPomodoro(time.Now(), NewDesktopNotifier())
+}
+
+func checkPomodoro(now time.Time, notifier Notifier) {
 	state := loadPomodoroStateFresh()
 	if !state.Active {
 		return
@@ -127,11 +132,11 @@ func CheckPomodoroAndNotify() {
 		return
 	}
 
-	elapsed := time.Since(state.StartTime)
+	elapsed := now.Sub(state.StartTime)
 	totalDuration := time.Duration(state.Duration) * time.Minute
 
 	if elapsed >= totalDuration {
-		ShowNotification("Pomodoro Complete!", "Great work! Take a break.")
+		notifier.Notify("Pomodoro Complete!", "Great work! Take a break.")
 		state.Notified = true
 		state.Active = false
 		savePomodoroState(state)
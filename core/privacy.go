@@ -0,0 +1,129 @@
+package core
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"focusd/storage"
+	"focusd/system"
+	"regexp"
+	"sync"
+)
+
+const (
+	titleHashKeyName = "title-hash"
+	titleHashPrefix  = "#"
+	redactedText     = "[redacted]"
+)
+
+var (
+	titleHashMu  sync.Mutex
+	titleHashKey []byte
+
+	titleHashPattern = regexp.MustCompile(`^#[0-9a-f]{16}$`)
+)
+
+type titlePolicy struct {
+	mode      string
+	patterns  []*regexp.Regexp
+	siteGroup func(title string) string
+	key       func() ([]byte, error)
+}
+
+func RedactTitle(title string) string {
+	return redactTitle(title, system.GetTitleRedactionPatterns())
+}
+
+func redactTitle(title string, patterns []*regexp.Regexp) string {
+	for _, re := range patterns {
+		title = re.ReplaceAllString(title, redactedText)
+	}
+	return title
+}
+
+func ApplyTitlePrivacy(rawTitle, exeName string, isBrowser bool) string {
+	return titlePolicy{
+		mode:      system.GetTitlePrivacyMode(),
+		patterns:  system.GetTitleRedactionPatterns(),
+		siteGroup: ExtractAppCategory,
+		key:       storedTitleHashKey,
+	}.apply(rawTitle, exeName, isBrowser)
+}
+
+// PrepareImportedSession stores an imported session the way the tracker would
+// have: canonical exe name, and the title and site key both put through the
+// current privacy mode. Titles that are already hashes are not hashed again.
+func PrepareImportedSession(s *storage.Session) {
+	s.ExeName = system.CanonicalExeName(s.ExeName)
+	s.Site = ""
+	if s.ExeName == IdleExeName {
+		return
+	}
+
+	isBrowser := storage.IsBrowser(s.ExeName)
+	if !isTitleHash(s.WindowTitle) || system.GetTitlePrivacyMode() == system.TitleModeNone {
+		s.WindowTitle = ApplyTitlePrivacy(s.WindowTitle, s.ExeName, isBrowser)
+	}
+	if isBrowser {
+		s.Site = storedSite(s.WindowTitle, s.ExeName)
+	}
+}
+
+func (p titlePolicy) apply(rawTitle, exeName string, isBrowser bool) string {
+	if p.mode == system.TitleModeNone || rawTitle == "" {
+		return ""
+	}
+
+	title := redactTitle(rawTitle, p.patterns)
+	if p.mode == system.TitleModeFull {
+		return title
+	}
+
+	clean := CleanWindowTitle(title, exeName)
+	if isBrowser {
+		if category := p.siteGroup(clean); category != "" {
+			clean = category
+		}
+	}
+	if p.mode == system.TitleModeRedacted {
+		return clean
+	}
+
+	key, err := p.key()
+	if err != nil {
+		return ""
+	}
+	return hashTitle(clean, key)
+}
+
+func storedTitleHashKey() ([]byte, error) {
+	titleHashMu.Lock()
+	defer titleHashMu.Unlock()
+	if titleHashKey == nil {
+		key, err := storage.GetOrCreateKey(titleHashKeyName, 32)
+		if err != nil {
+			return nil, err
+		}
+		titleHashKey = key
+	}
+	return titleHashKey, nil
+}
+
+// storedSite derives the site key from a title that has already been through
+// the privacy mode, so no site total is keyed on the raw title.
+func storedSite(storedTitle, exeName string) string {
+	if storedTitle == "" {
+		return ""
+	}
+	return CleanWindowTitle(storedTitle, exeName)
+}
+
+func isTitleHash(title string) bool {
+	return titleHashPattern.MatchString(title)
+}
+
+func hashTitle(title string, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(title))
+	return titleHashPrefix + hex.EncodeToString(mac.Sum(nil))[:16]
+}
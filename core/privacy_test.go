@@ -0,0 +1,78 @@
+package core
+
+import (
+	"focusd/storage"
+	"focusd/system"
+	"strings"
+	"testing"
+)
+
+func TestPrepareImportedSession(t *testing.T) {
+	t.Cleanup(func() { system.ReloadUserConfig() })
+	isolateDataDir(t)
+	if err := system.ReloadUserConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		storage.Close()
+		titleHashMu.Lock()
+		titleHashKey = nil
+		titleHashMu.Unlock()
+	})
+	if err := system.SetTitlePrivacyMode(system.TitleModeHashed); err != nil {
+		t.Fatal(err)
+	}
+
+	browser := &storage.Session{ExeName: "Chrome", WindowTitle: "Cats - YouTube - Google Chrome"}
+	PrepareImportedSession(browser)
+	if browser.ExeName != system.CanonicalExeName("chrome") {
+		t.Errorf("exe = %q, want the canonical name", browser.ExeName)
+	}
+	if !isTitleHash(browser.WindowTitle) {
+		t.Errorf("title = %q, want it hashed", browser.WindowTitle)
+	}
+	if browser.Site != browser.WindowTitle {
+		t.Errorf("site = %q, want the hashed title %q", browser.Site, browser.WindowTitle)
+	}
+
+	stored := browser.WindowTitle
+	again := &storage.Session{ExeName: "chrome", WindowTitle: stored}
+	PrepareImportedSession(again)
+	if again.WindowTitle != stored || again.Site != stored {
+		t.Errorf("re-imported hash = %q, site %q; want %q kept", again.WindowTitle, again.Site, stored)
+	}
+
+	lookalike := &storage.Session{ExeName: "chrome", WindowTitle: "#1 Cats - YouTube - Google Chrome"}
+	PrepareImportedSession(lookalike)
+	if !isTitleHash(lookalike.WindowTitle) || strings.Contains(lookalike.Site, "Cats") {
+		t.Errorf("title starting with # = %q, site %q; want it hashed like any other", lookalike.WindowTitle, lookalike.Site)
+	}
+
+	if err := system.SetTitlePrivacyMode(system.TitleModeNone); err != nil {
+		t.Fatal(err)
+	}
+	again = &storage.Session{ExeName: "chrome", WindowTitle: stored}
+	PrepareImportedSession(again)
+	if again.WindowTitle != "" || again.Site != "" {
+		t.Errorf("none mode kept title %q, site %q; want both dropped", again.WindowTitle, again.Site)
+	}
+}
+
+func TestIsTitleHash(t *testing.T) {
+	tests := map[string]bool{
+		hashTitle("Cats", []byte("key")): true,
+		"#0123456789abcdef":              true,
+		"#0123456789ABCDEF":              false,
+		"#0123456789abcde":               false,
+		"#1 Hit Songs":                   false,
+		"":                               false,
+	}
+	for title, want := range tests {
+		if got := isTitleHash(title); got != want {
+			t.Errorf("isTitleHash(%q) = %v, want %v", title, got, want)
+		}
+	}
+}
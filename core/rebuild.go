@@ -0,0 +1,67 @@
+package core
+
+import (
+	"focusd/storage"
+)
+
+func RebuildAggregates(dates []string) error {
+	sessions, err := storage.GetSessionsForDates(dates)
+	if err != nil {
+		return err
+	}
+
+	apps, sites := aggregateSessions(sessions)
+	return storage.ReplaceDailyAggregates(dates, apps, sites)
+}
+
+// ImportSessions stores imported sessions and adds them to the existing
+// totals, which may hold days whose sessions were already pruned.
+func ImportSessions(sessions []*storage.Session) error {
+	return storage.ImportSessions(sessions, siteForSession)
+}
+
+func aggregateSessions(sessions []storage.Session) ([]storage.AppDailyStat, []storage.AppDailyStat) {
+	appIndex := make(map[string]int)
+	siteIndex := make(map[string]int)
+	var apps, sites []storage.AppDailyStat
+
+	for _, s := range sessions {
+		if s.ExeName == IdleExeName {
+			continue
+		}
+
+		key := s.Date + "|" + s.ExeName + "|" + s.AppName
+		if i, ok := appIndex[key]; ok {
+			apps[i].TotalDurationSecs += s.DurationSecs
+			apps[i].OpenCount++
+		} else {
+			appIndex[key] = len(apps)
+			apps = append(apps, storage.AppDailyStat{
+				Date:              s.Date,
+				AppName:           s.AppName,
+				ExeName:           s.ExeName,
+				TotalDurationSecs: s.DurationSecs,
+				OpenCount:         1,
+			})
+		}
+
+		title, ok := siteForSession(s)
+		if !ok {
+			continue
+		}
+		key = s.Date + "|" + title
+		if i, ok := siteIndex[key]; ok {
+			sites[i].TotalDurationSecs += s.DurationSecs
+			sites[i].OpenCount++
+		} else {
+			siteIndex[key] = len(sites)
+			sites = append(sites, storage.AppDailyStat{
+				Date:              s.Date,
+				AppName:           title,
+				TotalDurationSecs: s.DurationSecs,
+				OpenCount:         1,
+			})
+		}
+	}
+	return apps, sites
+}
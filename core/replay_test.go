@@ -0,0 +1,154 @@
+package core
+
+import (
+	"focusd/storage"
+	"focusd/system"
+	"sort"
+	"sync"
+	"time"
+)
+
+type scriptedWindowSource struct {
+	mu      sync.Mutex
+	current *system.WindowInfo
+	err     error
+}
+
+func newScriptedWindowSource() *scriptedWindowSource {
+	return &scriptedWindowSource{}
+}
+
+func (s *scriptedWindowSource) ForegroundWindow() (*system.WindowInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.current == nil {
+		return nil, nil
+	}
+	info := *s.current
+	return &info, nil
+}
+
+func (s *scriptedWindowSource) Set(info *system.WindowInfo) {
+	s.mu.Lock()
+	s.current = info
+	s.mu.Unlock()
+}
+
+func (s *scriptedWindowSource) SetError(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+type Notification struct {
+	Time    time.Time
+	Title   string
+	Message string
+}
+
+type recordingNotifier struct {
+	mu            sync.Mutex
+	clock         Clock
+	notifications []Notification
+}
+
+func newRecordingNotifier(clock Clock) *recordingNotifier {
+	return &recordingNotifier{clock: clock}
+}
+
+func (n *recordingNotifier) Notify(title, message string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.notifications = append(n.notifications, Notification{Time: n.clock.Now(), Title: title, Message: message})
+	return true
+}
+
+func (n *recordingNotifier) NotifyWithAction(title, message string, callback func(disable bool)) bool {
+	return n.Notify(title, message)
+}
+
+func (n *recordingNotifier) Notifications() []Notification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	result := make([]Notification, len(n.notifications))
+	copy(result, n.notifications)
+	return result
+}
+
+type replayStep struct {
+	At     time.Duration
+	Window *system.WindowInfo
+	Idle   bool
+}
+
+type replayResult struct {
+	Sessions      []storage.Session
+	AppStats      []storage.AppDailyStat
+	BrowserStats  []storage.AppDailyStat
+	Notifications []Notification
+}
+
+func replay(cfg TrackerConfig, start time.Time, steps []replayStep, duration time.Duration) *replayResult {
+	clock := newFakeClock(start)
+	windows := newScriptedWindowSource()
+	notifier := newRecordingNotifier(clock)
+	repo := newMemoryRepository(clock.Now)
+	idle := newFakeIdleSource(start)
+
+	t := NewTrackerWithOptions(TrackerOptions{
+		Clock:      clock,
+		Windows:    windows,
+		Notifier:   notifier,
+		Repo:       repo,
+		IdleSource: idle,
+		Config:     cfg,
+	})
+
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].At < steps[j].At
+	})
+
+	clock.Set(start.Add(-t.pollInterval))
+	stopped := make(chan struct{})
+	go func() {
+		t.Start()
+		close(stopped)
+	}()
+	t.waitLoop()
+
+	next := 0
+	userIdle := false
+	for elapsed := time.Duration(0); elapsed <= duration; elapsed += t.pollInterval {
+		for next < len(steps) && steps[next].At <= elapsed {
+			windows.Set(steps[next].Window)
+			userIdle = steps[next].Idle
+			next++
+		}
+		if !userIdle {
+			idle.SetLastInput(start.Add(elapsed))
+		}
+
+		clock.Advance(t.pollInterval)
+		t.waitLoop()
+	}
+
+	t.Stop()
+	<-stopped
+
+	sessions, _ := repo.GetAllSessions()
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].StartTime.Before(sessions[j].StartTime)
+	})
+	apps, _ := repo.GetAllAppStats()
+	sites, _ := repo.GetAllBrowserStats()
+
+	return &replayResult{
+		Sessions:      sessions,
+		AppStats:      apps,
+		BrowserStats:  sites,
+		Notifications: notifier.Notifications(),
+	}
+}
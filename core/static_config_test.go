@@ -0,0 +1,133 @@
+package core
+
+import (
+	"focusd/storage"
+	"focusd/system"
+	"regexp"
+	"sync"
+	"time"
+)
+
+var (
+	builtinGroupRules = sync.OnceValue(func() []EffectiveGroupRule {
+		rules, _ := buildGroupRules(nil)
+		return rules
+	})
+	builtinCategorySet = sync.OnceValue(func() *categorySet {
+		set, _ := buildCategories(nil)
+		return set
+	})
+)
+
+type staticConfig struct {
+	IdleThresholdMinutes int
+	RecordIdle           bool
+	BreakReminderMinutes int
+	SnoozeMinutes        int
+	Limits               []system.TimeLimit
+	WarningPercent       int
+	WarningMinutes       int
+	WhitelistApps        []string
+	WhitelistSites       []string
+	PrivateWindows       string
+	AppNames             map[string]string
+	AppRules             []AppRule
+	TitleMode            string
+	RedactionPatterns    []*regexp.Regexp
+	HashKey              []byte
+}
+
+func (c *staticConfig) IdleThreshold() time.Duration {
+	if c.IdleThresholdMinutes < 1 {
+		return 5 * time.Minute
+	}
+	return time.Duration(c.IdleThresholdMinutes) * time.Minute
+}
+
+func (c *staticConfig) RecordIdleSessions() bool {
+	return c.RecordIdle
+}
+
+func (c *staticConfig) BreakReminder() (bool, int) {
+	return c.BreakReminderMinutes > 0, c.BreakReminderMinutes
+}
+
+func (c *staticConfig) SnoozeDuration() time.Duration {
+	if c.SnoozeMinutes < 1 {
+		return time.Hour
+	}
+	return time.Duration(c.SnoozeMinutes) * time.Minute
+}
+
+func (c *staticConfig) TimeLimits() []system.TimeLimit {
+	return c.Limits
+}
+
+func (c *staticConfig) LimitWarnings() (int, int) {
+	return c.WarningPercent, c.WarningMinutes
+}
+
+func (c *staticConfig) IsWhitelisted(exeName string) bool {
+	exeName = system.CanonicalExeName(exeName)
+	for _, a := range c.WhitelistApps {
+		if system.CanonicalExeName(a) == exeName {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *staticConfig) IsSiteWhitelisted(title, exeName string) bool {
+	return isSiteWhitelisted(title, exeName, c.WhitelistSites, c.SiteGroup)
+}
+
+func (c *staticConfig) PrivateWindowMode(exeName string) string {
+	if c.PrivateWindows == "" {
+		return storage.PrivateWindowsLabel
+	}
+	return c.PrivateWindows
+}
+
+func (c *staticConfig) ResolveApp(exeName, exePath, title string) AppIdentity {
+	exe := system.CanonicalExeName(exeName)
+	if name, ok := c.AppNames[exe]; ok {
+		return AppIdentity{ExeName: exe, AppName: name}
+	}
+	rules, _ := compileAppRules(&AppRulesFile{Rules: c.AppRules})
+	return resolveApp(rules, exeName, exePath, title)
+}
+
+func (c *staticConfig) SiteGroup(title string) string {
+	return extractAppCategory(title, builtinGroupRules())
+}
+
+func (c *staticConfig) AppCategory(exeName, appName string, isBrowser bool) string {
+	return builtinCategorySet().appCategory(exeName, appName, isBrowser)
+}
+
+func (c *staticConfig) SiteCategory(title string) string {
+	return builtinCategorySet().siteCategory(c.SiteGroup(title), title)
+}
+
+func (c *staticConfig) TitlePrivacy(rawTitle, exeName string, isBrowser bool) string {
+	mode := c.TitleMode
+	if mode == "" {
+		mode = system.TitleModeFull
+	}
+	return titlePolicy{
+		mode:      mode,
+		patterns:  c.RedactionPatterns,
+		siteGroup: c.SiteGroup,
+		key: func() ([]byte, error) {
+			return c.HashKey, nil
+		},
+	}.apply(rawTitle, exeName, isBrowser)
+}
+
+func (c *staticConfig) Check() error {
+	return nil
+}
+
+func (c *staticConfig) Reload() error {
+	return nil
+}
@@ -13,29 +13,95 @@ import (
 	"time"
 )
 
+const (
+	IdleAppName = "Idle"
+	IdleExeName = "(idle)"
+)
+
 type ActiveSession struct {
-	AppName     string
-	ExeName     string
-	WindowTitle string
-	StartTime   time.Time
-	Date        string
+	AppName     string    `json:"app_name"`
+	ExeName     string    `json:"exe_name"`
+	WindowTitle string    `json:"window_title"`
+	StartTime   time.Time `json:"start_time"`
+	Date        string    `json:"date"`
 }
 
 type Tracker struct {
-	mu              sync.Mutex
-	currentSession  *ActiveSession
-	pollInterval    time.Duration
-	batchInterval   time.Duration
-	pendingSessions []*storage.Session
-	ctx             context.Context
-	cancel          context.CancelFunc
+	mu                sync.Mutex
+	currentSession    *ActiveSession
+	pollInterval      time.Duration
+	batchInterval     time.Duration
+	titleDebounce     time.Duration
+	pendingTitle      string
+	pendingTitleSince time.Time
+	pendingSessions   []*storage.Session
+	clock             Clock
+	windows           WindowSource
+	notifier          Notifier
+	repo              storage.Repository
+	idleSource        system.IdleSource
+	idleErr           error
+	windowErr         error
+	config            TrackerConfig
+	loopSync          chan chan struct{}
+	idle              bool
+	paused            bool
+	ctx               context.Context
+	cancel            context.CancelFunc
+}
+
+type WindowSource interface {
+	ForegroundWindow() (*system.WindowInfo, error)
+}
+
+type TrackerOptions struct {
+	Clock      Clock
+	Windows    WindowSource
+	Notifier   Notifier
+	Repo       storage.Repository
+	IdleSource system.IdleSource
+	Config     TrackerConfig
 }
 
 func NewTracker() *Tracker {
+	return NewTrackerWithOptions(TrackerOptions{})
+}
+
+func NewTrackerWithOptions(opts TrackerOptions) *Tracker {
+	platform := system.GetPlatform()
+	if opts.Clock == nil {
+		opts.Clock = NewRealClock()
+	}
+	if opts.Windows == nil {
+		opts.Windows = platform
+	}
+	if opts.Notifier == nil {
+		opts.Notifier = NewDesktopNotifier()
+	}
+	if opts.Repo == nil {
+		opts.Repo = storage.GetRepository()
+	}
+	if opts.Config == nil {
+		opts.Config = NewSystemConfig()
+	}
+	var idleErr error
+	if opts.IdleSource == nil {
+		opts.IdleSource, idleErr = platform.NewIdleSource(opts.Clock.Now)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Tracker{
 		pollInterval:  1 * time.Second,
 		batchInterval: 10 * time.Second,
+		titleDebounce: 3 * time.Second,
+		clock:         opts.Clock,
+		windows:       opts.Windows,
+		notifier:      opts.Notifier,
+		repo:          opts.Repo,
+		idleSource:    opts.IdleSource,
+		idleErr:       idleErr,
+		config:        opts.Config,
+		loopSync:      make(chan chan struct{}),
 		ctx:           ctx,
 		cancel:        cancel,
 	}
@@ -45,133 +111,379 @@ func (t *Tracker) Start() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
+	defer signal.Stop(sigChan)
+
 	go func() {
-		<-sigChan
-		t.Stop()
+		select {
+		case <-sigChan:
+			t.Stop()
+		case <-t.ctx.Done():
+		}
 	}()
 
 	t.recoverOrphanedSession()
 
-	pollTicker := time.NewTicker(t.pollInterval)
-	batchTicker := time.NewTicker(t.batchInterval)
-	persistTicker := time.NewTicker(30 * time.Second)
-	retentionTicker := time.NewTicker(1 * time.Hour)
-	focusTicker := time.NewTicker(5 * time.Second)
+	if err := t.IdleError(); err != nil {
+		t.notifier.Notify("Idle Detection", err.Error())
+	}
+
+	t.syncPaused()
+
+	pollTicker := t.clock.NewTicker(t.pollInterval)
+	batchTicker := t.clock.NewTicker(t.batchInterval)
+	persistTicker := t.clock.NewTicker(30 * time.Second)
+	retentionTicker := t.clock.NewTicker(1 * time.Hour)
+	focusTicker := t.clock.NewTicker(5 * time.Second)
+	configTicker := t.clock.NewTicker(3 * time.Second)
 	defer pollTicker.Stop()
 	defer batchTicker.Stop()
 	defer persistTicker.Stop()
 	defer retentionTicker.Stop()
 	defer focusTicker.Stop()
+	defer configTicker.Stop()
 
-	storage.EnforceRetention()
+	t.repo.EnforceRetention()
 
 	var stateMu sync.Mutex
 	var continuousUseStart time.Time
 	var breakSnoozedUntil time.Time
-	prevSessionApp := ""
-	disabledLimitApps := make(map[string]time.Time)
-	appLimitDate := ""
+	alerts := newLimitAlerts(t.repo)
 
 	for {
 		select {
 		case <-t.ctx.Done():
 			t.flushCurrentSession()
 			t.flushPendingSessions()
-			storage.ClearActiveSession()
+			t.repo.ClearActiveSession()
 			return
-		case <-pollTicker.C:
-			if storage.IsPaused() {
+		case <-pollTicker.C():
+			if t.IsPaused() {
 				continuousUseStart = time.Time{}
 				continue
 			}
 			t.poll()
+			if t.IsIdle() {
+				continuousUseStart = time.Time{}
+				continue
+			}
 			if continuousUseStart.IsZero() {
-				continuousUseStart = time.Now()
+				continuousUseStart = t.clock.Now()
 			}
-		case <-batchTicker.C:
+		case <-batchTicker.C():
 			t.flushPendingSessions()
-		case <-persistTicker.C:
+		case <-persistTicker.C():
 			t.persistActiveSession()
-		case <-retentionTicker.C:
-			storage.EnforceRetention()
-		case <-focusTicker.C:
-			CheckPomodoroAndNotify()
-
-			today := storage.Today()
-			now := time.Now()
-			snoozeDuration := time.Duration(system.GetSnoozeDurationMinutes()) * time.Minute
+		case <-retentionTicker.C():
+			t.repo.EnforceRetention()
+		case done := <-t.loopSync:
+			close(done)
+		case <-configTicker.C():
+			t.syncPaused()
+			if err := t.config.Check(); err != nil {
+				t.notifier.Notify("Config Error", err.Error()+"\nKeeping previous settings.")
+			}
+		case <-focusTicker.C():
+			now := t.clock.Now()
+			checkPomodoro(now, t.notifier)
+			snoozeDuration := t.config.SnoozeDuration()
 
 			stateMu.Lock()
 			breakSnoozed := !breakSnoozedUntil.IsZero() && now.Before(breakSnoozedUntil)
 			stateMu.Unlock()
 
-			if system.GetBreakReminderEnabled() && !breakSnoozed && !continuousUseStart.IsZero() {
-				mins := system.GetBreakReminderMinutes()
-				if time.Since(continuousUseStart) >= time.Duration(mins)*time.Minute {
-					ShowNotificationWithAction("Break Reminder",
+			if enabled, mins := t.config.BreakReminder(); enabled && !breakSnoozed && !continuousUseStart.IsZero() {
+				if now.Sub(continuousUseStart) >= time.Duration(mins)*time.Minute {
+					t.notifier.NotifyWithAction("Break Reminder",
 						fmt.Sprintf("You've been working for %d min. Take a break!", mins),
 						func(disable bool) {
 							stateMu.Lock()
-							continuousUseStart = time.Now()
+							continuousUseStart = t.clock.Now()
 							if disable {
-								breakSnoozedUntil = time.Now().Add(snoozeDuration)
+								breakSnoozedUntil = t.clock.Now().Add(snoozeDuration)
 							}
 							stateMu.Unlock()
 						})
 				}
 			}
 
-			stateMu.Lock()
-			if appLimitDate != today {
-				disabledLimitApps = make(map[string]time.Time)
-				appLimitDate = today
+			t.checkLimits(alerts, now, snoozeDuration)
+		}
+	}
+}
+
+func (t *Tracker) checkLimits(alerts *limitAlerts, now time.Time, snoozeDuration time.Duration) {
+	today := now.Format("2006-01-02")
+	alerts.reset(today)
+
+	limits := t.config.TimeLimits()
+	percent, minutes := t.config.LimitWarnings()
+	session := t.CurrentSession()
+	for _, limit := range limits {
+		key := limit.Target.String()
+		if session == nil || session.ExeName == IdleExeName {
+			alerts.leave(key)
+			continue
+		}
+
+		siteTitle, isBrowser := t.limitSiteTitle(session.ExeName, session.WindowTitle)
+		if !limitAppliesTo(t.config, limit.Target, session.ExeName, session.AppName, siteTitle, isBrowser) {
+			alerts.leave(key)
+			continue
+		}
+
+		label := LimitLabel(limit.Target, session.AppName)
+		used := t.limitUsageSecs(limit.Target, today, now)
+		if used < limit.Minutes*60 {
+			if msg, fired := alerts.warning(key, label, used, limit.Minutes, percent, minutes); msg != "" {
+				if t.notifier.Notify("Time Limit Warning", msg) {
+					alerts.markFired(fired...)
+				}
 			}
-			stateMu.Unlock()
+			continue
+		}
 
-			limits := system.GetAppTimeLimits()
-			if len(limits) > 0 && t.currentSession != nil {
-				currentExe := strings.ToLower(t.currentSession.ExeName)
-				currentAppName := t.currentSession.AppName
-
-				if currentExe != prevSessionApp {
-					prevSessionApp = currentExe
-
-					stateMu.Lock()
-					appSnoozed := !disabledLimitApps[currentExe].IsZero() && now.Before(disabledLimitApps[currentExe])
-					stateMu.Unlock()
-
-					if limit, ok := limits[currentExe]; ok && !appSnoozed {
-						todayUsage := storage.GetAppUsageTodayMinutes(currentExe)
-						if todayUsage >= limit {
-							exeCopy := currentExe
-							ShowNotificationWithAction("App Time Limit",
-								currentAppName+" has exceeded daily limit!",
-								func(disable bool) {
-									if disable {
-										stateMu.Lock()
-										disabledLimitApps[exeCopy] = time.Now().Add(snoozeDuration)
-										stateMu.Unlock()
-									}
-								})
-						}
+		if alerts.exceededDue(key, now) {
+			if t.notifier.NotifyWithAction(LimitNotificationTitle(limit.Target),
+				label+" has exceeded daily limit!",
+				func(disable bool) {
+					if disable {
+						alerts.snooze(key, t.clock.Now().Add(snoozeDuration))
 					}
-				}
+				}) {
+				alerts.markExceeded(key)
 			}
 		}
 	}
 }
 
+func (t *Tracker) limitSiteTitle(exeName, title string) (string, bool) {
+	if !t.repo.IsBrowser(exeName) {
+		return "", false
+	}
+	return CleanWindowTitle(title, exeName), true
+}
+
+func (t *Tracker) limitUsageSecs(target system.LimitTarget, today string, now time.Time) int {
+	used := limitUsageSecs(t.config, t.repo, target, today)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, s := range t.pendingSessions {
+		if s.Date != today || s.ExeName == IdleExeName {
+			continue
+		}
+		siteTitle, isBrowser := t.limitSiteTitle(s.ExeName, s.WindowTitle)
+		if limitAppliesTo(t.config, target, s.ExeName, s.AppName, siteTitle, isBrowser) {
+			used += s.DurationSecs
+		}
+	}
+
+	if s := t.currentSession; s != nil && s.ExeName != IdleExeName {
+		siteTitle, isBrowser := t.limitSiteTitle(s.ExeName, s.WindowTitle)
+		if limitAppliesTo(t.config, target, s.ExeName, s.AppName, siteTitle, isBrowser) {
+			start := s.StartTime
+			if midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()); start.Before(midnight) {
+				start = midnight
+			}
+			if now.After(start) {
+				used += int(now.Sub(start).Seconds())
+			}
+		}
+	}
+	return used
+}
+
 func (t *Tracker) Stop() {
 	t.cancel()
 }
 
+func (t *Tracker) waitLoop() {
+	done := make(chan struct{})
+	select {
+	case t.loopSync <- done:
+		<-done
+	case <-t.ctx.Done():
+	}
+}
+
+func (t *Tracker) SetPaused(paused bool) error {
+	if err := t.repo.SetPaused(paused); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.applyPaused(paused)
+	return nil
+}
+
+func (t *Tracker) syncPaused() {
+	paused := t.repo.IsPaused()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if paused != t.paused {
+		t.applyPaused(paused)
+	}
+}
+
+func (t *Tracker) applyPaused(paused bool) {
+	t.paused = paused
+	if paused {
+		t.closeCurrentSession()
+		t.pendingTitle = ""
+	}
+}
+
+func (t *Tracker) IsPaused() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.paused
+}
+
+func (t *Tracker) DiscardSince(since time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var kept []*storage.Session
+	for _, s := range t.pendingSessions {
+		if !s.StartTime.Before(since) {
+			continue
+		}
+		if s.EndTime.After(since) {
+			s.EndTime = since
+			s.DurationSecs = int(since.Sub(s.StartTime).Seconds())
+		}
+		kept = append(kept, s)
+	}
+	t.pendingSessions = kept
+
+	if t.currentSession == nil {
+		return
+	}
+	if t.currentSession.StartTime.Before(since) {
+		restart := *t.currentSession
+		t.closeCurrentSessionAt(since)
+		t.currentSession = &restart
+	}
+	now := t.clock.Now()
+	t.currentSession.StartTime = now
+	t.currentSession.Date = now.Format("2006-01-02")
+	t.pendingTitle = ""
+}
+
+func (t *Tracker) ForgetApp(exeName string) {
+	variants := appNameVariants(exeName)
+	t.forget(func(exe, title string) bool {
+		return matchesApp(variants, exe)
+	})
+}
+
+func (t *Tracker) ForgetSite(name string) {
+	t.forget(func(exe, title string) bool {
+		return exe != IdleExeName && t.repo.IsBrowser(exe) && isSameSite(name, CleanWindowTitle(title, exe), t.config.SiteGroup)
+	})
+}
+
+func (t *Tracker) forget(match func(exe, title string) bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var kept []*storage.Session
+	for _, s := range t.pendingSessions {
+		if !match(s.ExeName, s.WindowTitle) {
+			kept = append(kept, s)
+		}
+	}
+	t.pendingSessions = kept
+
+	if t.currentSession != nil && match(t.currentSession.ExeName, t.currentSession.WindowTitle) {
+		t.currentSession = nil
+		t.pendingTitle = ""
+	}
+}
+
+func (t *Tracker) ReloadConfig() error {
+	return t.config.Reload()
+}
+
+func (t *Tracker) SetIdleSource(src system.IdleSource) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.idleSource = src
+	t.idleErr = nil
+}
+
+func (t *Tracker) IdleError() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.idleErr
+}
+
+// setWindowError notifies once when the window source starts failing, e.g.
+// when there is no X display, instead of dropping every poll silently.
+func (t *Tracker) setWindowError(err error) {
+	t.mu.Lock()
+	first := err != nil && t.windowErr == nil
+	t.windowErr = err
+	t.mu.Unlock()
+	if first {
+		t.notifier.Notify("Window Tracking", err.Error())
+	}
+}
+
+func (t *Tracker) WindowError() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.windowErr
+}
+
+func (t *Tracker) CurrentSession() *ActiveSession {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.currentSession == nil {
+		return nil
+	}
+	session := *t.currentSession
+	return &session
+}
+
+// CurrentSessionAsStored returns the current session with its title put
+// through the privacy mode, for anything that leaves the daemon.
+func (t *Tracker) CurrentSessionAsStored() *ActiveSession {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.currentSession == nil {
+		return nil
+	}
+	session := *t.currentSession
+	session.WindowTitle = t.privateTitle(session.WindowTitle, session.ExeName)
+	return &session
+}
+
+func (t *Tracker) Now() time.Time {
+	return t.clock.Now()
+}
+
+func (t *Tracker) IsIdle() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.idle
+}
+
 func (t *Tracker) recoverOrphanedSession() {
-	recovered, err := storage.RecoverActiveSession()
-	if err != nil || recovered == nil {
+	recovered, err := t.repo.RecoverActiveSession()
+	if err != nil {
 		return
 	}
-	storage.InsertSession(recovered)
-	storage.UpdateAppDaily(recovered.Date, recovered.AppName, recovered.ExeName, recovered.DurationSecs)
+	for _, s := range recovered {
+		t.repo.InsertSession(s)
+		if s.ExeName == IdleExeName {
+			continue
+		}
+		t.repo.UpdateAppDaily(s.Date, s.AppName, s.ExeName, s.DurationSecs)
+	}
 }
 
 func (t *Tracker) persistActiveSession() {
@@ -179,66 +491,157 @@ func (t *Tracker) persistActiveSession() {
 	defer t.mu.Unlock()
 
 	if t.currentSession == nil {
-		storage.ClearActiveSession()
+		t.repo.ClearActiveSession()
 		return
 	}
 
 	record := &storage.ActiveSessionRecord{
 		AppName:     t.currentSession.AppName,
 		ExeName:     t.currentSession.ExeName,
-		WindowTitle: t.currentSession.WindowTitle,
+		WindowTitle: t.privateTitle(t.currentSession.WindowTitle, t.currentSession.ExeName),
 		StartTime:   t.currentSession.StartTime,
-		LastSeen:    time.Now(),
+		LastSeen:    t.clock.Now(),
 		Date:        t.currentSession.Date,
 	}
-	storage.SaveActiveSession(record)
+	t.repo.SaveActiveSession(record)
 }
 
 func (t *Tracker) poll() {
-	info, err := system.GetForegroundWindowInfo()
+	if t.checkIdle() {
+		return
+	}
+
+	info, err := t.windows.ForegroundWindow()
+	t.setWindowError(err)
 	if err != nil || info == nil || info.Title == "" || info.ExeName == "" {
 		return
 	}
 
-	if system.IsWhitelisted(info.ExeName) {
+	if t.config.IsWhitelisted(info.ExeName) {
+		return
+	}
+	if t.repo.IsBrowser(info.ExeName) && IsPrivateWindow(info.Title) {
+		switch t.config.PrivateWindowMode(info.ExeName) {
+		case storage.PrivateWindowsIgnore:
+			return
+		case storage.PrivateWindowsLabel:
+			info.Title = PrivateWindowTitle(info.ExeName)
+		}
+	}
+	if t.repo.IsBrowser(info.ExeName) && t.config.IsSiteWhitelisted(info.Title, info.ExeName) {
 		return
 	}
 
-	appName := getAppName(info.ExeName)
+	app := t.config.ResolveApp(info.ExeName, info.ExePath, info.Title)
 
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
+	start := t.clock.Now()
 	if t.currentSession != nil {
-		if t.isSameSession(info.ExeName) {
-			return
+		if t.isSameSession(app) {
+			if !t.repo.IsBrowser(app.ExeName) || !t.titleChanged(info.Title) {
+				return
+			}
+			start = t.pendingTitleSince
+			t.closeCurrentSessionAt(start)
+		} else {
+			t.closeCurrentSession()
 		}
-		t.closeCurrentSession()
 	}
 
+	t.pendingTitle = ""
 	t.currentSession = &ActiveSession{
-		AppName:     appName,
-		ExeName:     info.ExeName,
+		AppName:     app.AppName,
+		ExeName:     app.ExeName,
 		WindowTitle: info.Title,
-		StartTime:   time.Now(),
-		Date:        storage.Today(),
+		StartTime:   start,
+		Date:        start.Format("2006-01-02"),
 	}
 }
 
-func (t *Tracker) isSameSession(exeName string) bool {
+func (t *Tracker) titleChanged(title string) bool {
+	current := CleanWindowTitle(t.currentSession.WindowTitle, t.currentSession.ExeName)
+	candidate := CleanWindowTitle(title, t.currentSession.ExeName)
+	if candidate == current {
+		t.pendingTitle = ""
+		return false
+	}
+
+	now := t.clock.Now()
+	if candidate != t.pendingTitle {
+		t.pendingTitle = candidate
+		t.pendingTitleSince = now
+	}
+	return now.Sub(t.pendingTitleSince) >= t.titleDebounce
+}
+
+func (t *Tracker) checkIdle() bool {
+	t.mu.Lock()
+	src := t.idleSource
+	t.mu.Unlock()
+	if src == nil {
+		return false
+	}
+
+	lastInput, err := src.LastInputTime()
+	if err != nil || lastInput.IsZero() {
+		return false
+	}
+
+	threshold := t.config.IdleThreshold()
+	isIdle := t.clock.Now().Sub(lastInput) >= threshold
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !isIdle {
+		if t.idle {
+			t.idle = false
+			if t.currentSession != nil && t.currentSession.ExeName == IdleExeName {
+				t.closeCurrentSessionAt(lastInput)
+			}
+		}
+		return false
+	}
+
+	if t.idle {
+		return true
+	}
+	t.idle = true
+
+	if t.currentSession != nil {
+		t.closeCurrentSessionAt(lastInput)
+	}
+
+	if t.config.RecordIdleSessions() {
+		t.currentSession = &ActiveSession{
+			AppName:   IdleAppName,
+			ExeName:   IdleExeName,
+			StartTime: lastInput,
+			Date:      lastInput.Format("2006-01-02"),
+		}
+	}
+	return true
+}
+
+func (t *Tracker) isSameSession(app AppIdentity) bool {
 	if t.currentSession == nil {
 		return false
 	}
-	return t.currentSession.ExeName == exeName
+	return t.currentSession.ExeName == app.ExeName && t.currentSession.AppName == app.AppName
 }
 
 func (t *Tracker) closeCurrentSession() {
+	t.closeCurrentSessionAt(t.clock.Now())
+}
+
+func (t *Tracker) closeCurrentSessionAt(end time.Time) {
 	if t.currentSession == nil {
 		return
 	}
 
-	now := time.Now()
-	duration := int(now.Sub(t.currentSession.StartTime).Seconds())
+	duration := int(end.Sub(t.currentSession.StartTime).Seconds())
 	if duration < 1 {
 		t.currentSession = nil
 		return
@@ -249,15 +652,22 @@ func (t *Tracker) closeCurrentSession() {
 		ExeName:      t.currentSession.ExeName,
 		WindowTitle:  t.currentSession.WindowTitle,
 		StartTime:    t.currentSession.StartTime,
-		EndTime:      now,
+		EndTime:      end,
 		DurationSecs: duration,
 		Date:         t.currentSession.Date,
 	}
 
-	t.pendingSessions = append(t.pendingSessions, session)
+	t.pendingSessions = append(t.pendingSessions, storage.SplitSessionByDay(session)...)
 	t.currentSession = nil
 }
 
+func (t *Tracker) privateTitle(rawTitle, exeName string) string {
+	if exeName == IdleExeName {
+		return rawTitle
+	}
+	return t.config.TitlePrivacy(rawTitle, exeName, t.repo.IsBrowser(exeName))
+}
+
 func (t *Tracker) flushCurrentSession() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -270,24 +680,34 @@ func (t *Tracker) flushPendingSessions() {
 	t.pendingSessions = nil
 	t.mu.Unlock()
 
+	// Pending sessions keep the raw title in memory so limits see the real
+	// site; the stored title and site key both go through the privacy mode.
 	for _, s := range sessions {
-		storage.InsertSession(s)
-		storage.UpdateAppDaily(s.Date, s.AppName, s.ExeName, s.DurationSecs)
+		s.WindowTitle = t.privateTitle(s.WindowTitle, s.ExeName)
+		if s.ExeName != IdleExeName && t.repo.IsBrowser(s.ExeName) {
+			s.Site = storedSite(s.WindowTitle, s.ExeName)
+		}
 
-		if IsBrowser(s.ExeName) {
-			cleanTitle := CleanWindowTitle(s.WindowTitle, s.ExeName)
-			storage.UpdateBrowserDaily(s.Date, cleanTitle, s.DurationSecs)
+		t.repo.InsertSession(s)
+		if s.ExeName == IdleExeName {
+			continue
+		}
+		t.repo.UpdateAppDaily(s.Date, s.AppName, s.ExeName, s.DurationSecs)
+
+		if s.Site != "" {
+			t.repo.UpdateBrowserDaily(s.Date, s.Site, s.DurationSecs)
 		}
 	}
 }
 
 func getAppName(exeName string) string {
-	name := strings.TrimSuffix(exeName, ".exe")
-	name = strings.TrimSuffix(name, ".EXE")
+	name := exeName
+	if strings.HasSuffix(strings.ToLower(name), ".exe") {
+		name = name[:len(name)-len(".exe")]
+	}
 
 	nameMap := map[string]string{
 		"code":            "VS Code",
-		"Code":            "VS Code",
 		"devenv":          "Visual Studio",
 		"idea64":          "IntelliJ IDEA",
 		"pycharm64":       "PyCharm",
@@ -298,24 +718,24 @@ func getAppName(exeName string) string {
 		"sublime_text":    "Sublime Text",
 		"atom":            "Atom",
 		"explorer":        "File Explorer",
-		"Discord":         "Discord",
-		"Spotify":         "Spotify",
+		"discord":         "Discord",
+		"spotify":         "Spotify",
 		"slack":           "Slack",
-		"Teams":           "Microsoft Teams",
-		"Zoom":            "Zoom",
-		"WINWORD":         "Microsoft Word",
-		"EXCEL":           "Microsoft Excel",
-		"POWERPNT":        "PowerPoint",
-		"OUTLOOK":         "Outlook",
-		"Terminal":        "Windows Terminal",
-		"WindowsTerminal": "Windows Terminal",
+		"teams":           "Microsoft Teams",
+		"zoom":            "Zoom",
+		"winword":         "Microsoft Word",
+		"excel":           "Microsoft Excel",
+		"powerpnt":        "PowerPoint",
+		"outlook":         "Outlook",
+		"terminal":        "Windows Terminal",
+		"windowsterminal": "Windows Terminal",
 		"cmd":             "Command Prompt",
 		"powershell":      "PowerShell",
 		"pwsh":            "PowerShell",
 		"wt":              "Windows Terminal",
 	}
 
-	if mapped, ok := nameMap[name]; ok {
+	if mapped, ok := nameMap[strings.ToLower(name)]; ok {
 		return mapped
 	}
 
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"focusd/storage"
+	"focusd/system"
+	"time"
+)
+
+type TrackerConfig interface {
+	IdleThreshold() time.Duration
+	RecordIdleSessions() bool
+	BreakReminder() (enabled bool, minutes int)
+	SnoozeDuration() time.Duration
+	TimeLimits() []system.TimeLimit
+	LimitWarnings() (percent, minutes int)
+	IsWhitelisted(exeName string) bool
+	IsSiteWhitelisted(title, exeName string) bool
+	PrivateWindowMode(exeName string) string
+	ResolveApp(exeName, exePath, title string) AppIdentity
+	SiteGroup(title string) string
+	AppCategory(exeName, appName string, isBrowser bool) string
+	SiteCategory(title string) string
+	TitlePrivacy(rawTitle, exeName string, isBrowser bool) string
+	Check() error
+	Reload() error
+}
+
+type systemConfig struct {
+	watcher *ConfigWatcher
+}
+
+func NewSystemConfig() TrackerConfig {
+	return &systemConfig{watcher: NewConfigWatcher()}
+}
+
+func (c *systemConfig) IdleThreshold() time.Duration {
+	return time.Duration(system.GetIdleThresholdMinutes()) * time.Minute
+}
+
+func (c *systemConfig) RecordIdleSessions() bool {
+	return system.GetRecordIdleSessions()
+}
+
+func (c *systemConfig) BreakReminder() (bool, int) {
+	return system.GetBreakReminderEnabled(), system.GetBreakReminderMinutes()
+}
+
+func (c *systemConfig) SnoozeDuration() time.Duration {
+	return time.Duration(system.GetSnoozeDurationMinutes()) * time.Minute
+}
+
+func (c *systemConfig) TimeLimits() []system.TimeLimit {
+	return system.GetTimeLimits()
+}
+
+func (c *systemConfig) LimitWarnings() (int, int) {
+	return system.GetLimitWarningPercent(), system.GetLimitWarningMinutes()
+}
+
+func (c *systemConfig) IsWhitelisted(exeName string) bool {
+	return system.IsWhitelisted(exeName)
+}
+
+func (c *systemConfig) IsSiteWhitelisted(title, exeName string) bool {
+	return IsSiteWhitelisted(title, exeName)
+}
+
+func (c *systemConfig) PrivateWindowMode(exeName string) string {
+	return storage.GetPrivateWindowMode(exeName)
+}
+
+func (c *systemConfig) ResolveApp(exeName, exePath, title string) AppIdentity {
+	return ResolveApp(exeName, exePath, title)
+}
+
+func (c *systemConfig) SiteGroup(title string) string {
+	return ExtractAppCategory(title)
+}
+
+func (c *systemConfig) AppCategory(exeName, appName string, isBrowser bool) string {
+	return getCategories().appCategory(exeName, appName, isBrowser)
+}
+
+func (c *systemConfig) SiteCategory(title string) string {
+	return CategoryForSite(title)
+}
+
+func (c *systemConfig) TitlePrivacy(rawTitle, exeName string, isBrowser bool) string {
+	return ApplyTitlePrivacy(rawTitle, exeName, isBrowser)
+}
+
+func (c *systemConfig) Check() error {
+	return c.watcher.Check()
+}
+
+func (c *systemConfig) Reload() error {
+	return c.watcher.Reload()
+}
@@ -0,0 +1,362 @@
+package core
+
+import (
+	"errors"
+	"focusd/storage"
+	"focusd/system"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func isolateDataDir(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("APPDATA", dir)
+}
+
+func window(exe, title string) *system.WindowInfo {
+	return &system.WindowInfo{ExeName: exe, Title: title}
+}
+
+type wantSession struct {
+	app   string
+	exe   string
+	title string
+	start time.Duration
+	end   time.Duration
+	date  string
+}
+
+func checkSessions(t *testing.T, base time.Time, got []storage.Session, want []wantSession) {
+	t.Helper()
+	if len(got) != len(want) {
+		for _, s := range got {
+			t.Logf("got %s %s %q %s-%s", s.AppName, s.ExeName, s.WindowTitle, s.StartTime.Format(time.TimeOnly), s.EndTime.Format(time.TimeOnly))
+		}
+		t.Fatalf("got %d sessions, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		s := got[i]
+		start, end := base.Add(w.start), base.Add(w.end)
+		if s.AppName != w.app || s.ExeName != system.CanonicalExeName(w.exe) || s.WindowTitle != w.title {
+			t.Errorf("session %d = %s/%s %q, want %s/%s %q", i, s.AppName, s.ExeName, s.WindowTitle, w.app, w.exe, w.title)
+		}
+		if !s.StartTime.Equal(start) || !s.EndTime.Equal(end) {
+			t.Errorf("session %d spans %s-%s, want %s-%s", i, s.StartTime.Format(time.TimeOnly), s.EndTime.Format(time.TimeOnly), start.Format(time.TimeOnly), end.Format(time.TimeOnly))
+		}
+		if s.DurationSecs != int((w.end - w.start).Seconds()) {
+			t.Errorf("session %d duration = %d, want %d", i, s.DurationSecs, int((w.end - w.start).Seconds()))
+		}
+		date := w.date
+		if date == "" {
+			date = base.Format("2006-01-02")
+		}
+		if s.Date != date {
+			t.Errorf("session %d date = %s, want %s", i, s.Date, date)
+		}
+	}
+}
+
+func TestReplaySessions(t *testing.T) {
+	isolateDataDir(t)
+	base := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		config   staticConfig
+		steps    []replayStep
+		duration time.Duration
+		want     []wantSession
+	}{
+		{
+			name:     "single app",
+			steps:    []replayStep{{At: 0, Window: window("code", "main.go - focusd")}},
+			duration: 10 * time.Second,
+			want: []wantSession{
+				{app: "VS Code", exe: "code", title: "main.go - focusd", start: 0, end: 10 * time.Second},
+			},
+		},
+		{
+			name: "app switch",
+			steps: []replayStep{
+				{At: 0, Window: window("code", "main.go - focusd")},
+				{At: 30 * time.Second, Window: window("slack", "general")},
+			},
+			duration: time.Minute,
+			want: []wantSession{
+				{app: "VS Code", exe: "code", title: "main.go - focusd", start: 0, end: 30 * time.Second},
+				{app: "Slack", exe: "slack", title: "general", start: 30 * time.Second, end: time.Minute},
+			},
+		},
+		{
+			name: "whitelisted app is not recorded",
+			config: staticConfig{
+				WhitelistApps: []string{"slack"},
+			},
+			steps: []replayStep{
+				{At: 0, Window: window("code", "main.go - focusd")},
+				{At: 20 * time.Second, Window: window("slack", "general")},
+			},
+			duration: 40 * time.Second,
+			want: []wantSession{
+				{app: "VS Code", exe: "code", title: "main.go - focusd", start: 0, end: 40 * time.Second},
+			},
+		},
+		{
+			name: "title flicker below debounce keeps one session",
+			steps: []replayStep{
+				{At: 0, Window: window("chrome", "Docs - Google Chrome")},
+				{At: 20 * time.Second, Window: window("chrome", "Loading - Google Chrome")},
+				{At: 22 * time.Second, Window: window("chrome", "Docs - Google Chrome")},
+			},
+			duration: 40 * time.Second,
+			want: []wantSession{
+				{app: "Chrome", exe: "chrome", title: "Docs - Google Chrome", start: 0, end: 40 * time.Second},
+			},
+		},
+		{
+			name: "stable title change splits at first sight",
+			steps: []replayStep{
+				{At: 0, Window: window("chrome", "Docs - Google Chrome")},
+				{At: 40 * time.Second, Window: window("chrome", "Cats - YouTube - Google Chrome")},
+			},
+			duration: time.Minute,
+			want: []wantSession{
+				{app: "Chrome", exe: "chrome", title: "Docs - Google Chrome", start: 0, end: 40 * time.Second},
+				{app: "Chrome", exe: "chrome", title: "Cats - YouTube - Google Chrome", start: 40 * time.Second, end: time.Minute},
+			},
+		},
+		{
+			name:   "idle gap is left out",
+			config: staticConfig{IdleThresholdMinutes: 1},
+			steps: []replayStep{
+				{At: 0, Window: window("code", "main.go - focusd")},
+				{At: time.Minute, Window: window("code", "main.go - focusd"), Idle: true},
+				{At: 3 * time.Minute, Window: window("code", "main.go - focusd")},
+			},
+			duration: 4 * time.Minute,
+			want: []wantSession{
+				{app: "VS Code", exe: "code", title: "main.go - focusd", start: 0, end: 59 * time.Second},
+				{app: "VS Code", exe: "code", title: "main.go - focusd", start: 3 * time.Minute, end: 4 * time.Minute},
+			},
+		},
+		{
+			name:   "idle gap is recorded as an idle session",
+			config: staticConfig{IdleThresholdMinutes: 1, RecordIdle: true},
+			steps: []replayStep{
+				{At: 0, Window: window("code", "main.go - focusd")},
+				{At: time.Minute, Window: window("code", "main.go - focusd"), Idle: true},
+				{At: 3 * time.Minute, Window: window("code", "main.go - focusd")},
+			},
+			duration: 4 * time.Minute,
+			want: []wantSession{
+				{app: "VS Code", exe: "code", title: "main.go - focusd", start: 0, end: 59 * time.Second},
+				{app: IdleAppName, exe: IdleExeName, start: 59 * time.Second, end: 3 * time.Minute},
+				{app: "VS Code", exe: "code", title: "main.go - focusd", start: 3 * time.Minute, end: 4 * time.Minute},
+			},
+		},
+		{
+			name:     "title privacy none stores no title",
+			config:   staticConfig{TitleMode: system.TitleModeNone},
+			steps:    []replayStep{{At: 0, Window: window("code", "secret.txt - focusd")}},
+			duration: 10 * time.Second,
+			want: []wantSession{
+				{app: "VS Code", exe: "code", start: 0, end: 10 * time.Second},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.config
+			result := replay(&cfg, base, tt.steps, tt.duration)
+			checkSessions(t, base, result.Sessions, tt.want)
+		})
+	}
+}
+
+func TestReplaySplitsAtMidnight(t *testing.T) {
+	isolateDataDir(t)
+	base := time.Date(2026, 3, 10, 23, 59, 30, 0, time.UTC)
+
+	result := replay(&staticConfig{}, base, []replayStep{
+		{At: 0, Window: window("code", "main.go - focusd")},
+	}, time.Minute)
+
+	checkSessions(t, base, result.Sessions, []wantSession{
+		{app: "VS Code", exe: "code", title: "main.go - focusd", start: 0, end: 30 * time.Second, date: "2026-03-10"},
+		{app: "VS Code", exe: "code", title: "main.go - focusd", start: 30 * time.Second, end: time.Minute, date: "2026-03-11"},
+	})
+
+	totals := make(map[string]int)
+	for _, a := range result.AppStats {
+		totals[a.Date] += a.TotalDurationSecs
+	}
+	if totals["2026-03-10"] != 30 || totals["2026-03-11"] != 30 {
+		t.Errorf("daily totals = %v, want 30s on each day", totals)
+	}
+}
+
+func TestReplayHashedTitlesUseInjectedKey(t *testing.T) {
+	isolateDataDir(t)
+	base := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	steps := []replayStep{{At: 0, Window: window("code", "main.go - focusd")}}
+
+	first := replay(&staticConfig{TitleMode: system.TitleModeHashed, HashKey: []byte("key-one")}, base, steps, 10*time.Second)
+	again := replay(&staticConfig{TitleMode: system.TitleModeHashed, HashKey: []byte("key-one")}, base, steps, 10*time.Second)
+	other := replay(&staticConfig{TitleMode: system.TitleModeHashed, HashKey: []byte("key-two")}, base, steps, 10*time.Second)
+
+	title := first.Sessions[0].WindowTitle
+	if !strings.HasPrefix(title, titleHashPrefix) {
+		t.Fatalf("title = %q, want a hash", title)
+	}
+	if again.Sessions[0].WindowTitle != title {
+		t.Errorf("same key produced %q and %q", title, again.Sessions[0].WindowTitle)
+	}
+	if other.Sessions[0].WindowTitle == title {
+		t.Errorf("different keys produced the same hash %q", title)
+	}
+}
+
+func TestReplayStoresNoRawSiteTitles(t *testing.T) {
+	isolateDataDir(t)
+	base := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	steps := []replayStep{{At: 0, Window: window("chrome", "Cats - YouTube - Google Chrome")}}
+	cats := []*regexp.Regexp{regexp.MustCompile("Cats")}
+
+	tests := []struct {
+		mode      string
+		patterns  []*regexp.Regexp
+		wantSites int
+	}{
+		{mode: system.TitleModeFull, patterns: cats, wantSites: 1},
+		{mode: system.TitleModeRedacted, wantSites: 1},
+		{mode: system.TitleModeHashed, wantSites: 1},
+		{mode: system.TitleModeNone, wantSites: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			config := &staticConfig{TitleMode: tt.mode, RedactionPatterns: tt.patterns, HashKey: []byte("key")}
+			result := replay(config, base, steps, 10*time.Second)
+
+			if len(result.Sessions) != 1 {
+				t.Fatalf("got %d sessions, want 1", len(result.Sessions))
+			}
+			if s := result.Sessions[0]; strings.Contains(s.WindowTitle, "Cats") || strings.Contains(s.Site, "Cats") {
+				t.Errorf("stored session title %q, site %q; want no raw title", s.WindowTitle, s.Site)
+			}
+			if len(result.BrowserStats) != tt.wantSites {
+				t.Fatalf("browser stats = %+v, want %d rows", result.BrowserStats, tt.wantSites)
+			}
+			for _, stat := range result.BrowserStats {
+				if strings.Contains(stat.AppName, "Cats") || stat.TotalDurationSecs != 10 {
+					t.Errorf("browser stat = %+v, want 10s without the raw title", stat)
+				}
+			}
+		})
+	}
+}
+
+func TestReplayTitleRulesKeepRealExe(t *testing.T) {
+	isolateDataDir(t)
+	base := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	config := &staticConfig{AppRules: []AppRule{
+		{Exe: "javaw", Title: "^Minecraft", Name: "Minecraft"},
+		{Exe: "chrome", Title: "Google Docs", Name: "Docs"},
+	}}
+
+	result := replay(config, base, []replayStep{
+		{At: 0, Window: window("javaw", "Minecraft 1.21")},
+		{At: 20 * time.Second, Window: window("javaw", "IntelliJ IDEA")},
+		{At: 40 * time.Second, Window: window("chrome", "Notes - Google Docs - Google Chrome")},
+	}, time.Minute)
+
+	checkSessions(t, base, result.Sessions, []wantSession{
+		{app: "Minecraft", exe: "javaw", title: "Minecraft 1.21", start: 0, end: 20 * time.Second},
+		{app: "Javaw", exe: "javaw", title: "IntelliJ IDEA", start: 20 * time.Second, end: 40 * time.Second},
+		{app: "Docs", exe: "chrome", title: "Notes - Google Docs - Google Chrome", start: 40 * time.Second, end: time.Minute},
+	})
+
+	if len(result.AppStats) != 3 {
+		t.Errorf("app stats = %+v, want separate rows for Minecraft, Javaw and Docs", result.AppStats)
+	}
+	if len(result.BrowserStats) != 1 || result.BrowserStats[0].AppName != "Notes - Google Docs" {
+		t.Errorf("browser stats = %+v, want the Docs session counted as a site", result.BrowserStats)
+	}
+}
+
+func newPollTracker(cfg TrackerConfig) (*Tracker, *scriptedWindowSource, *recordingNotifier) {
+	start := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	clock := newFakeClock(start)
+	windows := newScriptedWindowSource()
+	notifier := newRecordingNotifier(clock)
+	tracker := NewTrackerWithOptions(TrackerOptions{
+		Clock:      clock,
+		Windows:    windows,
+		Notifier:   notifier,
+		Repo:       newMemoryRepository(clock.Now),
+		IdleSource: newFakeIdleSource(start),
+		Config:     cfg,
+	})
+	return tracker, windows, notifier
+}
+
+func TestPollReportsWindowErrorOnce(t *testing.T) {
+	tracker, windows, notifier := newPollTracker(&staticConfig{})
+
+	noDisplay := errors.New("no X11 display: DISPLAY is not set")
+	windows.SetError(noDisplay)
+	for range 3 {
+		tracker.poll()
+	}
+	if got := len(notifier.Notifications()); got != 1 {
+		t.Fatalf("got %d notifications for a failing window source, want 1", got)
+	}
+	if err := tracker.WindowError(); err != noDisplay {
+		t.Errorf("WindowError() = %v, want %v", err, noDisplay)
+	}
+
+	windows.SetError(nil)
+	windows.Set(window("code", "main.go"))
+	tracker.poll()
+	if err := tracker.WindowError(); err != nil {
+		t.Errorf("WindowError() = %v after the source recovered", err)
+	}
+
+	windows.SetError(noDisplay)
+	tracker.poll()
+	if got := len(notifier.Notifications()); got != 2 {
+		t.Errorf("got %d notifications after a second outage, want 2", got)
+	}
+}
+
+func TestCurrentSessionAsStoredAppliesPrivacy(t *testing.T) {
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{system.TitleModeFull, "Cats - YouTube - Google Chrome"},
+		{system.TitleModeRedacted, "YouTube"},
+		{system.TitleModeNone, ""},
+	}
+	for _, tt := range tests {
+		tracker, windows, _ := newPollTracker(&staticConfig{TitleMode: tt.mode})
+		windows.Set(window("chrome", "Cats - YouTube - Google Chrome"))
+		tracker.poll()
+
+		session := tracker.CurrentSessionAsStored()
+		if session == nil {
+			t.Fatalf("%s: no current session", tt.mode)
+		}
+		if session.WindowTitle != tt.want {
+			t.Errorf("%s: CurrentSessionAsStored title = %q, want %q", tt.mode, session.WindowTitle, tt.want)
+		}
+		if raw := tracker.CurrentSession().WindowTitle; raw != "Cats - YouTube - Google Chrome" {
+			t.Errorf("%s: CurrentSession title = %q, want the raw title for limit checks", tt.mode, raw)
+		}
+	}
+}
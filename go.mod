@@ -3,6 +3,8 @@ module focusd
 go 1.23.0
 
 require (
+	github.com/jezek/xgb v1.1.1
+	golang.org/x/crypto v0.31.0
 	golang.org/x/sys v0.28.0
 	modernc.org/sqlite v1.29.5
 )
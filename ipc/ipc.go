@@ -0,0 +1,179 @@
+package ipc
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"focusd/core"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	CommandStop         = "stop"
+	CommandPause        = "pause"
+	CommandResume       = "resume"
+	CommandReloadConfig = "reload-config"
+	CommandGetState     = "get-state"
+	CommandDiscardSince = "discard-since"
+	CommandForgetApp    = "forget-app"
+	CommandForgetSite   = "forget-site"
+)
+
+const requestTimeout = 3 * time.Second
+
+type Request struct {
+	Command string    `json:"command"`
+	Since   time.Time `json:"since,omitempty"`
+	Target  string    `json:"target,omitempty"`
+}
+
+type Response struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+	State *State `json:"state,omitempty"`
+}
+
+type State struct {
+	PID         int                 `json:"pid"`
+	Version     string              `json:"version"`
+	Paused      bool                `json:"paused"`
+	Idle        bool                `json:"idle"`
+	IdleError   string              `json:"idle_error,omitempty"`
+	WindowError string              `json:"window_error,omitempty"`
+	Session     *core.ActiveSession `json:"session,omitempty"`
+}
+
+type Handler func(req Request) Response
+
+type listener interface {
+	Accept() (io.ReadWriteCloser, error)
+	Close() error
+}
+
+type Server struct {
+	handler  Handler
+	listener listener
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	closed   bool
+}
+
+func NewServer(handler Handler) (*Server, error) {
+	l, err := listen()
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{handler: handler, listener: l}
+
+	s.wg.Add(1)
+	go s.acceptLoop()
+	return s, nil
+}
+
+func (s *Server) acceptLoop() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return
+			}
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			ServeConn(conn, s.handler)
+		}()
+	}
+}
+
+// ServeConn answers the single request read from conn and closes it.
+func ServeConn(conn io.ReadWriteCloser, handler Handler) {
+	defer conn.Close()
+
+	var req Request
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil && len(line) == 0 {
+		return
+	}
+
+	var resp Response
+	if err := json.Unmarshal(line, &req); err != nil {
+		resp = Response{Error: "invalid request"}
+	} else {
+		resp = handler(req)
+	}
+
+	data, _ := json.Marshal(resp)
+	conn.Write(append(data, '\n'))
+}
+
+func (s *Server) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	err := s.listener.Close()
+	s.wg.Wait()
+	return err
+}
+
+func Send(command string) (*Response, error) {
+	return SendRequest(Request{Command: command})
+}
+
+func SendRequest(req Request) (*Response, error) {
+	command := req.Command
+	conn, err := dial(requestTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	data, _ := json.Marshal(req)
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to send %s: %w", command, err)
+	}
+
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil && len(line) == 0 {
+		return nil, fmt.Errorf("no response to %s: %w", command, err)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return nil, fmt.Errorf("invalid response to %s: %w", command, err)
+	}
+	if !resp.OK {
+		return &resp, fmt.Errorf("%s failed: %s", command, resp.Error)
+	}
+	return &resp, nil
+}
+
+func GetState() (*State, error) {
+	resp, err := Send(CommandGetState)
+	if err != nil {
+		return nil, err
+	}
+	return resp.State, nil
+}
+
+func IsDaemonReachable() bool {
+	_, err := Send(CommandGetState)
+	return err == nil
+}
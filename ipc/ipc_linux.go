@@ -0,0 +1,81 @@
+package ipc
+
+import (
+	"fmt"
+	"focusd/storage"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func SocketPath() (string, error) {
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		return filepath.Join(runtimeDir, "focusd.sock"), nil
+	}
+	dataDir, err := storage.GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "focusd.sock"), nil
+}
+
+type unixListener struct {
+	l    *net.UnixListener
+	path string
+}
+
+func listen() (listener, error) {
+	path, err := SocketPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("another focusd daemon is listening on %s", path)
+	}
+	os.Remove(path)
+
+	l, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	l.SetUnlinkOnClose(true)
+
+	if err := os.Chmod(path, 0600); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return &unixListener{l: l, path: path}, nil
+}
+
+func (u *unixListener) Accept() (io.ReadWriteCloser, error) {
+	conn, err := u.l.Accept()
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(requestTimeout))
+	return conn, nil
+}
+
+func (u *unixListener) Close() error {
+	return u.l.Close()
+}
+
+func dial(timeout time.Duration) (io.ReadWriteCloser, error) {
+	path, err := SocketPath()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialTimeout("unix", path, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("focusd daemon is not reachable: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+	return conn, nil
+}
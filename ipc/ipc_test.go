@@ -0,0 +1,54 @@
+package ipc
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+)
+
+func roundTrip(t *testing.T, handler Handler, line string) Response {
+	t.Helper()
+	client, server := net.Pipe()
+	defer client.Close()
+	go ServeConn(server, handler)
+
+	client.SetDeadline(time.Now().Add(time.Second))
+	if _, err := client.Write([]byte(line + "\n")); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := bufio.NewReader(client).ReadBytes('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp Response
+	if err := json.Unmarshal(reply, &resp); err != nil {
+		t.Fatalf("invalid response %q: %v", reply, err)
+	}
+	return resp
+}
+
+func TestServeConn(t *testing.T) {
+	var got Request
+	handler := func(req Request) Response {
+		got = req
+		return Response{OK: true, State: &State{Paused: req.Command == CommandPause}}
+	}
+
+	resp := roundTrip(t, handler, `{"command":"forget-site","target":"YouTube"}`)
+	if !resp.OK || got.Command != CommandForgetSite || got.Target != "YouTube" {
+		t.Errorf("request = %+v, response = %+v", got, resp)
+	}
+
+	resp = roundTrip(t, handler, `{"command":"pause"}`)
+	if resp.State == nil || !resp.State.Paused {
+		t.Errorf("pause response = %+v, want the handler's state", resp)
+	}
+
+	got = Request{}
+	resp = roundTrip(t, handler, `not json`)
+	if resp.OK || resp.Error != "invalid request" || got.Command != "" {
+		t.Errorf("invalid request reached the handler or was accepted: %+v", resp)
+	}
+}
@@ -0,0 +1,308 @@
+package ipc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const pipeBufferSize = 4096
+
+var (
+	errListenerClosed = errors.New("pipe listener closed")
+	errPipeTimeout    = errors.New("pipe i/o timeout")
+)
+
+func PipeName() string {
+	user := os.Getenv("USERNAME")
+	if user == "" {
+		return `\\.\pipe\focusd`
+	}
+	return `\\.\pipe\focusd-` + strings.ToLower(user)
+}
+
+type pipeListener struct {
+	name       string
+	sa         *windows.SecurityAttributes
+	closeEvent windows.Handle
+	mu         sync.Mutex
+	next       windows.Handle
+	closed     bool
+	release    sync.Once
+}
+
+func ownerOnlySecurity() (*windows.SecurityAttributes, error) {
+	user, err := windows.GetCurrentProcessToken().GetTokenUser()
+	if err != nil {
+		return nil, err
+	}
+	sd, err := windows.SecurityDescriptorFromString(fmt.Sprintf("D:P(A;;GA;;;%s)", user.User.Sid.String()))
+	if err != nil {
+		return nil, err
+	}
+	return &windows.SecurityAttributes{
+		Length:             uint32(unsafe.Sizeof(windows.SecurityAttributes{})),
+		SecurityDescriptor: sd,
+	}, nil
+}
+
+func createPipe(name string, sa *windows.SecurityAttributes, first bool) (windows.Handle, error) {
+	namePtr, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return 0, err
+	}
+	flags := uint32(windows.PIPE_ACCESS_DUPLEX | windows.FILE_FLAG_OVERLAPPED)
+	if first {
+		flags |= windows.FILE_FLAG_FIRST_PIPE_INSTANCE
+	}
+	return windows.CreateNamedPipe(
+		namePtr,
+		flags,
+		windows.PIPE_TYPE_BYTE|windows.PIPE_READMODE_BYTE|windows.PIPE_WAIT|windows.PIPE_REJECT_REMOTE_CLIENTS,
+		windows.PIPE_UNLIMITED_INSTANCES,
+		pipeBufferSize,
+		pipeBufferSize,
+		0,
+		sa,
+	)
+}
+
+func listen() (listener, error) {
+	name := PipeName()
+	sa, err := ownerOnlySecurity()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build pipe security descriptor: %w", err)
+	}
+
+	first, err := createPipe(name, sa, true)
+	if errors.Is(err, windows.ERROR_ACCESS_DENIED) {
+		return nil, fmt.Errorf("another focusd daemon is listening on %s", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pipe %s: %w", name, err)
+	}
+
+	closeEvent, err := windows.CreateEvent(nil, 1, 0, nil)
+	if err != nil {
+		windows.CloseHandle(first)
+		return nil, err
+	}
+	return &pipeListener{name: name, sa: sa, closeEvent: closeEvent, next: first}, nil
+}
+
+func (p *pipeListener) Accept() (io.ReadWriteCloser, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		p.releaseHandles()
+		return nil, errListenerClosed
+	}
+	if p.next == 0 {
+		next, err := createPipe(p.name, p.sa, false)
+		if err != nil {
+			p.mu.Unlock()
+			return nil, fmt.Errorf("failed to create pipe %s: %w", p.name, err)
+		}
+		p.next = next
+	}
+	handle := p.next
+	p.mu.Unlock()
+
+	if err := p.connect(handle); err != nil {
+		if errors.Is(err, errListenerClosed) {
+			p.releaseHandles()
+			return nil, err
+		}
+		p.mu.Lock()
+		windows.CloseHandle(handle)
+		p.next = 0
+		p.mu.Unlock()
+		return nil, err
+	}
+
+	next, err := createPipe(p.name, p.sa, false)
+	if err != nil {
+		next = 0
+	}
+
+	p.mu.Lock()
+	p.next = next
+	closed := p.closed
+	p.mu.Unlock()
+	if closed {
+		windows.CloseHandle(handle)
+		p.releaseHandles()
+		return nil, errListenerClosed
+	}
+
+	return newPipeConn(handle, time.Now().Add(requestTimeout)), nil
+}
+
+func (p *pipeListener) connect(handle windows.Handle) error {
+	event, err := windows.CreateEvent(nil, 1, 0, nil)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(event)
+
+	o := &windows.Overlapped{HEvent: event}
+	err = windows.ConnectNamedPipe(handle, o)
+	if err == nil || errors.Is(err, windows.ERROR_PIPE_CONNECTED) {
+		return nil
+	}
+	if !errors.Is(err, windows.ERROR_IO_PENDING) {
+		return err
+	}
+
+	which, err := windows.WaitForMultipleObjects([]windows.Handle{event, p.closeEvent}, false, windows.INFINITE)
+	if err != nil {
+		return err
+	}
+	var n uint32
+	if which != windows.WAIT_OBJECT_0 {
+		windows.CancelIoEx(handle, o)
+		windows.GetOverlappedResult(handle, o, &n, true)
+		return errListenerClosed
+	}
+	return windows.GetOverlappedResult(handle, o, &n, false)
+}
+
+func (p *pipeListener) releaseHandles() {
+	p.release.Do(func() {
+		p.mu.Lock()
+		if p.next != 0 {
+			windows.CloseHandle(p.next)
+			p.next = 0
+		}
+		p.mu.Unlock()
+		windows.CloseHandle(p.closeEvent)
+	})
+}
+
+func (p *pipeListener) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	return windows.SetEvent(p.closeEvent)
+}
+
+type pipeConn struct {
+	handle   windows.Handle
+	deadline time.Time
+	once     sync.Once
+}
+
+func newPipeConn(handle windows.Handle, deadline time.Time) *pipeConn {
+	return &pipeConn{handle: handle, deadline: deadline}
+}
+
+func (c *pipeConn) Read(b []byte) (int, error) {
+	n, err := c.overlapped(func(o *windows.Overlapped, done *uint32) error {
+		return windows.ReadFile(c.handle, b, done, o)
+	})
+	if errors.Is(err, windows.ERROR_BROKEN_PIPE) || errors.Is(err, windows.ERROR_PIPE_NOT_CONNECTED) {
+		return n, io.EOF
+	}
+	if err == nil && n == 0 && len(b) > 0 {
+		return 0, io.EOF
+	}
+	return n, err
+}
+
+func (c *pipeConn) Write(b []byte) (int, error) {
+	total := 0
+	for total < len(b) {
+		n, err := c.overlapped(func(o *windows.Overlapped, done *uint32) error {
+			return windows.WriteFile(c.handle, b[total:], done, o)
+		})
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+func (c *pipeConn) overlapped(op func(o *windows.Overlapped, done *uint32) error) (int, error) {
+	event, err := windows.CreateEvent(nil, 1, 0, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer windows.CloseHandle(event)
+
+	o := &windows.Overlapped{HEvent: event}
+	var n uint32
+	err = op(o, &n)
+	if errors.Is(err, windows.ERROR_IO_PENDING) {
+		err = c.wait(o, &n)
+	}
+	return int(n), err
+}
+
+func (c *pipeConn) wait(o *windows.Overlapped, n *uint32) error {
+	timeout := uint32(windows.INFINITE)
+	if !c.deadline.IsZero() {
+		remaining := time.Until(c.deadline)
+		if remaining < 0 {
+			remaining = 0
+		}
+		timeout = uint32(remaining / time.Millisecond)
+	}
+
+	event, err := windows.WaitForSingleObject(o.HEvent, timeout)
+	if err != nil {
+		return err
+	}
+	if event == uint32(windows.WAIT_TIMEOUT) {
+		windows.CancelIoEx(c.handle, o)
+		windows.GetOverlappedResult(c.handle, o, n, true)
+		return errPipeTimeout
+	}
+	return windows.GetOverlappedResult(c.handle, o, n, false)
+}
+
+func (c *pipeConn) Close() error {
+	var err error
+	c.once.Do(func() {
+		err = windows.CloseHandle(c.handle)
+	})
+	return err
+}
+
+func dial(timeout time.Duration) (io.ReadWriteCloser, error) {
+	namePtr, err := windows.UTF16PtrFromString(PipeName())
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	deadline := start.Add(timeout)
+	for {
+		handle, err := windows.CreateFile(
+			namePtr,
+			windows.GENERIC_READ|windows.GENERIC_WRITE,
+			0,
+			nil,
+			windows.OPEN_EXISTING,
+			windows.FILE_FLAG_OVERLAPPED|windows.SECURITY_SQOS_PRESENT|windows.SECURITY_IDENTIFICATION,
+			0,
+		)
+		if err == nil {
+			return newPipeConn(handle, deadline), nil
+		}
+
+		retry := errors.Is(err, windows.ERROR_PIPE_BUSY) ||
+			(errors.Is(err, windows.ERROR_FILE_NOT_FOUND) && time.Since(start) < 250*time.Millisecond)
+		if !retry || time.Now().After(deadline) {
+			return nil, fmt.Errorf("focusd daemon is not reachable: %w", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
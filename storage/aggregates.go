@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"fmt"
+	"focusd/system"
+	"strings"
+)
+
+func SessionKey(exeName string, startUnix int64) string {
+	return fmt.Sprintf("%s|%d", exeName, startUnix)
+}
+
+func GetSessionKeys(startDate, endDate string) (map[string]bool, error) {
+	whereClause, args := dateRangeClause(startDate, endDate)
+	rows, err := db.Query(`SELECT exe_name, start_time FROM sessions`+whereClause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make(map[string]bool)
+	for rows.Next() {
+		var exeName string
+		var startTime int64
+		if err := rows.Scan(&exeName, &startTime); err != nil {
+			return nil, err
+		}
+		keys[SessionKey(exeName, startTime)] = true
+	}
+	return keys, rows.Err()
+}
+
+func GetSessionsForDates(dates []string) ([]Session, error) {
+	if len(dates) == 0 {
+		return nil, nil
+	}
+
+	args := make([]interface{}, len(dates))
+	for i, d := range dates {
+		args[i] = d
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(dates)), ",")
+
+	rows, err := db.Query(`
+		SELECT id, app_name, exe_name, window_title, COALESCE(site, ''), start_time, end_time, duration_secs, date
+		FROM sessions
+		WHERE date IN (`+placeholders+`)
+		ORDER BY start_time ASC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSessions(rows)
+}
+
+func ReplaceDailyAggregates(dates []string, apps, sites []AppDailyStat) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, d := range dates {
+		if _, err := tx.Exec("DELETE FROM apps_daily WHERE date = ?", d); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM browsing_daily WHERE date = ?", d); err != nil {
+			return err
+		}
+	}
+
+	for _, a := range apps {
+		if _, err := tx.Exec(`
+			INSERT INTO apps_daily (date, app_name, exe_name, total_duration_secs, open_count)
+			VALUES (?, ?, ?, ?, ?)
+		`, a.Date, a.AppName, a.ExeName, a.TotalDurationSecs, a.OpenCount); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range sites {
+		if _, err := tx.Exec(`
+			INSERT INTO browsing_daily (date, domain_or_title, total_duration_secs, open_count)
+			VALUES (?, ?, ?, ?)
+		`, s.Date, sealTitle(s.AppName), s.TotalDurationSecs, s.OpenCount); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+type SessionIdentity struct {
+	ID      int64
+	ExeName string
+	AppName string
+}
+
+func UpdateSessionIdentities(updates []SessionIdentity) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare("UPDATE sessions SET exe_name = ?, app_name = ? WHERE id = ?")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, u := range updates {
+		if _, err := stmt.Exec(system.CanonicalExeName(u.ExeName), u.AppName, u.ID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// RenameAppAggregates gives every stored row of exeName the display name
+// appName, except rows named in keep, which belong to rules that split the
+// exe into separate apps. Rows that end up with the same name are merged.
+func RenameAppAggregates(exeName, appName string, keep []string) (int, error) {
+	exeName = system.CanonicalExeName(exeName)
+	match := "exe_name = ? AND app_name != ?"
+	args := []interface{}{exeName, appName}
+	for _, name := range keep {
+		match += " AND app_name != ?"
+		args = append(args, name)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec("UPDATE sessions SET app_name = ? WHERE "+match, append([]interface{}{appName}, args...)...)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	total := int(n)
+
+	tables := []struct{ table, period string }{
+		{"apps_daily", "date"},
+		{"apps_weekly", "week_start"},
+		{"apps_monthly", "month"},
+	}
+	for _, t := range tables {
+		if _, err := tx.Exec(`
+			INSERT INTO `+t.table+` (`+t.period+`, app_name, exe_name, total_duration_secs, open_count)
+			SELECT `+t.period+`, ?, exe_name, SUM(total_duration_secs), SUM(open_count)
+			FROM `+t.table+` WHERE `+match+`
+			GROUP BY `+t.period+`
+			ON CONFLICT(`+t.period+`, exe_name, app_name) DO UPDATE SET
+				total_duration_secs = total_duration_secs + excluded.total_duration_secs,
+				open_count = open_count + excluded.open_count
+		`, append([]interface{}{appName}, args...)...); err != nil {
+			return total, err
+		}
+		res, err := tx.Exec("DELETE FROM "+t.table+" WHERE "+match, args...)
+		if err != nil {
+			return total, err
+		}
+		n, _ := res.RowsAffected()
+		total += int(n)
+	}
+	return total, tx.Commit()
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"encoding/json"
 	"fmt"
+	"focusd/system"
 	"os"
 	"path/filepath"
 	"sort"
@@ -31,12 +32,22 @@ var (
 		"zen.exe":       true,
 	}
 	browserCache map[string]bool
+	privateModes map[string]string
 	browserMu    sync.RWMutex
 )
 
+const (
+	PrivateWindowsLabel  = "private"
+	PrivateWindowsIgnore = "ignore"
+	PrivateWindowsTrack  = "track"
+)
+
+var PrivateWindowModes = []string{PrivateWindowsLabel, PrivateWindowsIgnore, PrivateWindowsTrack}
+
 type BrowserConfig struct {
-	CustomBrowsers  []string `json:"custom_browsers"`
-	IgnoredBrowsers []string `json:"ignored_default_browsers"`
+	CustomBrowsers  []string          `json:"custom_browsers"`
+	IgnoredBrowsers []string          `json:"ignored_default_browsers"`
+	PrivateWindows  map[string]string `json:"private_windows,omitempty"`
 }
 
 func GetBrowserConfigPath() (string, error) {
@@ -66,6 +77,11 @@ func LoadBrowserConfig() (*BrowserConfig, error) {
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, err
 	}
+	for exe, mode := range config.PrivateWindows {
+		if !isPrivateWindowMode(mode) {
+			return nil, fmt.Errorf("unknown private_windows mode %q for %s", mode, exe)
+		}
+	}
 	return &config, nil
 }
 
@@ -80,6 +96,9 @@ func SaveBrowserConfig(config *BrowserConfig) error {
 		return err
 	}
 
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
 	return os.WriteFile(path, data, 0644)
 }
 
@@ -98,31 +117,47 @@ func GetBrowserList() map[string]bool {
 		return browserCache
 	}
 
+	config, _ := LoadBrowserConfig()
+	browserCache = buildBrowserList(config)
+	privateModes = buildPrivateModes(config)
+	return browserCache
+}
+
+func buildBrowserList(config *BrowserConfig) map[string]bool {
 	combined := make(map[string]bool)
 
 	for k, v := range defaultBrowsers {
-		combined[k] = v
+		combined[system.CanonicalExeName(k)] = v
 	}
 
-	config, _ := LoadBrowserConfig()
 	if config != nil {
 		for _, b := range config.CustomBrowsers {
-			combined[strings.ToLower(b)] = true
+			combined[system.CanonicalExeName(b)] = true
 		}
 	}
+	return combined
+}
 
+func ReloadBrowserConfig() error {
+	config, err := LoadBrowserConfig()
+	if err != nil {
+		return fmt.Errorf("invalid browsers.json: %w", err)
+	}
+
+	combined := buildBrowserList(config)
+	modes := buildPrivateModes(config)
+	browserMu.Lock()
 	browserCache = combined
-	return combined
+	privateModes = modes
+	browserMu.Unlock()
+	return nil
 }
 
 func AddCustomBrowser(exeName string) error {
-	exeName = strings.ToLower(strings.TrimSpace(exeName))
+	exeName = system.CanonicalExeName(exeName)
 	if exeName == "" {
 		return fmt.Errorf("invalid browser name")
 	}
-	if !strings.HasSuffix(exeName, ".exe") {
-		exeName += ".exe"
-	}
 
 	browserMu.Lock()
 	defer browserMu.Unlock()
@@ -133,12 +168,12 @@ func AddCustomBrowser(exeName string) error {
 	}
 
 	for _, b := range config.CustomBrowsers {
-		if b == exeName {
+		if system.CanonicalExeName(b) == exeName {
 			return fmt.Errorf("%s is already in custom list", exeName)
 		}
 	}
 
-	if defaultBrowsers[exeName] {
+	if isDefaultBrowser(exeName) {
 		return fmt.Errorf("%s is already a default browser", exeName)
 	}
 
@@ -152,7 +187,7 @@ func AddCustomBrowser(exeName string) error {
 }
 
 func RemoveCustomBrowser(exeName string) error {
-	exeName = strings.ToLower(strings.TrimSpace(exeName))
+	exeName = system.CanonicalExeName(exeName)
 
 	browserMu.Lock()
 	defer browserMu.Unlock()
@@ -165,7 +200,7 @@ func RemoveCustomBrowser(exeName string) error {
 	found := false
 	newList := []string{}
 	for _, b := range config.CustomBrowsers {
-		if b == exeName {
+		if system.CanonicalExeName(b) == exeName {
 			found = true
 			continue
 		}
@@ -195,6 +230,97 @@ func GetCustomBrowsersList() []string {
 }
 
 func IsBrowser(exeName string) bool {
-	list := GetBrowserList()
-	return list[strings.ToLower(exeName)]
+	return GetBrowserList()[system.CanonicalExeName(exeName)]
+}
+
+func isDefaultBrowser(exeName string) bool {
+	for b := range defaultBrowsers {
+		if system.CanonicalExeName(b) == exeName {
+			return true
+		}
+	}
+	return false
+}
+
+func isPrivateWindowMode(mode string) bool {
+	for _, m := range PrivateWindowModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+func buildPrivateModes(config *BrowserConfig) map[string]string {
+	modes := make(map[string]string)
+	if config != nil {
+		for exe, mode := range config.PrivateWindows {
+			modes[system.CanonicalExeName(exe)] = mode
+		}
+	}
+	return modes
+}
+
+func GetPrivateWindowMode(exeName string) string {
+	GetBrowserList()
+
+	browserMu.RLock()
+	defer browserMu.RUnlock()
+	if mode, ok := privateModes[system.CanonicalExeName(exeName)]; ok {
+		return mode
+	}
+	return PrivateWindowsLabel
+}
+
+func GetPrivateWindowModes() map[string]string {
+	GetBrowserList()
+
+	browserMu.RLock()
+	defer browserMu.RUnlock()
+	modes := make(map[string]string, len(privateModes))
+	for exe, mode := range privateModes {
+		modes[exe] = mode
+	}
+	return modes
+}
+
+func SetPrivateWindowMode(exeName, mode string) error {
+	exeName = system.CanonicalExeName(exeName)
+	if exeName == "" {
+		return fmt.Errorf("invalid browser name")
+	}
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if !isPrivateWindowMode(mode) {
+		return fmt.Errorf("unknown mode %q (use %s)", mode, strings.Join(PrivateWindowModes, ", "))
+	}
+	if !IsBrowser(exeName) {
+		return fmt.Errorf("%s is not a known browser", exeName)
+	}
+
+	browserMu.Lock()
+	defer browserMu.Unlock()
+
+	config, err := LoadBrowserConfig()
+	if err != nil {
+		return err
+	}
+
+	if config.PrivateWindows == nil {
+		config.PrivateWindows = make(map[string]string)
+	}
+	for exe := range config.PrivateWindows {
+		if system.CanonicalExeName(exe) == exeName {
+			delete(config.PrivateWindows, exe)
+		}
+	}
+	if mode != PrivateWindowsLabel {
+		config.PrivateWindows[exeName] = mode
+	}
+	if err := SaveBrowserConfig(config); err != nil {
+		return err
+	}
+
+	browserCache = nil
+	privateModes = nil
+	return nil
 }
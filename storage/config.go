@@ -1,6 +1,10 @@
 package storage
 
 import (
+	"database/sql"
+	"fmt"
+	"focusd/system"
+	"strconv"
 	"time"
 )
 
@@ -11,10 +15,25 @@ const (
 	ConfigKeyAutostart        = "autostart_enabled"
 	ConfigKeyPathEnabled      = "path_enabled"
 	ConfigKeyPaused           = "tracking_paused"
+	ConfigKeyTitleEncryption  = "title_encryption"
+
+	ConfigKeyPasswordFailures    = "password_failures"
+	ConfigKeyPasswordLockedUntil = "password_locked_until"
+
+	ConfigKeyDailyRetentionDays  = "daily_retention_days"
+	ConfigKeyRollupRetentionDays = "rollup_retention_days"
+
+	ConfigKeyLimitWarningsFired = "limit_warnings_fired"
 
 	DefaultRetentionDays = 7
-	MaxRetentionDays     = 30
+	MaxRetentionDays     = 365
 	MinRetentionDays     = 1
+
+	DefaultDailyRetentionDays = 365
+	MaxDailyRetentionDays     = 3650
+	MinDailyRetentionDays     = 62
+
+	DefaultRollupRetentionDays = 0
 )
 
 func GetConfig(key string) (string, error) {
@@ -59,33 +78,76 @@ func SetConsent(granted bool) error {
 }
 
 func GetRetentionDays() int {
-	value, err := GetConfig(ConfigKeyRetentionDays)
-	if err != nil {
-		return DefaultRetentionDays
+	return getIntConfig(ConfigKeyRetentionDays, DefaultRetentionDays, MinRetentionDays, MaxRetentionDays)
+}
+
+func SetRetentionDays(days int) error {
+	days = clamp(days, MinRetentionDays, MaxRetentionDays)
+	if days > GetDailyRetentionDays() {
+		if err := SetConfig(ConfigKeyDailyRetentionDays, intToStr(clamp(days, MinDailyRetentionDays, MaxDailyRetentionDays))); err != nil {
+			return err
+		}
+	}
+	return SetConfig(ConfigKeyRetentionDays, intToStr(days))
+}
+
+func GetDailyRetentionDays() int {
+	return getIntConfig(ConfigKeyDailyRetentionDays, DefaultDailyRetentionDays, MinDailyRetentionDays, MaxDailyRetentionDays)
+}
+
+func SetDailyRetentionDays(days int) error {
+	days = clamp(days, MinDailyRetentionDays, MaxDailyRetentionDays)
+	if days < GetRetentionDays() {
+		return fmt.Errorf("daily aggregates must be kept at least as long as raw sessions (%d days)", GetRetentionDays())
 	}
-	var days int
-	if _, err := time.ParseDuration(value + "h"); err == nil {
-		return DefaultRetentionDays
+	if rollup := GetRollupRetentionDays(); rollup != 0 && rollup < days {
+		return fmt.Errorf("rollups must be kept at least as long as daily aggregates (%d days)", days)
 	}
-	if n, err := parseInt(value); err == nil {
-		days = n
-	} else {
-		return DefaultRetentionDays
+	return SetConfig(ConfigKeyDailyRetentionDays, intToStr(days))
+}
+
+func GetRollupRetentionDays() int {
+	value, err := GetConfig(ConfigKeyRollupRetentionDays)
+	if err != nil {
+		return DefaultRollupRetentionDays
 	}
-	if days < MinRetentionDays || days > MaxRetentionDays {
-		return DefaultRetentionDays
+	days, err := parseInt(value)
+	if err != nil || days < 0 {
+		return DefaultRollupRetentionDays
 	}
 	return days
 }
 
-func SetRetentionDays(days int) error {
-	if days < MinRetentionDays {
-		days = MinRetentionDays
+func SetRollupRetentionDays(days int) error {
+	if days < 0 {
+		days = 0
 	}
-	if days > MaxRetentionDays {
-		days = MaxRetentionDays
+	if days != 0 && days < GetDailyRetentionDays() {
+		return fmt.Errorf("rollups must be kept at least as long as daily aggregates (%d days)", GetDailyRetentionDays())
 	}
-	return SetConfig(ConfigKeyRetentionDays, intToStr(days))
+	return SetConfig(ConfigKeyRollupRetentionDays, intToStr(days))
+}
+
+func getIntConfig(key string, def, min, max int) int {
+	value, err := GetConfig(key)
+	if err != nil || value == "" {
+		return def
+	}
+	n, err := parseInt(value)
+	if err != nil || n < min || n > max {
+		return def
+	}
+	return n
+}
+
+func clamp(n, min, max int) int {
+	if n < min {
+		return min
+	}
+	if n > max {
+		return max
+	}
+	return n
 }
 
 func IsPaused() bool {
@@ -104,11 +166,64 @@ func SetPaused(paused bool) error {
 	return SetConfig(ConfigKeyPaused, value)
 }
 
+func PasswordLockRemaining() time.Duration {
+	value, err := GetConfig(ConfigKeyPasswordLockedUntil)
+	if err != nil {
+		return 0
+	}
+	lockedUntil, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	if remaining := time.Until(time.Unix(lockedUntil, 0)); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// RecordPasswordAttempt keeps the failure count in the database rather than
+// in config.json, so editing the settings file does not lift a lockout.
+func RecordPasswordAttempt(ok bool) error {
+	if ok {
+		_, err := db.Exec("DELETE FROM config WHERE key IN (?, ?)", ConfigKeyPasswordFailures, ConfigKeyPasswordLockedUntil)
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	failures := 0
+	var value string
+	if err := tx.QueryRow("SELECT value FROM config WHERE key = ?", ConfigKeyPasswordFailures).Scan(&value); err == nil {
+		failures, _ = parseInt(value)
+	} else if err != sql.ErrNoRows {
+		return err
+	}
+	failures++
+
+	values := map[string]string{ConfigKeyPasswordFailures: intToStr(failures)}
+	if lockout := system.PasswordLockoutFor(failures); lockout > 0 {
+		values[ConfigKeyPasswordLockedUntil] = strconv.FormatInt(time.Now().Add(lockout).Unix(), 10)
+	}
+	for key, value := range values {
+		if _, err := tx.Exec(`
+			INSERT INTO config (key, value, updated_at) VALUES (?, ?, ?)
+			ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+		`, key, value, time.Now().Unix()); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
 func parseInt(s string) (int, error) {
 	var n int
 	for _, c := range s {
 		if c < '0' || c > '9' {
-			return 0, nil
+			return 0, fmt.Errorf("invalid number %q", s)
 		}
 		n = n*10 + int(c-'0')
 	}
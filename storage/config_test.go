@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordPasswordAttemptLocksAndResets(t *testing.T) {
+	useTempDataDir(t)
+	openTestDB(t)
+
+	for i := 0; i < 2; i++ {
+		if err := RecordPasswordAttempt(false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if remaining := PasswordLockRemaining(); remaining != 0 {
+		t.Fatalf("locked for %s after two failures, want no lockout yet", remaining)
+	}
+
+	if err := RecordPasswordAttempt(false); err != nil {
+		t.Fatal(err)
+	}
+	if remaining := PasswordLockRemaining(); remaining <= 0 || remaining > 30*time.Second {
+		t.Fatalf("lockout after three failures = %s, want up to 30s", remaining)
+	}
+
+	if err := RecordPasswordAttempt(true); err != nil {
+		t.Fatal(err)
+	}
+	if remaining := PasswordLockRemaining(); remaining != 0 {
+		t.Errorf("still locked for %s after a correct password", remaining)
+	}
+	if _, err := GetConfig(ConfigKeyPasswordFailures); err == nil {
+		t.Error("failure count survived a correct password")
+	}
+}
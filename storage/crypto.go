@@ -0,0 +1,280 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"focusd/system"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	titleKeyName      = "title-encryption"
+	titleKeyLen       = 32
+	titleCipherPrefix = "enc1:"
+	unreadableTitle   = "[encrypted]"
+)
+
+var (
+	keystore system.Keystore = system.DefaultKeystore()
+
+	titleMu     sync.RWMutex
+	titleAEAD   cipher.AEAD
+	titleMACKey []byte
+)
+
+var titleColumns = []struct {
+	table  string
+	column string
+}{
+	{"sessions", "window_title"},
+	{"sessions", "site"},
+	{"active_session", "window_title"},
+	{"browsing_daily", "domain_or_title"},
+	{"browsing_weekly", "domain_or_title"},
+	{"browsing_monthly", "domain_or_title"},
+}
+
+func GetOrCreateKey(name string, size int) ([]byte, error) {
+	key, err := keystore.Get(name)
+	if err == nil {
+		return key, nil
+	}
+	if !errors.Is(err, system.ErrKeyNotFound) {
+		return nil, err
+	}
+
+	key = make([]byte, size)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := keystore.Set(name, key); err != nil {
+		return nil, fmt.Errorf("failed to store key: %w", err)
+	}
+	return key, nil
+}
+
+func IsTitleEncryptionEnabled() bool {
+	value, err := GetConfig(ConfigKeyTitleEncryption)
+	return err == nil && value == "true"
+}
+
+func loadTitleCipher() error {
+	titleMu.Lock()
+	defer titleMu.Unlock()
+	titleAEAD, titleMACKey = nil, nil
+
+	if !IsTitleEncryptionEnabled() {
+		return nil
+	}
+
+	key, err := keystore.Get(titleKeyName)
+	if errors.Is(err, system.ErrKeyNotFound) {
+		return fmt.Errorf("title encryption is enabled but its key is missing from the keystore")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read title encryption key: %w", err)
+	}
+	return setTitleKey(key)
+}
+
+func setTitleKey(key []byte) error {
+	if len(key) != titleKeyLen {
+		return fmt.Errorf("title encryption key has wrong length")
+	}
+
+	derived := hkdf.New(sha256.New, key, nil, []byte("focusd title encryption"))
+	encKey := make([]byte, 32)
+	macKey := make([]byte, 32)
+	if _, err := io.ReadFull(derived, encKey); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(derived, macKey); err != nil {
+		return err
+	}
+
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	titleAEAD, titleMACKey = aead, macKey
+	return nil
+}
+
+func sealTitle(title string) string {
+	titleMu.RLock()
+	defer titleMu.RUnlock()
+	return sealTitleWith(titleAEAD, titleMACKey, title)
+}
+
+func sealTitleWith(aead cipher.AEAD, macKey []byte, title string) string {
+	if aead == nil || title == "" || strings.HasPrefix(title, titleCipherPrefix) {
+		return title
+	}
+
+	// The nonce is derived from the title so equal titles seal to equal
+	// ciphertexts, which keeps the UNIQUE keys on the browsing tables working.
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write([]byte(title))
+	nonce := mac.Sum(nil)[:aead.NonceSize()]
+
+	sealed := aead.Seal(append([]byte(nil), nonce...), nonce, []byte(title), nil)
+	return titleCipherPrefix + base64.RawStdEncoding.EncodeToString(sealed)
+}
+
+func openTitle(value string) string {
+	if !strings.HasPrefix(value, titleCipherPrefix) {
+		return value
+	}
+
+	titleMu.RLock()
+	aead := titleAEAD
+	titleMu.RUnlock()
+	if aead == nil {
+		return unreadableTitle
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, titleCipherPrefix))
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return unreadableTitle
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return unreadableTitle
+	}
+	return string(plain)
+}
+
+func EnableTitleEncryption() error {
+	if IsTitleEncryptionEnabled() {
+		return nil
+	}
+
+	key, err := GetOrCreateKey(titleKeyName, titleKeyLen)
+	if err != nil {
+		return err
+	}
+
+	titleMu.Lock()
+	defer titleMu.Unlock()
+	if err := setTitleKey(key); err != nil {
+		return err
+	}
+
+	aead, macKey := titleAEAD, titleMACKey
+	err = rewriteTitles(func(v string) (string, bool) {
+		if v == "" || strings.HasPrefix(v, titleCipherPrefix) {
+			return v, false
+		}
+		return sealTitleWith(aead, macKey, v), true
+	}, "true")
+	if err != nil {
+		titleAEAD, titleMACKey = nil, nil
+	}
+	return err
+}
+
+func DisableTitleEncryption() error {
+	if !IsTitleEncryptionEnabled() {
+		return nil
+	}
+	if err := loadTitleCipher(); err != nil {
+		return err
+	}
+
+	var unreadable int
+	err := rewriteTitles(func(v string) (string, bool) {
+		if !strings.HasPrefix(v, titleCipherPrefix) {
+			return v, false
+		}
+		plain := openTitle(v)
+		if plain == unreadableTitle {
+			unreadable++
+			return v, false
+		}
+		return plain, true
+	}, "false")
+	if err != nil {
+		return err
+	}
+	if unreadable > 0 {
+		return fmt.Errorf("%d titles could not be decrypted; the key was kept", unreadable)
+	}
+
+	titleMu.Lock()
+	titleAEAD, titleMACKey = nil, nil
+	titleMu.Unlock()
+	return keystore.Delete(titleKeyName)
+}
+
+func rewriteTitles(convert func(string) (string, bool), enabled string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, c := range titleColumns {
+		if err := rewriteColumn(tx, c.table, c.column, convert); err != nil {
+			return fmt.Errorf("%s: %w", c.table, err)
+		}
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO config (key, value, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+	`, ConfigKeyTitleEncryption, enabled, time.Now().Unix()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func rewriteColumn(tx *sql.Tx, table, column string, convert func(string) (string, bool)) error {
+	rows, err := tx.Query("SELECT rowid, COALESCE(" + column + ", '') FROM " + table)
+	if err != nil {
+		return err
+	}
+
+	type update struct {
+		id    int64
+		value string
+	}
+	var updates []update
+	for rows.Next() {
+		var id int64
+		var value string
+		if err := rows.Scan(&id, &value); err != nil {
+			rows.Close()
+			return err
+		}
+		if converted, changed := convert(value); changed {
+			updates = append(updates, update{id, converted})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, u := range updates {
+		if _, err := tx.Exec("UPDATE "+table+" SET "+column+" = ? WHERE rowid = ?", u.value, u.id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"focusd/system"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryKeystore struct {
+	mu   sync.Mutex
+	keys map[string][]byte
+}
+
+func (m *memoryKeystore) Get(name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	secret, ok := m.keys[name]
+	if !ok {
+		return nil, system.ErrKeyNotFound
+	}
+	return append([]byte(nil), secret...), nil
+}
+
+func (m *memoryKeystore) Set(name string, secret []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys[name] = append([]byte(nil), secret...)
+	return nil
+}
+
+func (m *memoryKeystore) Delete(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.keys, name)
+	return nil
+}
+
+func useMemoryKeystore(t *testing.T) *memoryKeystore {
+	t.Helper()
+	ks := &memoryKeystore{keys: make(map[string][]byte)}
+	previous := keystore
+	keystore = ks
+	t.Cleanup(func() {
+		keystore = previous
+		useTitleKey(t, nil)
+	})
+	return ks
+}
+
+func useTitleKey(t *testing.T, key []byte) {
+	t.Helper()
+	titleMu.Lock()
+	defer titleMu.Unlock()
+	titleAEAD, titleMACKey = nil, nil
+	if key != nil {
+		if err := setTitleKey(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func randomKey(t *testing.T) []byte {
+	t.Helper()
+	key := make([]byte, titleKeyLen)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestSealTitleRoundTrip(t *testing.T) {
+	useMemoryKeystore(t)
+	useTitleKey(t, randomKey(t))
+
+	for _, title := range []string{"main.go - focusd", "YouTube", "日本語のタイトル"} {
+		sealed := sealTitle(title)
+		if !strings.HasPrefix(sealed, titleCipherPrefix) || strings.Contains(sealed, title) {
+			t.Errorf("sealTitle(%q) = %q, want ciphertext", title, sealed)
+		}
+		if again := sealTitle(title); again != sealed {
+			t.Errorf("sealTitle(%q) is not deterministic: %q != %q", title, again, sealed)
+		}
+		if got := sealTitle(sealed); got != sealed {
+			t.Errorf("sealTitle sealed an already sealed title")
+		}
+		if got := openTitle(sealed); got != title {
+			t.Errorf("openTitle(sealTitle(%q)) = %q", title, got)
+		}
+	}
+	if got := sealTitle(""); got != "" {
+		t.Errorf("sealTitle(\"\") = %q, want empty", got)
+	}
+	if got := openTitle("plain title"); got != "plain title" {
+		t.Errorf("openTitle(plain) = %q, want it unchanged", got)
+	}
+}
+
+func TestOpenTitleWithWrongKey(t *testing.T) {
+	useMemoryKeystore(t)
+	useTitleKey(t, randomKey(t))
+	sealed := sealTitle("secret project")
+
+	useTitleKey(t, randomKey(t))
+	if got := openTitle(sealed); got != unreadableTitle {
+		t.Errorf("openTitle with another key = %q, want %q", got, unreadableTitle)
+	}
+
+	useTitleKey(t, nil)
+	if got := openTitle(sealed); got != unreadableTitle {
+		t.Errorf("openTitle without a key = %q, want %q", got, unreadableTitle)
+	}
+}
+
+func TestOpenTitleRejectsTampering(t *testing.T) {
+	useMemoryKeystore(t)
+	useTitleKey(t, randomKey(t))
+	sealed := sealTitle("secret project")
+
+	raw, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(sealed, titleCipherPrefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	flipped := append([]byte(nil), raw...)
+	flipped[len(flipped)-1] ^= 1
+
+	tests := map[string]string{
+		"flipped bit": titleCipherPrefix + base64.RawStdEncoding.EncodeToString(flipped),
+		"truncated":   titleCipherPrefix + base64.RawStdEncoding.EncodeToString(raw[:8]),
+		"bad base64":  titleCipherPrefix + "!!!",
+	}
+	for name, value := range tests {
+		if got := openTitle(value); got != unreadableTitle {
+			t.Errorf("%s: openTitle = %q, want %q", name, got, unreadableTitle)
+		}
+	}
+}
+
+func TestTitleEncryptionEnableDisable(t *testing.T) {
+	useTempDataDir(t)
+	ks := useMemoryKeystore(t)
+	openTestDB(t)
+
+	start := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	session := &Session{AppName: "Chrome", ExeName: "chrome", WindowTitle: "secret project - Google Chrome", Site: "secret project", StartTime: start, EndTime: start.Add(time.Minute), DurationSecs: 60, Date: "2026-03-10"}
+	if err := InsertSession(session); err != nil {
+		t.Fatal(err)
+	}
+
+	storedTitle := func() string {
+		var title string
+		if err := db.QueryRow("SELECT window_title FROM sessions").Scan(&title); err != nil {
+			t.Fatal(err)
+		}
+		return title
+	}
+
+	if err := EnableTitleEncryption(); err != nil {
+		t.Fatal(err)
+	}
+	if got := storedTitle(); !strings.HasPrefix(got, titleCipherPrefix) {
+		t.Errorf("stored title after enable = %q, want ciphertext", got)
+	}
+	if _, err := ks.Get(titleKeyName); err != nil {
+		t.Errorf("key not stored in the keystore: %v", err)
+	}
+	sessions, err := GetAllSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].WindowTitle != session.WindowTitle || sessions[0].Site != session.Site {
+		t.Errorf("sessions read back = %+v, want the original title and site", sessions)
+	}
+
+	if err := DisableTitleEncryption(); err != nil {
+		t.Fatal(err)
+	}
+	if got := storedTitle(); got != session.WindowTitle {
+		t.Errorf("stored title after disable = %q, want %q", got, session.WindowTitle)
+	}
+	if _, err := ks.Get(titleKeyName); err != system.ErrKeyNotFound {
+		t.Errorf("key still in the keystore after disable: %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"database/sql"
 	"fmt"
+	"focusd/system"
 	"os"
 	"path/filepath"
 	"time"
@@ -13,11 +14,7 @@ import (
 var db *sql.DB
 
 func GetDataDir() (string, error) {
-	appData := os.Getenv("APPDATA")
-	if appData == "" {
-		return "", fmt.Errorf("APPDATA environment variable not set")
-	}
-	return filepath.Join(appData, "focusd"), nil
+	return system.GetDataDir()
 }
 
 func GetDBPath() (string, error) {
@@ -74,65 +71,10 @@ func Init() error {
 	db.Exec("PRAGMA mmap_size = 0")
 	db.Exec("PRAGMA temp_store = FILE")
 
-	return createSchema()
-}
-
-func createSchema() error {
-	schema := `
-	CREATE TABLE IF NOT EXISTS config (
-		key TEXT PRIMARY KEY,
-		value TEXT NOT NULL,
-		updated_at INTEGER NOT NULL
-	);
-
-	CREATE TABLE IF NOT EXISTS sessions (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		app_name TEXT NOT NULL,
-		exe_name TEXT NOT NULL,
-		window_title TEXT,
-		start_time INTEGER NOT NULL,
-		end_time INTEGER,
-		duration_secs INTEGER,
-		date TEXT NOT NULL
-	);
-
-	CREATE TABLE IF NOT EXISTS apps_daily (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		date TEXT NOT NULL,
-		app_name TEXT NOT NULL,
-		exe_name TEXT NOT NULL,
-		total_duration_secs INTEGER DEFAULT 0,
-		open_count INTEGER DEFAULT 0,
-		UNIQUE(date, exe_name)
-	);
-
-	CREATE TABLE IF NOT EXISTS active_session (
-		id INTEGER PRIMARY KEY CHECK (id = 1),
-		app_name TEXT NOT NULL,
-		exe_name TEXT NOT NULL,
-		window_title TEXT,
-		start_time INTEGER NOT NULL,
-		last_seen INTEGER NOT NULL,
-		date TEXT NOT NULL
-	);
-
-	CREATE TABLE IF NOT EXISTS browsing_daily (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		date TEXT NOT NULL,
-		domain_or_title TEXT NOT NULL,
-		total_duration_secs INTEGER DEFAULT 0,
-		open_count INTEGER DEFAULT 0,
-		UNIQUE(date, domain_or_title)
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_sessions_date ON sessions(date);
-	CREATE INDEX IF NOT EXISTS idx_sessions_start ON sessions(start_time);
-	CREATE INDEX IF NOT EXISTS idx_apps_daily_date ON apps_daily(date);
-
-	`
-
-	_, err := db.Exec(schema)
-	return err
+	if err := migrate(); err != nil {
+		return err
+	}
+	return loadTitleCipher()
 }
 
 func GetDB() *sql.DB {
@@ -0,0 +1,324 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"focusd/system"
+	"time"
+)
+
+type SiteFunc func(s Session) (string, bool)
+
+type ForgetResult struct {
+	Sessions      int
+	AggregateRows int
+	Active        bool
+}
+
+type aggregateDelta struct {
+	date string
+	key  string
+	app  string
+}
+
+type aggregateAdjuster struct {
+	siteOf    SiteFunc
+	appSecs   map[aggregateDelta]int
+	appOpens  map[aggregateDelta]int
+	siteSecs  map[aggregateDelta]int
+	siteOpens map[aggregateDelta]int
+}
+
+func newAggregateAdjuster(siteOf SiteFunc) *aggregateAdjuster {
+	return &aggregateAdjuster{
+		siteOf:    siteOf,
+		appSecs:   make(map[aggregateDelta]int),
+		appOpens:  make(map[aggregateDelta]int),
+		siteSecs:  make(map[aggregateDelta]int),
+		siteOpens: make(map[aggregateDelta]int),
+	}
+}
+
+func (a *aggregateAdjuster) remove(s Session, secs, opens int) {
+	a.adjust(s, -secs, -opens)
+}
+
+func (a *aggregateAdjuster) add(s Session, secs, opens int) {
+	a.adjust(s, secs, opens)
+}
+
+func (a *aggregateAdjuster) adjust(s Session, secs, opens int) {
+	appKey := aggregateDelta{s.Date, s.ExeName, s.AppName}
+	a.appSecs[appKey] += secs
+	a.appOpens[appKey] += opens
+
+	if site, ok := a.siteOf(s); ok {
+		siteKey := aggregateDelta{s.Date, sealTitle(site), ""}
+		a.siteSecs[siteKey] += secs
+		a.siteOpens[siteKey] += opens
+	}
+}
+
+// apply adds the collected changes to the daily totals and to every weekly
+// and monthly rollup covering them, including finalized periods whose daily
+// rows have been pruned.
+func (a *aggregateAdjuster) apply(tx *sql.Tx) error {
+	day := func(date string) string { return date }
+	if err := adjustAggregates(tx, "apps_daily", "date", "exe_name", true, day, a.appSecs, a.appOpens); err != nil {
+		return err
+	}
+	if err := adjustAggregates(tx, "browsing_daily", "date", "domain_or_title", false, day, a.siteSecs, a.siteOpens); err != nil {
+		return err
+	}
+
+	for _, spec := range rollupSpecs {
+		secs, opens := a.appSecs, a.appOpens
+		if spec.source == "browsing_daily" {
+			secs, opens = a.siteSecs, a.siteOpens
+		}
+		if err := adjustAggregates(tx, spec.table, spec.periodCol, spec.keyCol, spec.appName, spec.period, secs, opens); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func adjustAggregates(tx *sql.Tx, table, periodCol, keyCol string, byApp bool, period func(date string) string, secs, opens map[aggregateDelta]int) error {
+	periodSecs := make(map[aggregateDelta]int)
+	periodOpens := make(map[aggregateDelta]int)
+	for k, n := range secs {
+		key := aggregateDelta{period(k.date), k.key, k.app}
+		periodSecs[key] += n
+		periodOpens[key] += opens[k]
+	}
+
+	cols := periodCol + ", " + keyCol
+	values := "?, ?"
+	if byApp {
+		cols += ", app_name"
+		values += ", ?"
+	}
+	query := fmt.Sprintf(`
+		INSERT INTO %s (%s, total_duration_secs, open_count)
+		VALUES (%s, ?, ?)
+		ON CONFLICT(%s) DO UPDATE SET
+			total_duration_secs = MAX(0, total_duration_secs + excluded.total_duration_secs),
+			open_count = MAX(0, open_count + excluded.open_count)
+	`, table, cols, values, cols)
+	for k, n := range periodSecs {
+		args := []interface{}{k.date, k.key}
+		if byApp {
+			args = append(args, k.app)
+		}
+		if _, err := tx.Exec(query, append(args, n, periodOpens[k])...); err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.Exec("DELETE FROM " + table + " WHERE total_duration_secs <= 0")
+	return err
+}
+
+// ImportSessions inserts sessions and adds their time to the daily totals and
+// rollups, leaving totals already kept for those days in place.
+func ImportSessions(sessions []*Session, siteOf SiteFunc) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO sessions (app_name, exe_name, window_title, site, start_time, end_time, duration_secs, date)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	adjuster := newAggregateAdjuster(siteOf)
+	for _, s := range sessions {
+		s.ExeName = system.CanonicalExeName(s.ExeName)
+		var endTime *int64
+		if !s.EndTime.IsZero() {
+			t := s.EndTime.Unix()
+			endTime = &t
+		}
+		if _, err := stmt.Exec(s.AppName, s.ExeName, sealTitle(s.WindowTitle), sealTitle(s.Site), s.StartTime.Unix(), endTime, s.DurationSecs, s.Date); err != nil {
+			return err
+		}
+		adjuster.add(*s, s.DurationSecs, 1)
+	}
+
+	if err := adjuster.apply(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func DeleteSessionsSince(from time.Time, siteOf SiteFunc) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		SELECT id, app_name, exe_name, window_title, COALESCE(site, ''), start_time, end_time, duration_secs, date
+		FROM sessions
+		WHERE COALESCE(end_time, start_time + duration_secs) > ?
+	`, from.Unix())
+	if err != nil {
+		return err
+	}
+	sessions, err := scanSessions(rows)
+	rows.Close()
+	if err != nil {
+		return err
+	}
+
+	adjuster := newAggregateAdjuster(siteOf)
+	for _, s := range sessions {
+		kept := 0
+		if s.StartTime.Before(from) {
+			kept = int(from.Sub(s.StartTime).Seconds())
+		}
+		if kept > s.DurationSecs {
+			kept = s.DurationSecs
+		}
+
+		if kept > 0 {
+			if _, err := tx.Exec(
+				"UPDATE sessions SET end_time = ?, duration_secs = ? WHERE id = ?",
+				from.Unix(), kept, s.ID,
+			); err != nil {
+				return err
+			}
+			adjuster.remove(s, s.DurationSecs-kept, 0)
+			continue
+		}
+
+		if _, err := tx.Exec("DELETE FROM sessions WHERE id = ?", s.ID); err != nil {
+			return err
+		}
+		adjuster.remove(s, s.DurationSecs, 1)
+	}
+
+	if err := adjuster.apply(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM active_session WHERE last_seen > ?", from.Unix()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func ForgetSessions(match func(s Session) bool, siteOf SiteFunc) (*ForgetResult, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		SELECT id, app_name, exe_name, window_title, COALESCE(site, ''), start_time, end_time, duration_secs, date
+		FROM sessions
+	`)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := scanSessions(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ForgetResult{}
+	adjuster := newAggregateAdjuster(siteOf)
+	for _, s := range sessions {
+		if !match(s) {
+			continue
+		}
+		if _, err := tx.Exec("DELETE FROM sessions WHERE id = ?", s.ID); err != nil {
+			return nil, err
+		}
+		adjuster.remove(s, s.DurationSecs, 1)
+		result.Sessions++
+	}
+
+	if err := adjuster.apply(tx); err != nil {
+		return nil, err
+	}
+
+	var active Session
+	var startTime int64
+	err = tx.QueryRow(`
+		SELECT app_name, exe_name, COALESCE(window_title, ''), start_time, date
+		FROM active_session WHERE id = 1
+	`).Scan(&active.AppName, &active.ExeName, &active.WindowTitle, &startTime, &active.Date)
+	if err == nil {
+		active.WindowTitle = openTitle(active.WindowTitle)
+		active.StartTime = time.Unix(startTime, 0)
+		if match(active) {
+			if _, err := tx.Exec("DELETE FROM active_session"); err != nil {
+				return nil, err
+			}
+			result.Active = true
+		}
+	} else if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	return result, tx.Commit()
+}
+
+func DeleteAppAggregates(exeNames []string) (int, error) {
+	tables := []string{"apps_daily", "apps_weekly", "apps_monthly"}
+	total := 0
+	for _, exe := range exeNames {
+		for _, table := range tables {
+			res, err := db.Exec("DELETE FROM "+table+" WHERE exe_name = ? COLLATE NOCASE", exe)
+			if err != nil {
+				return total, err
+			}
+			n, _ := res.RowsAffected()
+			total += int(n)
+		}
+	}
+	return total, nil
+}
+
+func DeleteSiteAggregates(match func(site string) bool) (int, error) {
+	tables := []string{"browsing_daily", "browsing_weekly", "browsing_monthly"}
+	total := 0
+	for _, table := range tables {
+		rows, err := db.Query("SELECT DISTINCT domain_or_title FROM " + table)
+		if err != nil {
+			return total, err
+		}
+		var sites []string
+		for rows.Next() {
+			var site string
+			if err := rows.Scan(&site); err != nil {
+				rows.Close()
+				return total, err
+			}
+			if match(openTitle(site)) {
+				sites = append(sites, site)
+			}
+		}
+		rows.Close()
+
+		for _, site := range sites {
+			res, err := db.Exec("DELETE FROM "+table+" WHERE domain_or_title = ?", site)
+			if err != nil {
+				return total, err
+			}
+			n, _ := res.RowsAffected()
+			total += int(n)
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRollupPeriodMatchesSQL(t *testing.T) {
+	useTempDataDir(t)
+	openTestDB(t)
+
+	weekly, monthly := rollupSpecs[0], rollupSpecs[1]
+	for _, date := range []string{"2026-03-08", "2026-03-09", "2026-03-15", "2026-03-31", "2026-01-01"} {
+		var week, month string
+		if err := db.QueryRow("SELECT "+weekStartSQL+", "+monthSQL+" FROM (SELECT ? AS date)", date).Scan(&week, &month); err != nil {
+			t.Fatal(err)
+		}
+		if got := weekly.period(date); got != week {
+			t.Errorf("weekly period(%s) = %s, SQL says %s", date, got, week)
+		}
+		if got := monthly.period(date); got != month {
+			t.Errorf("monthly period(%s) = %s, SQL says %s", date, got, month)
+		}
+	}
+}
+
+func seedForgetData(t *testing.T) {
+	t.Helper()
+	useTempDataDir(t)
+	openTestDB(t)
+
+	start := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	sessions := []*Session{
+		{AppName: "Chrome", ExeName: "chrome", WindowTitle: "Cats - YouTube", StartTime: start, EndTime: start.Add(10 * time.Minute), DurationSecs: 600, Date: "2026-03-10"},
+		{AppName: "VS Code", ExeName: "code", WindowTitle: "main.go", StartTime: start.Add(time.Hour), EndTime: start.Add(90 * time.Minute), DurationSecs: 1800, Date: "2026-03-10"},
+	}
+	if err := InsertSessionsBatch(sessions); err != nil {
+		t.Fatal(err)
+	}
+
+	stmts := []string{
+		"INSERT INTO apps_daily (date, app_name, exe_name, total_duration_secs, open_count) VALUES ('2026-03-10', 'Chrome', 'chrome', 900, 2), ('2026-03-10', 'VS Code', 'code', 1800, 1)",
+		"INSERT INTO browsing_daily (date, domain_or_title, total_duration_secs, open_count) VALUES ('2026-03-10', 'YouTube', 600, 1)",
+		"INSERT INTO apps_weekly (week_start, app_name, exe_name, total_duration_secs, open_count) VALUES ('2026-03-09', 'Chrome', 'chrome', 900, 2), ('2026-03-09', 'VS Code', 'code', 1800, 1)",
+		"INSERT INTO apps_monthly (month, app_name, exe_name, total_duration_secs, open_count) VALUES ('2026-03', 'Chrome', 'chrome', 900, 2), ('2026-03', 'VS Code', 'code', 1800, 1)",
+		"INSERT INTO browsing_weekly (week_start, domain_or_title, total_duration_secs, open_count) VALUES ('2026-03-09', 'YouTube', 600, 1)",
+		"INSERT INTO browsing_monthly (month, domain_or_title, total_duration_secs, open_count) VALUES ('2026-03', 'YouTube', 600, 1)",
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func youTubeSite(s Session) (string, bool) {
+	if strings.Contains(s.WindowTitle, "YouTube") {
+		return "YouTube", true
+	}
+	return "", false
+}
+
+func TestForgetSessionsAdjustsRollups(t *testing.T) {
+	seedForgetData(t)
+
+	result, err := ForgetSessions(func(s Session) bool { return s.ExeName == "chrome" }, youTubeSite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Sessions != 1 {
+		t.Fatalf("forgot %d sessions, want 1", result.Sessions)
+	}
+
+	for _, q := range []struct {
+		query string
+		want  map[string]int
+	}{
+		{"SELECT exe_name, total_duration_secs FROM apps_daily", map[string]int{"chrome": 300, "code": 1800}},
+		{"SELECT exe_name, total_duration_secs FROM apps_weekly", map[string]int{"chrome": 300, "code": 1800}},
+		{"SELECT exe_name, total_duration_secs FROM apps_monthly", map[string]int{"chrome": 300, "code": 1800}},
+		{"SELECT domain_or_title, total_duration_secs FROM browsing_weekly", map[string]int{}},
+		{"SELECT domain_or_title, total_duration_secs FROM browsing_monthly", map[string]int{}},
+	} {
+		got := rollupTotals(t, q.query)
+		if len(got) != len(q.want) {
+			t.Errorf("%s = %v, want %v", q.query, got, q.want)
+			continue
+		}
+		for key, secs := range q.want {
+			if got[key] != secs {
+				t.Errorf("%s: %s = %ds, want %ds", q.query, key, got[key], secs)
+			}
+		}
+	}
+}
+
+func TestDeleteSessionsSinceAdjustsRollups(t *testing.T) {
+	seedForgetData(t)
+
+	from := time.Date(2026, 3, 10, 10, 15, 0, 0, time.Local)
+	if err := DeleteSessionsSince(from, youTubeSite); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, query := range []string{
+		"SELECT exe_name, total_duration_secs FROM apps_daily",
+		"SELECT exe_name, total_duration_secs FROM apps_weekly",
+		"SELECT exe_name, total_duration_secs FROM apps_monthly",
+	} {
+		got := rollupTotals(t, query)
+		if got["code"] != 900 || got["chrome"] != 900 {
+			t.Errorf("%s = %v, want code trimmed to 900s and chrome untouched", query, got)
+		}
+	}
+}
+
+func TestImportSessionsAddsToRetainedTotals(t *testing.T) {
+	seedForgetData(t)
+
+	start := time.Date(2026, 3, 10, 20, 0, 0, 0, time.Local)
+	imported := []*Session{
+		{AppName: "Chrome", ExeName: "Chrome", WindowTitle: "More cats - YouTube", StartTime: start, EndTime: start.Add(time.Minute), DurationSecs: 60, Date: "2026-03-10"},
+		{AppName: "VS Code", ExeName: "code", StartTime: start.Add(24 * time.Hour), EndTime: start.Add(24*time.Hour + 2*time.Minute), DurationSecs: 120, Date: "2026-03-11"},
+	}
+	if err := ImportSessions(imported, youTubeSite); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, q := range []struct {
+		query string
+		want  map[string]int
+	}{
+		{"SELECT exe_name || ' ' || date, total_duration_secs FROM apps_daily", map[string]int{"chrome 2026-03-10": 960, "code 2026-03-10": 1800, "code 2026-03-11": 120}},
+		{"SELECT exe_name, total_duration_secs FROM apps_weekly", map[string]int{"chrome": 960, "code": 1920}},
+		{"SELECT exe_name, total_duration_secs FROM apps_monthly", map[string]int{"chrome": 960, "code": 1920}},
+		{"SELECT domain_or_title, total_duration_secs FROM browsing_daily", map[string]int{"YouTube": 660}},
+		{"SELECT domain_or_title, total_duration_secs FROM browsing_weekly", map[string]int{"YouTube": 660}},
+		{"SELECT domain_or_title, total_duration_secs FROM browsing_monthly", map[string]int{"YouTube": 660}},
+	} {
+		got := rollupTotals(t, q.query)
+		if len(got) != len(q.want) {
+			t.Errorf("%s = %v, want %v", q.query, got, q.want)
+			continue
+		}
+		for key, secs := range q.want {
+			if got[key] != secs {
+				t.Errorf("%s: %s = %ds, want %ds", q.query, key, got[key], secs)
+			}
+		}
+	}
+
+	keys, err := GetSessionKeys("2026-03-10", "2026-03-11")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !keys[SessionKey("chrome", start.Unix())] {
+		t.Errorf("session keys = %v, want the imported chrome session under its canonical exe", keys)
+	}
+}
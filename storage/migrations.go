@@ -0,0 +1,379 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"focusd/system"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const maxBackups = 5
+
+type migration struct {
+	version int
+	name    string
+	up      func(tx *sql.Tx) error
+}
+
+var migrations = []migration{
+	{1, "initial schema", execSQL(`
+	CREATE TABLE IF NOT EXISTS config (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL,
+		updated_at INTEGER NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS sessions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		app_name TEXT NOT NULL,
+		exe_name TEXT NOT NULL,
+		window_title TEXT,
+		start_time INTEGER NOT NULL,
+		end_time INTEGER,
+		duration_secs INTEGER,
+		date TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS apps_daily (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		date TEXT NOT NULL,
+		app_name TEXT NOT NULL,
+		exe_name TEXT NOT NULL,
+		total_duration_secs INTEGER DEFAULT 0,
+		open_count INTEGER DEFAULT 0,
+		UNIQUE(date, exe_name)
+	);
+
+	CREATE TABLE IF NOT EXISTS active_session (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		app_name TEXT NOT NULL,
+		exe_name TEXT NOT NULL,
+		window_title TEXT,
+		start_time INTEGER NOT NULL,
+		last_seen INTEGER NOT NULL,
+		date TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS browsing_daily (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		date TEXT NOT NULL,
+		domain_or_title TEXT NOT NULL,
+		total_duration_secs INTEGER DEFAULT 0,
+		open_count INTEGER DEFAULT 0,
+		UNIQUE(date, domain_or_title)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_sessions_date ON sessions(date);
+	CREATE INDEX IF NOT EXISTS idx_sessions_start ON sessions(start_time);
+	CREATE INDEX IF NOT EXISTS idx_apps_daily_date ON apps_daily(date);
+	`)},
+	{2, "index browsing_daily by date", execSQL(`
+	CREATE INDEX IF NOT EXISTS idx_browsing_daily_date ON browsing_daily(date);
+	`)},
+	{3, "weekly and monthly rollups", execSQL(`
+	CREATE TABLE IF NOT EXISTS apps_weekly (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		week_start TEXT NOT NULL,
+		app_name TEXT NOT NULL,
+		exe_name TEXT NOT NULL,
+		total_duration_secs INTEGER DEFAULT 0,
+		open_count INTEGER DEFAULT 0,
+		UNIQUE(week_start, exe_name)
+	);
+
+	CREATE TABLE IF NOT EXISTS apps_monthly (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		month TEXT NOT NULL,
+		app_name TEXT NOT NULL,
+		exe_name TEXT NOT NULL,
+		total_duration_secs INTEGER DEFAULT 0,
+		open_count INTEGER DEFAULT 0,
+		UNIQUE(month, exe_name)
+	);
+
+	CREATE TABLE IF NOT EXISTS browsing_weekly (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		week_start TEXT NOT NULL,
+		domain_or_title TEXT NOT NULL,
+		total_duration_secs INTEGER DEFAULT 0,
+		open_count INTEGER DEFAULT 0,
+		UNIQUE(week_start, domain_or_title)
+	);
+
+	CREATE TABLE IF NOT EXISTS browsing_monthly (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		month TEXT NOT NULL,
+		domain_or_title TEXT NOT NULL,
+		total_duration_secs INTEGER DEFAULT 0,
+		open_count INTEGER DEFAULT 0,
+		UNIQUE(month, domain_or_title)
+	);
+	`)},
+	{4, "canonical exe names", canonicalizeExeNames},
+	{5, "session site keys", execSQL("ALTER TABLE sessions ADD COLUMN site TEXT")},
+	{6, "app identity in app_name", keyAppsByName},
+}
+
+func execSQL(query string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(query)
+		return err
+	}
+}
+
+func canonicalizeExeNames(tx *sql.Tx) error {
+	rollups := []struct{ table, period string }{
+		{"apps_daily", "date"},
+		{"apps_weekly", "week_start"},
+		{"apps_monthly", "month"},
+	}
+	for _, r := range rollups {
+		renames, err := exeRenames(tx, r.table)
+		if err != nil {
+			return err
+		}
+		for from, to := range renames {
+			if _, err := tx.Exec(`
+				INSERT INTO `+r.table+` (`+r.period+`, app_name, exe_name, total_duration_secs, open_count)
+				SELECT `+r.period+`, app_name, ?, total_duration_secs, open_count FROM `+r.table+` WHERE exe_name = ?
+				ON CONFLICT(`+r.period+`, exe_name) DO UPDATE SET
+					total_duration_secs = total_duration_secs + excluded.total_duration_secs,
+					open_count = open_count + excluded.open_count
+			`, to, from); err != nil {
+				return err
+			}
+			if _, err := tx.Exec("DELETE FROM "+r.table+" WHERE exe_name = ?", from); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, table := range []string{"sessions", "active_session"} {
+		renames, err := exeRenames(tx, table)
+		if err != nil {
+			return err
+		}
+		for from, to := range renames {
+			if _, err := tx.Exec("UPDATE "+table+" SET exe_name = ? WHERE exe_name = ?", to, from); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// keyAppsByName undoes the old "name@exe" identities, which hid the real exe
+// from browser and limit checks, and keys app totals by exe and app name so
+// rule-split apps keep separate rows.
+func keyAppsByName(tx *sql.Tx) error {
+	const baseExe = "CASE WHEN instr(exe_name, '@') > 0 THEN substr(exe_name, instr(exe_name, '@') + 1) ELSE exe_name END"
+
+	for _, table := range []string{"sessions", "active_session"} {
+		if _, err := tx.Exec("UPDATE " + table + " SET exe_name = " + baseExe + " WHERE instr(exe_name, '@') > 0"); err != nil {
+			return err
+		}
+	}
+
+	tables := []struct{ table, period string }{
+		{"apps_daily", "date"},
+		{"apps_weekly", "week_start"},
+		{"apps_monthly", "month"},
+	}
+	for _, t := range tables {
+		if _, err := tx.Exec(`
+			CREATE TABLE ` + t.table + `_new (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				` + t.period + ` TEXT NOT NULL,
+				app_name TEXT NOT NULL,
+				exe_name TEXT NOT NULL,
+				total_duration_secs INTEGER DEFAULT 0,
+				open_count INTEGER DEFAULT 0,
+				UNIQUE(` + t.period + `, exe_name, app_name)
+			)
+		`); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO ` + t.table + `_new (` + t.period + `, app_name, exe_name, total_duration_secs, open_count)
+			SELECT ` + t.period + `, app_name, ` + baseExe + ` AS exe, SUM(total_duration_secs), SUM(open_count)
+			FROM ` + t.table + `
+			GROUP BY ` + t.period + `, exe, app_name
+		`); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DROP TABLE " + t.table); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("ALTER TABLE " + t.table + "_new RENAME TO " + t.table); err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.Exec("CREATE INDEX IF NOT EXISTS idx_apps_daily_date ON apps_daily(date)")
+	return err
+}
+
+func exeRenames(tx *sql.Tx, table string) (map[string]string, error) {
+	rows, err := tx.Query("SELECT DISTINCT exe_name FROM " + table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	renames := make(map[string]string)
+	for rows.Next() {
+		var exe string
+		if err := rows.Scan(&exe); err != nil {
+			return nil, err
+		}
+		if canonical := system.CanonicalExeName(exe); canonical != exe && canonical != "" {
+			renames[exe] = canonical
+		}
+	}
+	return renames, rows.Err()
+}
+
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+func GetSchemaVersion() (int, error) {
+	var version int
+	err := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&version)
+	return version, err
+}
+
+func migrate() error {
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at INTEGER NOT NULL
+		)
+	`); err != nil {
+		return err
+	}
+
+	current, err := GetSchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	latest := LatestSchemaVersion()
+	if current > latest {
+		return fmt.Errorf("database schema version %d is newer than this focusd supports (%d); update focusd before opening it", current, latest)
+	}
+	if current == latest {
+		return nil
+	}
+
+	hasData, err := hasExistingTables()
+	if err != nil {
+		return err
+	}
+
+	if hasData {
+		if _, err := backupDatabase(current); err != nil {
+			return fmt.Errorf("failed to back up database before migrating from version %d: %w", current, err)
+		}
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := applyMigration(m); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.version, m.name, err)
+		}
+	}
+	return nil
+}
+
+func applyMigration(m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var applied int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM schema_version WHERE version = ?", m.version).Scan(&applied); err != nil {
+		return err
+	}
+	if applied > 0 {
+		return nil
+	}
+
+	if err := m.up(tx); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(
+		"INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)",
+		m.version, m.name, time.Now().Unix(),
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func hasExistingTables() (bool, error) {
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM sqlite_master
+		WHERE type = 'table' AND name IN ('sessions', 'apps_daily', 'config')
+	`).Scan(&count)
+	return count > 0, err
+}
+
+func GetBackupDir() (string, error) {
+	dataDir, err := GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "backups"), nil
+}
+
+func backupDatabase(version int) (string, error) {
+	backupDir, err := GetBackupDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(backupDir, 0700); err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("focusd-v%d-%s.db", version, time.Now().Format("20060102-150405"))
+	path := filepath.Join(backupDir, name)
+	os.Remove(path)
+
+	if _, err := db.Exec("VACUUM INTO ?", path); err != nil {
+		return "", err
+	}
+
+	pruneBackups(backupDir, maxBackups)
+	return path, nil
+}
+
+func pruneBackups(dir string, keep int) {
+	matches, err := filepath.Glob(filepath.Join(dir, "focusd-v*.db"))
+	if err != nil || len(matches) <= keep {
+		return
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, errA := os.Stat(matches[i])
+		b, errB := os.Stat(matches[j])
+		if errA != nil || errB != nil {
+			return matches[i] < matches[j]
+		}
+		return a.ModTime().Before(b.ModTime())
+	})
+	for _, path := range matches[:len(matches)-keep] {
+		os.Remove(path)
+	}
+}
@@ -0,0 +1,214 @@
+package storage
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func useTempDataDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("APPDATA", dir)
+
+	dataDir, err := GetDataDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dataDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	return dataDir
+}
+
+func openTestDB(t *testing.T) {
+	t.Helper()
+	if err := Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	t.Cleanup(func() {
+		Close()
+		db = nil
+	})
+}
+
+func writeRawDB(t *testing.T, statements ...string) {
+	t.Helper()
+	path, err := GetDBPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer raw.Close()
+	for _, stmt := range statements {
+		if _, err := raw.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+}
+
+func TestMigrateFreshDatabase(t *testing.T) {
+	dataDir := useTempDataDir(t)
+	openTestDB(t)
+
+	version, err := GetSchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != LatestSchemaVersion() {
+		t.Errorf("schema version = %d, want %d", version, LatestSchemaVersion())
+	}
+
+	backups, _ := filepath.Glob(filepath.Join(dataDir, "backups", "*.db"))
+	if len(backups) != 0 {
+		t.Errorf("fresh database made %d backups, want none", len(backups))
+	}
+}
+
+func TestMigrateUpgradesVersionOneDatabase(t *testing.T) {
+	dataDir := useTempDataDir(t)
+
+	writeRawDB(t,
+		`CREATE TABLE sessions (id INTEGER PRIMARY KEY AUTOINCREMENT, app_name TEXT NOT NULL, exe_name TEXT NOT NULL,
+			window_title TEXT, start_time INTEGER NOT NULL, end_time INTEGER, duration_secs INTEGER, date TEXT NOT NULL)`,
+		`CREATE TABLE apps_daily (id INTEGER PRIMARY KEY AUTOINCREMENT, date TEXT NOT NULL, app_name TEXT NOT NULL,
+			exe_name TEXT NOT NULL, total_duration_secs INTEGER DEFAULT 0, open_count INTEGER DEFAULT 0, UNIQUE(date, exe_name))`,
+		`CREATE TABLE active_session (id INTEGER PRIMARY KEY CHECK (id = 1), app_name TEXT NOT NULL, exe_name TEXT NOT NULL,
+			window_title TEXT, start_time INTEGER NOT NULL, last_seen INTEGER NOT NULL, date TEXT NOT NULL)`,
+		`CREATE TABLE browsing_daily (id INTEGER PRIMARY KEY AUTOINCREMENT, date TEXT NOT NULL, domain_or_title TEXT NOT NULL,
+			total_duration_secs INTEGER DEFAULT 0, open_count INTEGER DEFAULT 0, UNIQUE(date, domain_or_title))`,
+		`CREATE TABLE config (key TEXT PRIMARY KEY, value TEXT NOT NULL, updated_at INTEGER NOT NULL)`,
+		`CREATE TABLE schema_version (version INTEGER PRIMARY KEY, name TEXT NOT NULL, applied_at INTEGER NOT NULL)`,
+		`INSERT INTO schema_version VALUES (1, 'initial schema', 0)`,
+		`INSERT INTO apps_daily (date, app_name, exe_name, total_duration_secs, open_count) VALUES
+			('2026-03-10', 'Discord', 'Discord', 600, 2),
+			('2026-03-10', 'Discord', 'DISCORD', 300, 1)`,
+		`INSERT INTO sessions (app_name, exe_name, start_time, duration_secs, date) VALUES
+			('Discord', 'Discord', 0, 600, '2026-03-10')`,
+	)
+
+	openTestDB(t)
+
+	version, err := GetSchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != LatestSchemaVersion() {
+		t.Fatalf("schema version = %d, want %d", version, LatestSchemaVersion())
+	}
+
+	var rows, total, opens int
+	if err := db.QueryRow("SELECT COUNT(*), SUM(total_duration_secs), SUM(open_count) FROM apps_daily").Scan(&rows, &total, &opens); err != nil {
+		t.Fatal(err)
+	}
+	if rows != 1 || total != 900 || opens != 3 {
+		t.Errorf("apps_daily = %d rows, %ds, %d opens; want 1 row, 900s, 3 opens", rows, total, opens)
+	}
+
+	var exe string
+	if err := db.QueryRow("SELECT exe_name FROM sessions").Scan(&exe); err != nil {
+		t.Fatal(err)
+	}
+	if exe != strings.ToLower(exe) {
+		t.Errorf("session exe_name = %q, want it canonicalized", exe)
+	}
+
+	for _, table := range []string{"apps_weekly", "apps_monthly", "browsing_weekly", "browsing_monthly"} {
+		if _, err := db.Exec("SELECT COUNT(*) FROM " + table); err != nil {
+			t.Errorf("table %s missing after upgrade: %v", table, err)
+		}
+	}
+
+	backups, _ := filepath.Glob(filepath.Join(dataDir, "backups", "focusd-v1-*.db"))
+	if len(backups) == 0 {
+		t.Error("upgrade did not back up the version 1 database")
+	}
+}
+
+func TestMigrateRefusesNewerSchema(t *testing.T) {
+	useTempDataDir(t)
+	openTestDB(t)
+
+	newer := LatestSchemaVersion() + 1
+	if _, err := db.Exec("INSERT INTO schema_version (version, name, applied_at) VALUES (?, 'from the future', 0)", newer); err != nil {
+		t.Fatal(err)
+	}
+	Close()
+	db = nil
+
+	err := Init()
+	if err == nil {
+		Close()
+		t.Fatal("Init accepted a database from a newer focusd")
+	}
+	if !strings.Contains(err.Error(), "newer") {
+		t.Errorf("Init error = %q, want it to mention the newer schema", err)
+	}
+}
+
+func TestMigrateMovesAppIdentityIntoAppName(t *testing.T) {
+	useTempDataDir(t)
+
+	all := migrations
+	migrations = all[:5]
+	if err := Init(); err != nil {
+		migrations = all
+		t.Fatal(err)
+	}
+	migrations = all
+	for _, stmt := range []string{
+		`INSERT INTO apps_daily (date, app_name, exe_name, total_duration_secs, open_count) VALUES
+			('2026-03-10', 'Minecraft', 'minecraft@javaw', 600, 2),
+			('2026-03-10', 'Javaw', 'javaw', 300, 1)`,
+		`INSERT INTO apps_weekly (week_start, app_name, exe_name, total_duration_secs, open_count) VALUES
+			('2026-03-09', 'Minecraft', 'minecraft@javaw', 600, 2)`,
+		`INSERT INTO sessions (app_name, exe_name, start_time, duration_secs, date) VALUES
+			('Minecraft', 'minecraft@javaw', 0, 600, '2026-03-10')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	Close()
+	db = nil
+
+	openTestDB(t)
+
+	got := make(map[string]int)
+	rows, err := db.Query("SELECT exe_name, app_name, total_duration_secs FROM apps_daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var exe, app string
+		var secs int
+		if err := rows.Scan(&exe, &app, &secs); err != nil {
+			t.Fatal(err)
+		}
+		got[exe+"/"+app] = secs
+	}
+	if len(got) != 2 || got["javaw/Minecraft"] != 600 || got["javaw/Javaw"] != 300 {
+		t.Errorf("apps_daily = %v, want javaw/Minecraft 600s and javaw/Javaw 300s", got)
+	}
+
+	var exe string
+	if err := db.QueryRow("SELECT exe_name FROM sessions").Scan(&exe); err != nil {
+		t.Fatal(err)
+	}
+	if exe != "javaw" {
+		t.Errorf("session exe_name = %q, want javaw", exe)
+	}
+
+	if _, err := db.Exec(`INSERT INTO apps_weekly (week_start, app_name, exe_name, total_duration_secs, open_count)
+		VALUES ('2026-03-09', 'Javaw', 'javaw', 60, 1)`); err != nil {
+		t.Errorf("apps_weekly still keyed by exe alone: %v", err)
+	}
+}
@@ -15,7 +15,7 @@ type Repository interface {
 	GetBrowserStatsForDate(date string) ([]AppDailyStat, error)
 	SaveActiveSession(s *ActiveSessionRecord) error
 	ClearActiveSession() error
-	RecoverActiveSession() (*Session, error)
+	RecoverActiveSession() ([]*Session, error)
 	GetConfig(key string) (string, error)
 	SetConfig(key, value string) error
 	IsConsentGranted() bool
@@ -85,7 +85,7 @@ func (r *SQLiteRepository) ClearActiveSession() error {
 	return ClearActiveSession()
 }
 
-func (r *SQLiteRepository) RecoverActiveSession() (*Session, error) {
+func (r *SQLiteRepository) RecoverActiveSession() ([]*Session, error) {
 	return RecoverActiveSession()
 }
 
@@ -1,27 +1,131 @@
 package storage
 
 import (
+	"database/sql"
+	"fmt"
 	"time"
 )
 
+type rollupSpec struct {
+	table     string
+	periodCol string
+	periodSQL string
+	source    string
+	keyCol    string
+	appName   bool
+}
+
+var rollupSpecs = []rollupSpec{
+	{"apps_weekly", "week_start", weekStartSQL, "apps_daily", "exe_name", true},
+	{"apps_monthly", "month", monthSQL, "apps_daily", "exe_name", true},
+	{"browsing_weekly", "week_start", weekStartSQL, "browsing_daily", "domain_or_title", false},
+	{"browsing_monthly", "month", monthSQL, "browsing_daily", "domain_or_title", false},
+}
+
+const (
+	weekStartSQL = "date(date, 'weekday 0', '-6 days')"
+	monthSQL     = "substr(date, 1, 7)"
+)
+
 func EnforceRetention() error {
-	days := GetRetentionDays()
-	cutoff := time.Now().AddDate(0, 0, -days).Format("2006-01-02")
+	now := time.Now()
+	sessionCutoff := now.AddDate(0, 0, -GetRetentionDays()).Format("2006-01-02")
+	dailyCutoffTime := now.AddDate(0, 0, -GetDailyRetentionDays())
+	dailyCutoff := dailyCutoffTime.Format("2006-01-02")
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	if _, err := db.Exec("DELETE FROM sessions WHERE date < ?", cutoff); err != nil {
+	if err := updateRollups(tx, dailyCutoffTime); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM sessions WHERE date < ?", sessionCutoff); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM apps_daily WHERE date < ?", dailyCutoff); err != nil {
 		return err
 	}
-	if _, err := db.Exec("DELETE FROM apps_daily WHERE date < ?", cutoff); err != nil {
+	if _, err := tx.Exec("DELETE FROM browsing_daily WHERE date < ?", dailyCutoff); err != nil {
 		return err
 	}
-	if _, err := db.Exec("DELETE FROM browsing_daily WHERE date < ?", cutoff); err != nil {
+
+	if days := GetRollupRetentionDays(); days > 0 {
+		rollupCutoff := now.AddDate(0, 0, -days).Format("2006-01-02")
+		for _, spec := range rollupSpecs {
+			cutoff := rollupCutoff
+			if spec.periodCol == "month" {
+				cutoff = rollupCutoff[:7]
+			}
+			if _, err := tx.Exec("DELETE FROM "+spec.table+" WHERE "+spec.periodCol+" < ?", cutoff); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
 		return err
 	}
 
-	_, err := db.Exec("PRAGMA incremental_vacuum")
+	_, err = db.Exec("PRAGMA incremental_vacuum")
 	return err
 }
 
+func updateRollups(tx *sql.Tx, dailyCutoff time.Time) error {
+	for _, spec := range rollupSpecs {
+		nameCols, nameGroup := "", ""
+		if spec.appName {
+			nameCols, nameGroup = "app_name, ", ", app_name"
+		}
+
+		// Periods starting on or after the daily cutoff still have all of their
+		// days and are recomputed; older ones were finalized before pruning began
+		// and are only filled in when missing.
+		if _, err := tx.Exec("DELETE FROM "+spec.table+" WHERE "+spec.periodCol+" >= ?", spec.firstComplete(dailyCutoff)); err != nil {
+			return err
+		}
+
+		query := fmt.Sprintf(`
+			INSERT OR IGNORE INTO %s (%s, %s%s, total_duration_secs, open_count)
+			SELECT %s AS period, %s%s, SUM(total_duration_secs), SUM(open_count)
+			FROM %s
+			GROUP BY period, %s%s
+		`, spec.table, spec.periodCol, nameCols, spec.keyCol,
+			spec.periodSQL, nameCols, spec.keyCol,
+			spec.source, spec.keyCol, nameGroup)
+		if _, err := tx.Exec(query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (spec rollupSpec) firstComplete(cutoff time.Time) string {
+	y, m, d := cutoff.Date()
+	if spec.periodCol == "month" {
+		if d > 1 {
+			m++
+		}
+		return time.Date(y, m, 1, 0, 0, 0, 0, cutoff.Location()).Format("2006-01")
+	}
+	offset := (int(time.Monday) - int(cutoff.Weekday()) + 7) % 7
+	return time.Date(y, m, d+offset, 0, 0, 0, 0, cutoff.Location()).Format("2006-01-02")
+}
+
+func (spec rollupSpec) period(date string) string {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	if spec.periodCol == "month" {
+		return day.Format("2006-01")
+	}
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7).Format("2006-01-02")
+}
+
 func GetOldestDate() (string, error) {
 	var date string
 	err := db.QueryRow(`
@@ -42,57 +146,34 @@ func GetTotalSessionCount() (int, error) {
 }
 
 func ClearAllTrackingData() error {
-	if _, err := db.Exec("DELETE FROM sessions"); err != nil {
-		return err
+	tables := []string{"sessions", "apps_daily", "browsing_daily", "active_session"}
+	for _, spec := range rollupSpecs {
+		tables = append(tables, spec.table)
 	}
-	if _, err := db.Exec("DELETE FROM apps_daily"); err != nil {
-		return err
-	}
-
-	if _, err := db.Exec("DELETE FROM active_session"); err != nil {
-		return err
+	for _, table := range tables {
+		if _, err := db.Exec("DELETE FROM " + table); err != nil {
+			return err
+		}
 	}
 	_, err := db.Exec("VACUUM")
 	return err
 }
 
-func ClearTodayData() error {
-	today := Today()
-	if _, err := db.Exec("DELETE FROM sessions WHERE date = ?", today); err != nil {
-		return err
-	}
-	if _, err := db.Exec("DELETE FROM apps_daily WHERE date = ?", today); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func ClearLastHourData() error {
-	cutoff := time.Now().Add(-1 * time.Hour).Unix()
-	if _, err := db.Exec("DELETE FROM sessions WHERE start_time >= ?", cutoff); err != nil {
-		return err
-	}
-	return nil
-}
-
-func ClearLast24HoursData() error {
-	cutoff := time.Now().Add(-24 * time.Hour).Unix()
-	cutoffDate := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
-	if _, err := db.Exec("DELETE FROM sessions WHERE start_time >= ?", cutoff); err != nil {
-		return err
-	}
-	if _, err := db.Exec("DELETE FROM apps_daily WHERE date >= ?", cutoffDate); err != nil {
-		return err
+func GetSessionDates(startDate, endDate string) ([]string, error) {
+	whereClause, args := dateRangeClause(startDate, endDate)
+	rows, err := db.Query("SELECT DISTINCT date FROM sessions"+whereClause+" ORDER BY date", args...)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	return nil
-}
-
-func ClearLastNHoursData(hours int) error {
-	cutoff := time.Now().Add(-time.Duration(hours) * time.Hour).Unix()
-	if _, err := db.Exec("DELETE FROM sessions WHERE start_time >= ?", cutoff); err != nil {
-		return err
+	var dates []string
+	for rows.Next() {
+		var d string
+		if err := rows.Scan(&d); err != nil {
+			return nil, err
+		}
+		dates = append(dates, d)
 	}
-	return nil
+	return dates, rows.Err()
 }
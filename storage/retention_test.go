@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRollupFirstComplete(t *testing.T) {
+	weekly, monthly := rollupSpecs[0], rollupSpecs[1]
+	tests := []struct {
+		cutoff string
+		week   string
+		month  string
+	}{
+		{"2026-03-16", "2026-03-16", "2026-04"},
+		{"2026-03-15", "2026-03-16", "2026-04"},
+		{"2026-03-11", "2026-03-16", "2026-04"},
+		{"2026-03-01", "2026-03-02", "2026-03"},
+		{"2026-12-31", "2027-01-04", "2027-01"},
+		{"2026-12-01", "2026-12-07", "2026-12"},
+	}
+	for _, tt := range tests {
+		cutoff, err := time.Parse("2006-01-02", tt.cutoff)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := weekly.firstComplete(cutoff); got != tt.week {
+			t.Errorf("weekly firstComplete(%s) = %s, want %s", tt.cutoff, got, tt.week)
+		}
+		if got := monthly.firstComplete(cutoff); got != tt.month {
+			t.Errorf("monthly firstComplete(%s) = %s, want %s", tt.cutoff, got, tt.month)
+		}
+	}
+}
+
+func TestUpdateRollupsWeekBoundaries(t *testing.T) {
+	useTempDataDir(t)
+	openTestDB(t)
+
+	days := []struct {
+		date string
+		secs int
+	}{
+		{"2026-03-08", 100},
+		{"2026-03-09", 200},
+		{"2026-03-15", 300},
+		{"2026-03-16", 400},
+		{"2026-03-31", 500},
+		{"2026-04-01", 600},
+	}
+	for _, d := range days {
+		if _, err := db.Exec(
+			"INSERT INTO apps_daily (date, app_name, exe_name, total_duration_secs, open_count) VALUES (?, 'VS Code', 'code', ?, 1)",
+			d.date, d.secs,
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := db.Exec("INSERT INTO apps_weekly (week_start, app_name, exe_name, total_duration_secs, open_count) VALUES ('2026-02-23', 'VS Code', 'code', 999, 9)"); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cutoff := time.Date(2026, 3, 9, 0, 0, 0, 0, time.Local)
+	if err := updateRollups(tx, cutoff); err != nil {
+		tx.Rollback()
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	wantWeeks := map[string]int{
+		"2026-02-23": 999,
+		"2026-03-02": 100,
+		"2026-03-09": 500,
+		"2026-03-16": 400,
+		"2026-03-30": 1100,
+	}
+	gotWeeks := rollupTotals(t, "SELECT week_start, total_duration_secs FROM apps_weekly")
+	if len(gotWeeks) != len(wantWeeks) {
+		t.Errorf("weekly rollups = %v, want %v", gotWeeks, wantWeeks)
+	}
+	for week, secs := range wantWeeks {
+		if gotWeeks[week] != secs {
+			t.Errorf("week %s = %ds, want %ds", week, gotWeeks[week], secs)
+		}
+	}
+
+	wantMonths := map[string]int{"2026-03": 1500, "2026-04": 600}
+	gotMonths := rollupTotals(t, "SELECT month, total_duration_secs FROM apps_monthly")
+	for month, secs := range wantMonths {
+		if gotMonths[month] != secs {
+			t.Errorf("month %s = %ds, want %ds", month, gotMonths[month], secs)
+		}
+	}
+}
+
+func TestUpdateRollupsKeepsFinalizedPeriods(t *testing.T) {
+	useTempDataDir(t)
+	openTestDB(t)
+
+	if _, err := db.Exec("INSERT INTO apps_weekly (week_start, app_name, exe_name, total_duration_secs, open_count) VALUES ('2026-03-02', 'VS Code', 'code', 700, 7)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("INSERT INTO apps_daily (date, app_name, exe_name, total_duration_secs, open_count) VALUES ('2026-03-08', 'VS Code', 'code', 100, 1)"); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := updateRollups(tx, time.Date(2026, 3, 8, 0, 0, 0, 0, time.Local)); err != nil {
+		tx.Rollback()
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := rollupTotals(t, "SELECT week_start, total_duration_secs FROM apps_weekly")
+	if got["2026-03-02"] != 700 {
+		t.Errorf("finalized week = %ds, want it left at 700s", got["2026-03-02"])
+	}
+}
+
+func rollupTotals(t *testing.T, query string) map[string]int {
+	t.Helper()
+	rows, err := db.Query(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	totals := make(map[string]int)
+	for rows.Next() {
+		var period string
+		var secs int
+		if err := rows.Scan(&period, &secs); err != nil {
+			t.Fatal(err)
+		}
+		totals[period] = secs
+	}
+	return totals
+}
@@ -1,18 +1,54 @@
 package storage
 
 import (
+	"database/sql"
+	"focusd/system"
 	"time"
 )
 
 type Session struct {
-	ID           int64
-	AppName      string
-	ExeName      string
-	WindowTitle  string
-	StartTime    time.Time
-	EndTime      time.Time
-	DurationSecs int
-	Date         string
+	ID           int64     `json:"id"`
+	AppName      string    `json:"app_name"`
+	ExeName      string    `json:"exe_name"`
+	WindowTitle  string    `json:"window_title"`
+	Site         string    `json:"-"`
+	StartTime    time.Time `json:"start_time"`
+	EndTime      time.Time `json:"end_time"`
+	DurationSecs int       `json:"duration_secs"`
+	Date         string    `json:"date"`
+}
+
+func SplitSessionByDay(s *Session) []*Session {
+	if s.EndTime.IsZero() || !s.EndTime.After(s.StartTime) {
+		return []*Session{s}
+	}
+
+	var parts []*Session
+	cur := s.StartTime
+	for cur.Before(s.EndTime) {
+		y, m, d := cur.Date()
+		nextMidnight := time.Date(y, m, d+1, 0, 0, 0, 0, cur.Location())
+		segEnd := s.EndTime
+		if nextMidnight.Before(segEnd) {
+			segEnd = nextMidnight
+		}
+
+		duration := int(segEnd.Sub(cur).Seconds())
+		if duration >= 1 {
+			parts = append(parts, &Session{
+				AppName:      s.AppName,
+				ExeName:      s.ExeName,
+				WindowTitle:  s.WindowTitle,
+				Site:         s.Site,
+				StartTime:    cur,
+				EndTime:      segEnd,
+				DurationSecs: duration,
+				Date:         cur.Format("2006-01-02"),
+			})
+		}
+		cur = segEnd
+	}
+	return parts
 }
 
 func InsertSession(s *Session) error {
@@ -23,9 +59,9 @@ func InsertSession(s *Session) error {
 	}
 
 	_, err := db.Exec(`
-		INSERT INTO sessions (app_name, exe_name, window_title, start_time, end_time, duration_secs, date)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
-	`, s.AppName, s.ExeName, s.WindowTitle, s.StartTime.Unix(), endTime, s.DurationSecs, s.Date)
+		INSERT INTO sessions (app_name, exe_name, window_title, site, start_time, end_time, duration_secs, date)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, s.AppName, system.CanonicalExeName(s.ExeName), sealTitle(s.WindowTitle), sealTitle(s.Site), s.StartTime.Unix(), endTime, s.DurationSecs, s.Date)
 	return err
 }
 
@@ -41,8 +77,8 @@ func InsertSessionsBatch(sessions []*Session) error {
 	defer tx.Rollback()
 
 	stmt, err := tx.Prepare(`
-		INSERT INTO sessions (app_name, exe_name, window_title, start_time, end_time, duration_secs, date)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO sessions (app_name, exe_name, window_title, site, start_time, end_time, duration_secs, date)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return err
@@ -55,7 +91,7 @@ func InsertSessionsBatch(sessions []*Session) error {
 			t := s.EndTime.Unix()
 			endTime = &t
 		}
-		if _, err := stmt.Exec(s.AppName, s.ExeName, s.WindowTitle, s.StartTime.Unix(), endTime, s.DurationSecs, s.Date); err != nil {
+		if _, err := stmt.Exec(s.AppName, system.CanonicalExeName(s.ExeName), sealTitle(s.WindowTitle), sealTitle(s.Site), s.StartTime.Unix(), endTime, s.DurationSecs, s.Date); err != nil {
 			return err
 		}
 	}
@@ -67,10 +103,10 @@ func UpdateAppDaily(date, appName, exeName string, durationSecs int) error {
 	_, err := db.Exec(`
 		INSERT INTO apps_daily (date, app_name, exe_name, total_duration_secs, open_count)
 		VALUES (?, ?, ?, ?, 1)
-		ON CONFLICT(date, exe_name) DO UPDATE SET
+		ON CONFLICT(date, exe_name, app_name) DO UPDATE SET
 			total_duration_secs = total_duration_secs + excluded.total_duration_secs,
 			open_count = open_count + 1
-	`, date, appName, exeName, durationSecs)
+	`, date, appName, system.CanonicalExeName(exeName), durationSecs)
 	return err
 }
 
@@ -78,16 +114,16 @@ func IncrementAppOpenCount(date, exeName string) error {
 	_, err := db.Exec(`
 		UPDATE apps_daily SET open_count = open_count + 1
 		WHERE date = ? AND exe_name = ?
-	`, date, exeName)
+	`, date, system.CanonicalExeName(exeName))
 	return err
 }
 
 type AppDailyStat struct {
-	Date              string
-	AppName           string
-	ExeName           string
-	TotalDurationSecs int
-	OpenCount         int
+	Date              string `json:"date,omitempty"`
+	AppName           string `json:"app_name"`
+	ExeName           string `json:"exe_name,omitempty"`
+	TotalDurationSecs int    `json:"total_duration_secs"`
+	OpenCount         int    `json:"open_count"`
 }
 
 func GetAppStatsForDate(date string) ([]AppDailyStat, error) {
@@ -117,9 +153,9 @@ func GetAppUsageTodayMinutes(exeName string) int {
 	today := Today()
 	var secs int
 	err := db.QueryRow(`
-		SELECT COALESCE(total_duration_secs, 0) FROM apps_daily
+		SELECT COALESCE(SUM(total_duration_secs), 0) FROM apps_daily
 		WHERE date = ? AND exe_name = ?
-	`, today, exeName).Scan(&secs)
+	`, today, system.CanonicalExeName(exeName)).Scan(&secs)
 	if err != nil {
 		return 0
 	}
@@ -128,7 +164,7 @@ func GetAppUsageTodayMinutes(exeName string) int {
 
 func GetAllSessions() ([]Session, error) {
 	rows, err := db.Query(`
-		SELECT id, app_name, exe_name, window_title, start_time, end_time, duration_secs, date
+		SELECT id, app_name, exe_name, window_title, COALESCE(site, ''), start_time, end_time, duration_secs, date
 		FROM sessions
 		ORDER BY start_time DESC
 	`)
@@ -137,14 +173,20 @@ func GetAllSessions() ([]Session, error) {
 	}
 	defer rows.Close()
 
+	return scanSessions(rows)
+}
+
+func scanSessions(rows *sql.Rows) ([]Session, error) {
 	var sessions []Session
 	for rows.Next() {
 		var s Session
 		var startTime int64
 		var endTime *int64
-		if err := rows.Scan(&s.ID, &s.AppName, &s.ExeName, &s.WindowTitle, &startTime, &endTime, &s.DurationSecs, &s.Date); err != nil {
+		if err := rows.Scan(&s.ID, &s.AppName, &s.ExeName, &s.WindowTitle, &s.Site, &startTime, &endTime, &s.DurationSecs, &s.Date); err != nil {
 			return nil, err
 		}
+		s.WindowTitle = openTitle(s.WindowTitle)
+		s.Site = openTitle(s.Site)
 		s.StartTime = time.Unix(startTime, 0)
 		if endTime != nil {
 			s.EndTime = time.Unix(*endTime, 0)
@@ -157,23 +199,11 @@ func GetAllSessions() ([]Session, error) {
 func GetSessionsPaginated(limit, offset int, startDate, endDate string) ([]Session, int, error) {
 	countQuery := `SELECT COUNT(*) FROM sessions`
 	dataQuery := `
-		SELECT id, app_name, exe_name, window_title, start_time, end_time, duration_secs, date
+		SELECT id, app_name, exe_name, window_title, COALESCE(site, ''), start_time, end_time, duration_secs, date
 		FROM sessions
 	`
 
-	var args []interface{}
-	whereClause := ""
-
-	if startDate != "" && endDate != "" {
-		whereClause = " WHERE date >= ? AND date <= ?"
-		args = append(args, startDate, endDate)
-	} else if startDate != "" {
-		whereClause = " WHERE date >= ?"
-		args = append(args, startDate)
-	} else if endDate != "" {
-		whereClause = " WHERE date <= ?"
-		args = append(args, endDate)
-	}
+	whereClause, args := dateRangeClause(startDate, endDate)
 
 	var total int
 	err := db.QueryRow(countQuery+whereClause, args...).Scan(&total)
@@ -195,9 +225,11 @@ func GetSessionsPaginated(limit, offset int, startDate, endDate string) ([]Sessi
 		var s Session
 		var startTime int64
 		var endTime *int64
-		if err := rows.Scan(&s.ID, &s.AppName, &s.ExeName, &s.WindowTitle, &startTime, &endTime, &s.DurationSecs, &s.Date); err != nil {
+		if err := rows.Scan(&s.ID, &s.AppName, &s.ExeName, &s.WindowTitle, &s.Site, &startTime, &endTime, &s.DurationSecs, &s.Date); err != nil {
 			return nil, 0, err
 		}
+		s.WindowTitle = openTitle(s.WindowTitle)
+		s.Site = openTitle(s.Site)
 		s.StartTime = time.Unix(startTime, 0)
 		if endTime != nil {
 			s.EndTime = time.Unix(*endTime, 0)
@@ -207,6 +239,85 @@ func GetSessionsPaginated(limit, offset int, startDate, endDate string) ([]Sessi
 	return sessions, total, rows.Err()
 }
 
+func dateRangeClause(startDate, endDate string) (string, []interface{}) {
+	var args []interface{}
+	whereClause := ""
+
+	if startDate != "" && endDate != "" {
+		whereClause = " WHERE date >= ? AND date <= ?"
+		args = append(args, startDate, endDate)
+	} else if startDate != "" {
+		whereClause = " WHERE date >= ?"
+		args = append(args, startDate)
+	} else if endDate != "" {
+		whereClause = " WHERE date <= ?"
+		args = append(args, endDate)
+	}
+	return whereClause, args
+}
+
+func GetSessionsInRange(startDate, endDate string) ([]Session, error) {
+	whereClause, args := dateRangeClause(startDate, endDate)
+	rows, err := db.Query(`
+		SELECT id, app_name, exe_name, window_title, COALESCE(site, ''), start_time, end_time, duration_secs, date
+		FROM sessions`+whereClause+`
+		ORDER BY start_time ASC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSessions(rows)
+}
+
+func GetAppStatsInRange(startDate, endDate string) ([]AppDailyStat, error) {
+	whereClause, args := dateRangeClause(startDate, endDate)
+	rows, err := db.Query(`
+		SELECT date, app_name, exe_name, total_duration_secs, open_count
+		FROM apps_daily`+whereClause+`
+		ORDER BY date ASC, total_duration_secs DESC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []AppDailyStat
+	for rows.Next() {
+		var s AppDailyStat
+		if err := rows.Scan(&s.Date, &s.AppName, &s.ExeName, &s.TotalDurationSecs, &s.OpenCount); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+func GetBrowserStatsInRange(startDate, endDate string) ([]AppDailyStat, error) {
+	whereClause, args := dateRangeClause(startDate, endDate)
+	rows, err := db.Query(`
+		SELECT date, domain_or_title, '', total_duration_secs, open_count
+		FROM browsing_daily`+whereClause+`
+		ORDER BY date ASC, total_duration_secs DESC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []AppDailyStat
+	for rows.Next() {
+		var s AppDailyStat
+		if err := rows.Scan(&s.Date, &s.AppName, &s.ExeName, &s.TotalDurationSecs, &s.OpenCount); err != nil {
+			return nil, err
+		}
+		s.AppName = openTitle(s.AppName)
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
 func GetAllAppStats() ([]AppDailyStat, error) {
 	rows, err := db.Query(`
 		SELECT date, app_name, exe_name, total_duration_secs, open_count
@@ -246,6 +357,7 @@ func GetAllBrowserStats() ([]AppDailyStat, error) {
 		if err := rows.Scan(&s.Date, &s.AppName, &s.ExeName, &s.TotalDurationSecs, &s.OpenCount); err != nil {
 			return nil, err
 		}
+		s.AppName = openTitle(s.AppName)
 		stats = append(stats, s)
 	}
 	return stats, rows.Err()
@@ -258,7 +370,7 @@ func UpdateBrowserDaily(date, domainOrTitle string, durationSecs int) error {
 		ON CONFLICT(date, domain_or_title) DO UPDATE SET
 			total_duration_secs = total_duration_secs + excluded.total_duration_secs,
 			open_count = open_count + 1
-	`, date, domainOrTitle, durationSecs)
+	`, date, sealTitle(domainOrTitle), durationSecs)
 	return err
 }
 
@@ -280,6 +392,7 @@ func GetBrowserStatsForDate(date string) ([]AppDailyStat, error) {
 		if err := rows.Scan(&s.Date, &s.AppName, &s.ExeName, &s.TotalDurationSecs, &s.OpenCount); err != nil {
 			return nil, err
 		}
+		s.AppName = openTitle(s.AppName)
 		stats = append(stats, s)
 	}
 	return stats, rows.Err()
@@ -301,7 +414,7 @@ func SaveActiveSession(s *ActiveSessionRecord) error {
 	_, err := db.Exec(`
 		INSERT OR REPLACE INTO active_session (id, app_name, exe_name, window_title, start_time, last_seen, date)
 		VALUES (1, ?, ?, ?, ?, ?, ?)
-	`, s.AppName, s.ExeName, s.WindowTitle, s.StartTime.Unix(), s.LastSeen.Unix(), s.Date)
+	`, s.AppName, system.CanonicalExeName(s.ExeName), sealTitle(s.WindowTitle), s.StartTime.Unix(), s.LastSeen.Unix(), s.Date)
 	return err
 }
 
@@ -310,7 +423,7 @@ func ClearActiveSession() error {
 	return err
 }
 
-func RecoverActiveSession() (*Session, error) {
+func RecoverActiveSession() ([]*Session, error) {
 	var s ActiveSessionRecord
 	var startTime, lastSeen int64
 
@@ -330,13 +443,13 @@ func RecoverActiveSession() (*Session, error) {
 		return nil, nil
 	}
 
-	return &Session{
+	return SplitSessionByDay(&Session{
 		AppName:      s.AppName,
 		ExeName:      s.ExeName,
-		WindowTitle:  s.WindowTitle,
+		WindowTitle:  openTitle(s.WindowTitle),
 		StartTime:    time.Unix(startTime, 0),
 		EndTime:      time.Unix(lastSeen, 0),
 		DurationSecs: duration,
 		Date:         s.Date,
-	}, nil
+	}), nil
 }
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestSplitSessionByDay(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type part struct {
+		date string
+		secs int
+	}
+	tests := []struct {
+		name  string
+		start time.Time
+		end   time.Time
+		want  []part
+	}{
+		{
+			name:  "same day",
+			start: time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC),
+			end:   time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC),
+			want:  []part{{"2026-03-10", 3600}},
+		},
+		{
+			name:  "crosses midnight",
+			start: time.Date(2026, 3, 10, 23, 50, 0, 0, time.UTC),
+			end:   time.Date(2026, 3, 11, 0, 10, 0, 0, time.UTC),
+			want:  []part{{"2026-03-10", 600}, {"2026-03-11", 600}},
+		},
+		{
+			name:  "ends exactly at midnight",
+			start: time.Date(2026, 3, 10, 23, 0, 0, 0, time.UTC),
+			end:   time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC),
+			want:  []part{{"2026-03-10", 3600}},
+		},
+		{
+			name:  "spans a whole day",
+			start: time.Date(2026, 3, 10, 22, 0, 0, 0, time.UTC),
+			end:   time.Date(2026, 3, 12, 1, 0, 0, 0, time.UTC),
+			want:  []part{{"2026-03-10", 7200}, {"2026-03-11", 86400}, {"2026-03-12", 3600}},
+		},
+		{
+			name:  "spring forward day is 23 hours",
+			start: time.Date(2026, 3, 7, 23, 0, 0, 0, newYork),
+			end:   time.Date(2026, 3, 9, 1, 0, 0, 0, newYork),
+			want:  []part{{"2026-03-07", 3600}, {"2026-03-08", 23 * 3600}, {"2026-03-09", 3600}},
+		},
+		{
+			name:  "fall back day is 25 hours",
+			start: time.Date(2026, 10, 31, 23, 0, 0, 0, newYork),
+			end:   time.Date(2026, 11, 2, 1, 0, 0, 0, newYork),
+			want:  []part{{"2026-10-31", 3600}, {"2026-11-01", 25 * 3600}, {"2026-11-02", 3600}},
+		},
+		{
+			name:  "crosses midnight after a DST change",
+			start: time.Date(2026, 3, 8, 23, 30, 0, 0, newYork),
+			end:   time.Date(2026, 3, 9, 0, 30, 0, 0, newYork),
+			want:  []part{{"2026-03-08", 1800}, {"2026-03-09", 1800}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Session{
+				AppName:      "VS Code",
+				ExeName:      "code",
+				StartTime:    tt.start,
+				EndTime:      tt.end,
+				DurationSecs: int(tt.end.Sub(tt.start).Seconds()),
+				Date:         tt.start.Format("2006-01-02"),
+			}
+			parts := SplitSessionByDay(s)
+			if len(parts) != len(tt.want) {
+				t.Fatalf("got %d parts, want %d", len(parts), len(tt.want))
+			}
+
+			total := 0
+			for i, p := range parts {
+				if p.Date != tt.want[i].date || p.DurationSecs != tt.want[i].secs {
+					t.Errorf("part %d = %s/%ds, want %s/%ds", i, p.Date, p.DurationSecs, tt.want[i].date, tt.want[i].secs)
+				}
+				if i > 0 && !p.StartTime.Equal(parts[i-1].EndTime) {
+					t.Errorf("part %d starts at %s, previous ended at %s", i, p.StartTime, parts[i-1].EndTime)
+				}
+				total += p.DurationSecs
+			}
+			if total != s.DurationSecs {
+				t.Errorf("parts sum to %ds, want %ds", total, s.DurationSecs)
+			}
+			if !parts[0].StartTime.Equal(tt.start) || !parts[len(parts)-1].EndTime.Equal(tt.end) {
+				t.Errorf("parts span %s-%s, want %s-%s", parts[0].StartTime, parts[len(parts)-1].EndTime, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestInsertSessionKeepsSite(t *testing.T) {
+	useTempDataDir(t)
+	openTestDB(t)
+
+	start := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	if err := InsertSession(&Session{
+		AppName: "Chrome", ExeName: "chrome", WindowTitle: "#1f2e3d4c5b6a7988", Site: "Cats - YouTube",
+		StartTime: start, EndTime: start.Add(time.Minute), DurationSecs: 60, Date: "2026-03-10",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := GetAllSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].Site != "Cats - YouTube" {
+		t.Errorf("sessions = %+v, want the site kept alongside the hashed title", sessions)
+	}
+}
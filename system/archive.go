@@ -0,0 +1,76 @@
+package system
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+var archiveMagic = []byte("focusd-enc1\n")
+
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted archive")
+
+func IsSealedArchive(data []byte) bool {
+	return bytes.HasPrefix(data, archiveMagic)
+}
+
+func archiveCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func SealArchive(plaintext []byte, passphrase string) ([]byte, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := archiveCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := append([]byte(nil), archiveMagic...)
+	out = append(out, salt...)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plaintext, archiveMagic), nil
+}
+
+func OpenArchive(data []byte, passphrase string) ([]byte, error) {
+	if !IsSealedArchive(data) {
+		return nil, errors.New("not an encrypted focusd archive")
+	}
+	data = data[len(archiveMagic):]
+	if len(data) < saltLen {
+		return nil, ErrWrongPassphrase
+	}
+
+	aead, err := archiveCipher(passphrase, data[:saltLen])
+	if err != nil {
+		return nil, err
+	}
+	data = data[saltLen:]
+	if len(data) < aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], archiveMagic)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plaintext, nil
+}
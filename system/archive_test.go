@@ -0,0 +1,75 @@
+package system
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSealArchiveRoundTrip(t *testing.T) {
+	plaintext := []byte(`{"sessions":[{"app_name":"VS Code"}]}`)
+
+	sealed, err := SealArchive(plaintext, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsSealedArchive(sealed) {
+		t.Error("IsSealedArchive(sealed) = false")
+	}
+	if bytes.Contains(sealed, plaintext) {
+		t.Error("sealed archive contains the plaintext")
+	}
+
+	again, err := SealArchive(plaintext, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(again, sealed) {
+		t.Error("two archives of the same data are equal, want a fresh salt and nonce")
+	}
+
+	opened, err := OpenArchive(sealed, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("OpenArchive = %q, want %q", opened, plaintext)
+	}
+}
+
+func TestOpenArchiveRejectsWrongPassphraseAndTampering(t *testing.T) {
+	sealed, err := SealArchive([]byte("export data"), "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flip := func(i int) []byte {
+		data := bytes.Clone(sealed)
+		data[i] ^= 1
+		return data
+	}
+
+	tests := []struct {
+		name       string
+		data       []byte
+		passphrase string
+	}{
+		{"wrong passphrase", sealed, "battery staple"},
+		{"empty passphrase", sealed, ""},
+		{"flipped salt", flip(len(archiveMagic)), "correct horse"},
+		{"flipped nonce", flip(len(archiveMagic) + saltLen), "correct horse"},
+		{"flipped ciphertext", flip(len(sealed) - 1), "correct horse"},
+		{"truncated", sealed[:len(archiveMagic)+saltLen+4], "correct horse"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := OpenArchive(tt.data, tt.passphrase); !errors.Is(err, ErrWrongPassphrase) {
+				t.Errorf("OpenArchive error = %v, want ErrWrongPassphrase", err)
+			}
+		})
+	}
+
+	if _, err := OpenArchive([]byte("export data"), "correct horse"); err == nil {
+		t.Error("OpenArchive accepted data without the archive header")
+	}
+}
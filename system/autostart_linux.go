@@ -0,0 +1,137 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const startupDesktopName = "focusd.desktop"
+
+func GetStartupLinkPath() string {
+	configHome, err := getConfigHome()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configHome, "autostart", startupDesktopName)
+}
+
+func GetAutoStartEnabled() (bool, string, error) {
+	linkPath := GetStartupLinkPath()
+	if linkPath == "" {
+		return false, "", nil
+	}
+	if _, err := os.Stat(linkPath); err == nil {
+		return true, linkPath, nil
+	}
+	return false, "", nil
+}
+
+func EnableAutoStart() error {
+	launcherPath := GetLauncherPath()
+	if launcherPath == "" {
+		return fmt.Errorf("install directory not available")
+	}
+
+	if err := InstallExes(); err != nil {
+		return fmt.Errorf("failed to install: %w", err)
+	}
+
+	linkPath := GetStartupLinkPath()
+	if linkPath == "" {
+		return fmt.Errorf("autostart directory not found")
+	}
+	if err := os.MkdirAll(filepath.Dir(linkPath), 0755); err != nil {
+		return fmt.Errorf("failed to create autostart directory: %w", err)
+	}
+
+	desktopEntry := fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name=Focus Daemon
+Comment=Focus Daemon Background Process
+Exec="%s"
+Terminal=false
+NoDisplay=true
+X-GNOME-Autostart-enabled=true
+`, launcherPath)
+
+	if err := os.WriteFile(linkPath, []byte(desktopEntry), 0644); err != nil {
+		return fmt.Errorf("failed to create autostart entry: %w", err)
+	}
+	return nil
+}
+
+func DisableAutoStart() error {
+	linkPath := GetStartupLinkPath()
+	if linkPath != "" {
+		os.Remove(linkPath)
+	}
+	return nil
+}
+
+func getPathLinkPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return ""
+	}
+	return filepath.Join(home, ".local", "bin", ExecutableName)
+}
+
+func GetPathEnabled() (bool, error) {
+	linkPath := getPathLinkPath()
+	if linkPath == "" {
+		return false, nil
+	}
+	target, err := os.Readlink(linkPath)
+	if err != nil {
+		return false, nil
+	}
+	return target == GetInstalledExePath(), nil
+}
+
+func EnablePath() error {
+
+	if err := InstallExes(); err != nil {
+		return fmt.Errorf("failed to install/update binary: %w", err)
+	}
+
+	exePath := GetInstalledExePath()
+	linkPath := getPathLinkPath()
+	if exePath == "" || linkPath == "" {
+		return fmt.Errorf("install directory not available")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(linkPath), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(linkPath), err)
+	}
+
+	if target, err := os.Readlink(linkPath); err == nil {
+		if target == exePath {
+			return nil
+		}
+		os.Remove(linkPath)
+	} else if _, err := os.Lstat(linkPath); err == nil {
+		return fmt.Errorf("%s already exists and is not a focusd link", linkPath)
+	}
+
+	return os.Symlink(exePath, linkPath)
+}
+
+func DisablePath() error {
+	linkPath := getPathLinkPath()
+	if linkPath == "" {
+		return fmt.Errorf("install directory not available")
+	}
+
+	target, err := os.Readlink(linkPath)
+	if err != nil || target != GetInstalledExePath() {
+		return nil
+	}
+	return os.Remove(linkPath)
+}
+
+func CleanupRegistry() error {
+	DisableAutoStart()
+	DisablePath()
+	return nil
+}
@@ -0,0 +1,14 @@
+package system
+
+import "strings"
+
+func CanonicalExeName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	if name == "" || strings.HasPrefix(name, "(") {
+		return name
+	}
+	return strings.TrimSuffix(name, ".exe") + executableSuffix
+}
@@ -0,0 +1,28 @@
+package system
+
+import "testing"
+
+func TestCanonicalExeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"chrome", "chrome" + executableSuffix},
+		{"Chrome.EXE", "chrome" + executableSuffix},
+		{"  code  ", "code" + executableSuffix},
+		{`C:\Program Files\Google\Chrome\Application\chrome.exe`, "chrome" + executableSuffix},
+		{"/usr/lib/firefox/firefox", "firefox" + executableSuffix},
+		{"user@example", "user@example" + executableSuffix},
+		{"node@20", "node@20" + executableSuffix},
+		{"(idle)", "(idle)"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := CanonicalExeName(tt.name); got != tt.want {
+			t.Errorf("CanonicalExeName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+		if got := CanonicalExeName(tt.want); got != tt.want {
+			t.Errorf("CanonicalExeName(%q) = %q, want it unchanged", tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+package system
+
+import "time"
+
+type IdleSource interface {
+	LastInputTime() (time.Time, error)
+}
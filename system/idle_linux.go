@@ -0,0 +1,40 @@
+package system
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jezek/xgb/screensaver"
+	"github.com/jezek/xgb/xproto"
+)
+
+type X11IdleSource struct {
+	now func() time.Time
+}
+
+func NewIdleSource(now func() time.Time) (IdleSource, error) {
+	if _, _, err := x11.connect(); err != nil {
+		return nil, fmt.Errorf("idle detection unavailable: %w", err)
+	}
+	if !x11.hasScreenSaver() {
+		return nil, fmt.Errorf("idle detection unavailable: the X server has no MIT-SCREEN-SAVER extension")
+	}
+	return &X11IdleSource{now: now}, nil
+}
+
+func (s *X11IdleSource) LastInputTime() (time.Time, error) {
+	conn, root, err := x11.connect()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !x11.hasScreenSaver() {
+		return time.Time{}, fmt.Errorf("the X server has no MIT-SCREEN-SAVER extension")
+	}
+
+	info, err := screensaver.QueryInfo(conn, xproto.Drawable(root)).Reply()
+	if err != nil {
+		x11.drop(conn)
+		return time.Time{}, err
+	}
+	return s.now().Add(-time.Duration(info.MsSinceUserInput) * time.Millisecond), nil
+}
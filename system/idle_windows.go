@@ -0,0 +1,38 @@
+package system
+
+import (
+	"time"
+	"unsafe"
+)
+
+var (
+	procGetLastInputInfo = user32.NewProc("GetLastInputInfo")
+	procGetTickCount     = kernel32.NewProc("GetTickCount")
+)
+
+type LASTINPUTINFO struct {
+	Size uint32
+	Time uint32
+}
+
+type WindowsIdleSource struct {
+	now func() time.Time
+}
+
+func NewIdleSource(now func() time.Time) (IdleSource, error) {
+	return &WindowsIdleSource{now: now}, nil
+}
+
+func (s *WindowsIdleSource) LastInputTime() (time.Time, error) {
+	var info LASTINPUTINFO
+	info.Size = uint32(unsafe.Sizeof(info))
+
+	ret, _, err := procGetLastInputInfo.Call(uintptr(unsafe.Pointer(&info)))
+	if ret == 0 {
+		return time.Time{}, err
+	}
+
+	tick, _, _ := procGetTickCount.Call()
+	idleMs := uint32(tick) - info.Time
+	return s.now().Add(-time.Duration(idleMs) * time.Millisecond), nil
+}
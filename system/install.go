@@ -8,14 +8,12 @@ import (
 	"strings"
 )
 
-const DaemonProcessName = "focusd.exe"
-
 func GetInstallDir() string {
-	appData := os.Getenv("APPDATA")
-	if appData == "" {
+	dataDir, err := GetDataDir()
+	if err != nil {
 		return ""
 	}
-	return filepath.Join(appData, "focusd")
+	return dataDir
 }
 
 func GetInstalledExePath() string {
@@ -23,15 +21,7 @@ func GetInstalledExePath() string {
 	if installDir == "" {
 		return ""
 	}
-	return filepath.Join(installDir, "focusd.exe")
-}
-
-func GetLauncherPath() string {
-	installDir := GetInstallDir()
-	if installDir == "" {
-		return ""
-	}
-	return filepath.Join(installDir, "FocusDaemon.vbs")
+	return filepath.Join(installDir, ExecutableName)
 }
 
 func InstallExes() error {
@@ -44,12 +34,12 @@ func InstallExes() error {
 
 	installDir := GetInstallDir()
 	if installDir == "" {
-		return fmt.Errorf("APPDATA environment variable not set")
+		return fmt.Errorf("install directory not available")
 	}
 	if err := os.MkdirAll(installDir, 0755); err != nil {
 		return fmt.Errorf("failed to create install dir: %w", err)
 	}
-	dest := filepath.Join(installDir, "focusd.exe")
+	dest := filepath.Join(installDir, ExecutableName)
 	dest, _ = filepath.Abs(dest)
 
 	if strings.EqualFold(src, dest) {
@@ -58,19 +48,10 @@ func InstallExes() error {
 	}
 
 	if err := installFile(src, dest); err != nil {
-		return fmt.Errorf("failed to install focusd.exe: %w", err)
-	}
-
-	vbsContent := fmt.Sprintf(`Set WshShell = CreateObject("WScript.Shell")
-WshShell.Run """%s"" --daemon", 0, False
-`, dest)
-
-	vbsPath := filepath.Join(installDir, "FocusDaemon.vbs")
-	if err := os.WriteFile(vbsPath, []byte(vbsContent), 0644); err != nil {
-		return fmt.Errorf("failed to create VBS launcher: %w", err)
+		return fmt.Errorf("failed to install %s: %w", ExecutableName, err)
 	}
 
-	return nil
+	return writeLauncher(dest)
 }
 
 func installFile(src, dst string) error {
@@ -95,7 +76,7 @@ func copyFile(src, dst string) error {
 	}
 	defer srcFile.Close()
 
-	dstFile, err := os.Create(dst)
+	dstFile, err := os.OpenFile(dst, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0755)
 	if err != nil {
 		return err
 	}
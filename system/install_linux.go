@@ -0,0 +1,32 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	DaemonProcessName = "focusd"
+	ExecutableName    = "focusd"
+	ReleaseAssetName  = "focusd-linux-amd64"
+)
+
+func GetLauncherPath() string {
+	installDir := GetInstallDir()
+	if installDir == "" {
+		return ""
+	}
+	return filepath.Join(installDir, "focusd-daemon.sh")
+}
+
+func writeLauncher(exePath string) error {
+	script := fmt.Sprintf(`#!/bin/sh
+exec "%s" --daemon >/dev/null 2>&1
+`, exePath)
+
+	if err := os.WriteFile(GetLauncherPath(), []byte(script), 0755); err != nil {
+		return fmt.Errorf("failed to create launcher script: %w", err)
+	}
+	return nil
+}
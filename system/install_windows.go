@@ -0,0 +1,32 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	DaemonProcessName = "focusd.exe"
+	ExecutableName    = "focusd.exe"
+	ReleaseAssetName  = "focusd.exe"
+)
+
+func GetLauncherPath() string {
+	installDir := GetInstallDir()
+	if installDir == "" {
+		return ""
+	}
+	return filepath.Join(installDir, "FocusDaemon.vbs")
+}
+
+func writeLauncher(exePath string) error {
+	vbsContent := fmt.Sprintf(`Set WshShell = CreateObject("WScript.Shell")
+WshShell.Run """%s"" --daemon", 0, False
+`, exePath)
+
+	if err := os.WriteFile(GetLauncherPath(), []byte(vbsContent), 0644); err != nil {
+		return fmt.Errorf("failed to create VBS launcher: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package system
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+var ErrKeyNotFound = errors.New("key not found")
+
+type Keystore interface {
+	Get(name string) ([]byte, error)
+	Set(name string, secret []byte) error
+	Delete(name string) error
+}
+
+type fileKeystore struct{}
+
+func DefaultKeystore() Keystore {
+	return fileKeystore{}
+}
+
+func keyPath(name string) (string, error) {
+	dataDir, err := GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "keys", name+".key"), nil
+}
+
+func (fileKeystore) Get(name string) ([]byte, error) {
+	path, err := keyPath(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return unprotectSecret(data)
+}
+
+func (fileKeystore) Set(name string, secret []byte) error {
+	path, err := keyPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := protectSecret(secret)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func (fileKeystore) Delete(name string) error {
+	path, err := keyPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package system
+
+// Linux has no per-user secret store that works without a desktop session,
+// so the key file is only protected by its permissions. It guards copies of
+// focusd.db and its backups, not the data directory itself.
+const KeystoreEncryptsKey = false
+
+func protectSecret(secret []byte) ([]byte, error) {
+	return append([]byte(nil), secret...), nil
+}
+
+func unprotectSecret(data []byte) ([]byte, error) {
+	return data, nil
+}
+
+func KeystoreDescription() string {
+	return "unencrypted key file in the data directory (owner-only permissions)"
+}
@@ -0,0 +1,42 @@
+package system
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const KeystoreEncryptsKey = true
+
+func protectSecret(secret []byte) ([]byte, error) {
+	return dpapi(secret, true)
+}
+
+func unprotectSecret(data []byte) ([]byte, error) {
+	return dpapi(data, false)
+}
+
+func dpapi(data []byte, protect bool) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	in := windows.DataBlob{Size: uint32(len(data)), Data: &data[0]}
+	var out windows.DataBlob
+	var err error
+	if protect {
+		err = windows.CryptProtectData(&in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out)
+	} else {
+		err = windows.CryptUnprotectData(&in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+
+	return append([]byte(nil), unsafe.Slice(out.Data, out.Size)...), nil
+}
+
+func KeystoreDescription() string {
+	return "key file in the data directory, protected with Windows DPAPI"
+}
@@ -0,0 +1,23 @@
+package system
+
+import (
+	"os/exec"
+)
+
+func ShowMessage(title, message string) {
+	exec.Command("notify-send", "--app-name=focusd", title, message).Run()
+}
+
+func ShowConfirm(title, message string) bool {
+	if _, err := exec.LookPath("zenity"); err != nil {
+		ShowMessage(title, message)
+		return false
+	}
+
+	err := exec.Command("zenity", "--question",
+		"--title="+title,
+		"--text="+message,
+		"--ok-label=Disable this reminder",
+		"--cancel-label=Just close").Run()
+	return err == nil
+}
@@ -0,0 +1,45 @@
+package system
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var procMessageBoxW = user32.NewProc("MessageBoxW")
+
+const (
+	MB_OK              = 0x00000000
+	MB_OKCANCEL        = 0x00000001
+	MB_YESNO           = 0x00000004
+	MB_ICONINFORMATION = 0x00000040
+	MB_ICONWARNING     = 0x00000030
+	MB_SYSTEMMODAL     = 0x00001000
+	MB_SETFOREGROUND   = 0x00010000
+	IDOK               = 1
+	IDYES              = 6
+	IDNO               = 7
+)
+
+func ShowMessage(title, message string) {
+	titlePtr, _ := syscall.UTF16PtrFromString(title)
+	messagePtr, _ := syscall.UTF16PtrFromString(message)
+	procMessageBoxW.Call(
+		0,
+		uintptr(unsafe.Pointer(messagePtr)),
+		uintptr(unsafe.Pointer(titlePtr)),
+		uintptr(MB_OK|MB_ICONINFORMATION|MB_SETFOREGROUND),
+	)
+}
+
+func ShowConfirm(title, message string) bool {
+	titlePtr, _ := syscall.UTF16PtrFromString(title)
+	fullMessage := message + "\n\n[OK] Disable this reminder\n[Cancel] Just close"
+	messagePtr, _ := syscall.UTF16PtrFromString(fullMessage)
+	ret, _, _ := procMessageBoxW.Call(
+		0,
+		uintptr(unsafe.Pointer(messagePtr)),
+		uintptr(unsafe.Pointer(titlePtr)),
+		uintptr(MB_OKCANCEL|MB_ICONWARNING|MB_SETFOREGROUND),
+	)
+	return ret == IDOK
+}
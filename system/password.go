@@ -0,0 +1,104 @@
+package system
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+	saltLen      = 16
+
+	passwordFreeAttempts = 3
+	passwordBaseLockout  = 30 * time.Second
+	passwordMaxLockout   = 1 * time.Hour
+
+	recoveryCodeCount = 8
+	recoveryCodeBytes = 5
+)
+
+func hashPassword(password string) (string, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key, err := scrypt.Key([]byte(password), salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("scrypt$%d$%d$%d$%s$%s", scryptN, scryptR, scryptP,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func verifyPasswordHash(password, encoded string) bool {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "scrypt" {
+		return false
+	}
+
+	n, errN := strconv.Atoi(parts[1])
+	r, errR := strconv.Atoi(parts[2])
+	p, errP := strconv.Atoi(parts[3])
+	if errN != nil || errR != nil || errP != nil {
+		return false
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false
+	}
+
+	got, err := scrypt.Key([]byte(password), salt, n, r, p, len(want))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+func PasswordLockoutFor(failures int) time.Duration {
+	if failures < passwordFreeAttempts {
+		return 0
+	}
+	lockout := passwordBaseLockout << (failures - passwordFreeAttempts)
+	if lockout > passwordMaxLockout || lockout <= 0 {
+		return passwordMaxLockout
+	}
+	return lockout
+}
+
+func newRecoveryCode() (string, error) {
+	buf := make([]byte, recoveryCodeBytes*2)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	enc := base32.StdEncoding.WithPadding(base32.NoPadding)
+	return enc.EncodeToString(buf[:recoveryCodeBytes]) + "-" + enc.EncodeToString(buf[recoveryCodeBytes:]), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, code)
+}
@@ -0,0 +1,88 @@
+package system
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPasswordHashVerify(t *testing.T) {
+	hash, err := hashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "scrypt$32768$8$1$") {
+		t.Errorf("hash = %q, want scrypt parameters in the prefix", hash)
+	}
+
+	again, err := hashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again == hash {
+		t.Error("two hashes of the same password are equal, want distinct salts")
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+	}{
+		{"correct password", "correct horse", hash, true},
+		{"second hash", "correct horse", again, true},
+		{"wrong password", "correct horse ", hash, false},
+		{"empty password", "", hash, false},
+		{"empty hash", "correct horse", "", false},
+		{"plaintext stored", "correct horse", "correct horse", false},
+		{"wrong scheme", "correct horse", strings.Replace(hash, "scrypt", "bcrypt", 1), false},
+		{"bad cost", "correct horse", strings.Replace(hash, "$32768$", "$abc$", 1), false},
+		{"truncated", "correct horse", hash[:strings.LastIndex(hash, "$")], false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyPasswordHash(tt.password, tt.encoded); got != tt.want {
+				t.Errorf("verifyPasswordHash(%q) = %v, want %v", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordLockoutFor(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{1, 0},
+		{2, 0},
+		{3, 30 * time.Second},
+		{4, time.Minute},
+		{5, 2 * time.Minute},
+		{9, 32 * time.Minute},
+		{10, time.Hour},
+		{11, time.Hour},
+		{100, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := PasswordLockoutFor(tt.failures); got != tt.want {
+			t.Errorf("PasswordLockoutFor(%d) = %s, want %s", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestRecoveryCodeNormalization(t *testing.T) {
+	code, err := newRecoveryCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := hashPassword(normalizeRecoveryCode(code))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	typed := strings.ToLower(strings.Replace(code, "-", " ", 1))
+	if !verifyPasswordHash(normalizeRecoveryCode(typed), hash) {
+		t.Errorf("recovery code %q typed as %q did not verify", code, typed)
+	}
+}
@@ -0,0 +1,31 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func GetDataDir() (string, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil || home == "" {
+			return "", fmt.Errorf("neither XDG_DATA_HOME nor HOME environment variable is set")
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataHome, "focusd"), nil
+}
+
+func getConfigHome() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome != "" {
+		return configHome, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return "", fmt.Errorf("neither XDG_CONFIG_HOME nor HOME environment variable is set")
+	}
+	return filepath.Join(home, ".config"), nil
+}
@@ -0,0 +1,15 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func GetDataDir() (string, error) {
+	appData := os.Getenv("APPDATA")
+	if appData == "" {
+		return "", fmt.Errorf("APPDATA environment variable not set")
+	}
+	return filepath.Join(appData, "focusd"), nil
+}
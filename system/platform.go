@@ -0,0 +1,82 @@
+package system
+
+import "time"
+
+type WindowInfo struct {
+	Title   string
+	ExeName string
+	ExePath string
+	PID     uint32
+}
+
+type Platform interface {
+	ForegroundWindow() (*WindowInfo, error)
+	NewIdleSource(now func() time.Time) (IdleSource, error)
+	ProcessCount(name string) int
+	KillProcess(name string) error
+	Notify(title, message string)
+	Confirm(title, message string) bool
+	AutoStartEnabled() (bool, string, error)
+	EnableAutoStart() error
+	DisableAutoStart() error
+	DataDir() (string, error)
+	InstallDir() string
+}
+
+type nativePlatform struct{}
+
+func (nativePlatform) ForegroundWindow() (*WindowInfo, error) {
+	return GetForegroundWindowInfo()
+}
+
+func (nativePlatform) NewIdleSource(now func() time.Time) (IdleSource, error) {
+	return NewIdleSource(now)
+}
+
+func (nativePlatform) ProcessCount(name string) int {
+	return GetProcessCount(name)
+}
+
+func (nativePlatform) KillProcess(name string) error {
+	return KillProcess(name)
+}
+
+func (nativePlatform) Notify(title, message string) {
+	ShowMessage(title, message)
+}
+
+func (nativePlatform) Confirm(title, message string) bool {
+	return ShowConfirm(title, message)
+}
+
+func (nativePlatform) AutoStartEnabled() (bool, string, error) {
+	return GetAutoStartEnabled()
+}
+
+func (nativePlatform) EnableAutoStart() error {
+	return EnableAutoStart()
+}
+
+func (nativePlatform) DisableAutoStart() error {
+	return DisableAutoStart()
+}
+
+func (nativePlatform) DataDir() (string, error) {
+	return GetDataDir()
+}
+
+func (nativePlatform) InstallDir() string {
+	return GetInstallDir()
+}
+
+var _ Platform = nativePlatform{}
+
+var currentPlatform Platform = nativePlatform{}
+
+func GetPlatform() Platform {
+	return currentPlatform
+}
+
+func SetPlatform(p Platform) {
+	currentPlatform = p
+}
@@ -0,0 +1,153 @@
+package system
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	TitleModeFull     = "full"
+	TitleModeRedacted = "redacted"
+	TitleModeHashed   = "hashed"
+	TitleModeNone     = "none"
+
+	maxTitlePrivacyHistory = 20
+)
+
+var TitleModes = []string{TitleModeFull, TitleModeRedacted, TitleModeHashed, TitleModeNone}
+
+var RedactionPresets = map[string]string{
+	"email":    `[\w.+-]+@[\w-]+(\.[\w-]+)+`,
+	"number":   `\d{3,}`,
+	"order-id": `(?i)\b(order|invoice|ticket|case)\s*(no\.?|#)?\s*[a-z0-9-]*\d[a-z0-9-]*`,
+}
+
+type TitlePrivacyChange struct {
+	Mode      string    `json:"mode"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+func compileRedactions(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", p, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+func isTitleMode(mode string) bool {
+	for _, m := range TitleModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+func GetTitlePrivacyMode() string {
+	mode := loadUserConfig().TitlePrivacyMode
+	if !isTitleMode(mode) {
+		return TitleModeFull
+	}
+	return mode
+}
+
+func SetTitlePrivacyMode(mode string) error {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if !isTitleMode(mode) {
+		return fmt.Errorf("unknown title mode %q (use %s)", mode, strings.Join(TitleModes, ", "))
+	}
+	if mode == GetTitlePrivacyMode() {
+		return nil
+	}
+
+	return updateUserConfig(func(config *UserConfig) error {
+		config.TitlePrivacyMode = mode
+		config.TitlePrivacyHistory = append(config.TitlePrivacyHistory, TitlePrivacyChange{
+			Mode:      mode,
+			ChangedAt: time.Now(),
+		})
+		if n := len(config.TitlePrivacyHistory); n > maxTitlePrivacyHistory {
+			config.TitlePrivacyHistory = config.TitlePrivacyHistory[n-maxTitlePrivacyHistory:]
+		}
+		return nil
+	})
+}
+
+func GetTitlePrivacyHistory() []TitlePrivacyChange {
+	return loadUserConfig().TitlePrivacyHistory
+}
+
+func GetTitleRedactions() []string {
+	return loadUserConfig().TitleRedactions
+}
+
+func GetTitleRedactionPatterns() []*regexp.Regexp {
+	return loadUserConfig().redactions
+}
+
+func AddTitleRedaction(pattern string) (string, error) {
+	if preset, ok := RedactionPresets[strings.ToLower(strings.TrimSpace(pattern))]; ok {
+		pattern = preset
+	}
+	if strings.TrimSpace(pattern) == "" {
+		return "", fmt.Errorf("pattern cannot be empty")
+	}
+
+	err := updateUserConfig(func(config *UserConfig) error {
+		for _, p := range config.TitleRedactions {
+			if p == pattern {
+				return errConfigUnchanged
+			}
+		}
+
+		patterns := append(config.TitleRedactions, pattern)
+		compiled, err := compileRedactions(patterns)
+		if err != nil {
+			return err
+		}
+		config.TitleRedactions = patterns
+		config.redactions = compiled
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return pattern, nil
+}
+
+func RemoveTitleRedaction(pattern string) (bool, error) {
+	if preset, ok := RedactionPresets[strings.ToLower(strings.TrimSpace(pattern))]; ok {
+		pattern = preset
+	}
+
+	removed := false
+	err := updateUserConfig(func(config *UserConfig) error {
+		var updated []string
+		for _, p := range config.TitleRedactions {
+			if p == pattern {
+				removed = true
+				continue
+			}
+			updated = append(updated, p)
+		}
+		if !removed {
+			return errConfigUnchanged
+		}
+
+		compiled, err := compileRedactions(updated)
+		if err != nil {
+			return err
+		}
+		config.TitleRedactions = updated
+		config.redactions = compiled
+		return nil
+	})
+	return removed && err == nil, err
+}
@@ -0,0 +1,115 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const executableSuffix = ""
+
+func iterateProcesses(callback func(pid uint32, name string) bool) error {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		pid, err := strconv.ParseUint(entry.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		name := getProcessName(uint32(pid))
+		if name == "" {
+			continue
+		}
+		if !callback(uint32(pid), name) {
+			break
+		}
+	}
+	return nil
+}
+
+func IsProcessRunning(name string) bool {
+	found := false
+	iterateProcesses(func(pid uint32, procName string) bool {
+		if strings.EqualFold(procName, name) {
+			found = true
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+func GetProcessCount(name string) int {
+	count := 0
+	iterateProcesses(func(pid uint32, procName string) bool {
+		if strings.EqualFold(procName, name) {
+			count++
+		}
+		return true
+	})
+	return count
+}
+
+func GetPIDByName(name string) (uint32, error) {
+	var pid uint32
+	iterateProcesses(func(p uint32, procName string) bool {
+		if strings.EqualFold(procName, name) {
+			pid = p
+			return false
+		}
+		return true
+	})
+	return pid, nil
+}
+
+func terminateProcessByPID(pid uint32) error {
+	if err := syscall.Kill(int(pid), syscall.SIGTERM); err != nil {
+		return fmt.Errorf("failed to terminate process %d: %w", pid, err)
+	}
+	return nil
+}
+
+func KillProcess(name string) error {
+	var lastErr error
+	iterateProcesses(func(pid uint32, procName string) bool {
+		if strings.EqualFold(procName, name) {
+			if err := terminateProcessByPID(pid); err != nil {
+				lastErr = err
+			}
+		}
+		return true
+	})
+	return lastErr
+}
+
+func KillOtherInstances(name string) error {
+	myPID := uint32(os.Getpid())
+	var lastErr error
+	iterateProcesses(func(pid uint32, procName string) bool {
+		if strings.EqualFold(procName, name) && pid != myPID {
+			if err := terminateProcessByPID(pid); err != nil {
+				lastErr = err
+			}
+		}
+		return true
+	})
+	return lastErr
+}
+
+func DetachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
+
+func OpenURL(url string) error {
+	return exec.Command("xdg-open", url).Start()
+}
+
+func getMyPID() int {
+	return os.Getpid()
+}
@@ -3,6 +3,7 @@ package system
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"strings"
 	"syscall"
 	"unsafe"
@@ -14,6 +15,8 @@ const (
 	PROCESS_TERMINATE  = 0x0001
 )
 
+const executableSuffix = ".exe"
+
 type PROCESSENTRY32W struct {
 	Size              uint32
 	CntUsage          uint32
@@ -149,6 +152,16 @@ func KillOtherInstances(name string) error {
 	return lastErr
 }
 
+func DetachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | 0x00000008,
+	}
+}
+
+func OpenURL(url string) error {
+	return exec.Command("cmd", "/c", "start", url).Start()
+}
+
 func getMyPID() int {
 	return os.Getpid()
 }
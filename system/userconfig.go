@@ -2,78 +2,263 @@ package system
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 )
 
 type UserConfig struct {
 	WhitelistApps         []string       `json:"whitelist_apps"`
+	WhitelistSites        []string       `json:"whitelist_sites"`
 	BreakReminderEnabled  bool           `json:"break_reminder_enabled"`
 	BreakReminderMinutes  int            `json:"break_reminder_minutes"`
 	AppTimeLimits         map[string]int `json:"app_time_limits"`
+	SiteTimeLimits        map[string]int `json:"site_time_limits"`
+	CategoryTimeLimits    map[string]int `json:"category_time_limits"`
 	PomodoroMinutes       int            `json:"pomodoro_minutes"`
-	Password              string         `json:"password"`
+	Password              string         `json:"password,omitempty"`
+	PasswordHash          string         `json:"password_hash,omitempty"`
+	RecoveryCodes         []string       `json:"recovery_codes,omitempty"`
+	NeedsRecoveryCodes    bool           `json:"needs_recovery_codes,omitempty"`
+	LimitWarningPercent   int            `json:"limit_warning_percent"`
+	LimitWarningMinutes   int            `json:"limit_warning_minutes"`
 	SnoozeDurationMinutes int            `json:"snooze_duration_minutes"`
+	IdleThresholdMinutes  int            `json:"idle_threshold_minutes"`
+	RecordIdleSessions    bool           `json:"record_idle_sessions"`
+	APIEnabled            bool           `json:"api_enabled"`
+	APIPort               int            `json:"api_port"`
+
+	TitlePrivacyMode    string               `json:"title_privacy_mode"`
+	TitleRedactions     []string             `json:"title_redactions"`
+	TitlePrivacyHistory []TitlePrivacyChange `json:"title_privacy_history,omitempty"`
+
+	redactions        []*regexp.Regexp
+	plaintextPassword bool
 }
 
-var userConfig *UserConfig
+const DefaultAPIPort = 7878
 
-func getUserConfigPath() (string, error) {
-	appData := os.Getenv("APPDATA")
-	if appData == "" {
-		return "", nil
-	}
-	return filepath.Join(appData, "focusd", "config.json"), nil
+const (
+	LimitKindApp      = "app"
+	LimitKindSite     = "site"
+	LimitKindCategory = "category"
+)
+
+type LimitTarget struct {
+	Kind string
+	Name string
 }
 
-func loadUserConfig() *UserConfig {
-	if userConfig != nil {
-		return userConfig
-	}
+type TimeLimit struct {
+	Target  LimitTarget
+	Minutes int
+}
+
+var (
+	userConfig    *UserConfig
+	userConfigErr error
+	userConfigMu  sync.RWMutex
 
-	userConfig = &UserConfig{
+	errConfigUnchanged = errors.New("config unchanged")
+)
 
+func defaultUserConfig() *UserConfig {
+	return &UserConfig{
 		WhitelistApps:         []string{},
+		WhitelistSites:        []string{},
 		BreakReminderEnabled:  false,
 		BreakReminderMinutes:  60,
 		AppTimeLimits:         make(map[string]int),
+		SiteTimeLimits:        make(map[string]int),
+		CategoryTimeLimits:    make(map[string]int),
 		PomodoroMinutes:       25,
 		Password:              "",
+		LimitWarningPercent:   80,
+		LimitWarningMinutes:   5,
 		SnoozeDurationMinutes: 60,
+		IdleThresholdMinutes:  5,
+		RecordIdleSessions:    false,
+		APIEnabled:            false,
+		APIPort:               DefaultAPIPort,
+		TitlePrivacyMode:      TitleModeFull,
+		TitleRedactions:       []string{},
 	}
+}
 
-	configPath, err := getUserConfigPath()
+func GetUserConfigPath() (string, error) {
+	dataDir, err := GetDataDir()
+	if err != nil {
+		return "", nil
+	}
+	return filepath.Join(dataDir, "config.json"), nil
+}
+
+func readUserConfig() (*UserConfig, error) {
+	config := defaultUserConfig()
+
+	configPath, err := GetUserConfigPath()
 	if err != nil || configPath == "" {
-		return userConfig
+		return config, err
 	}
 
 	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
 	if err != nil {
-		return userConfig
+		return nil, err
 	}
 
-	json.Unmarshal(data, userConfig)
-	if userConfig.AppTimeLimits == nil {
-		userConfig.AppTimeLimits = make(map[string]int)
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", filepath.Base(configPath), err)
 	}
-	return userConfig
+	canonicalizeExeNames(config)
+	if config.SiteTimeLimits == nil {
+		config.SiteTimeLimits = make(map[string]int)
+	}
+	if config.CategoryTimeLimits == nil {
+		config.CategoryTimeLimits = make(map[string]int)
+	}
+	if config.TitlePrivacyMode != "" && !isTitleMode(config.TitlePrivacyMode) {
+		return nil, fmt.Errorf("invalid %s: unknown title_privacy_mode %q", filepath.Base(configPath), config.TitlePrivacyMode)
+	}
+	if config.redactions, err = compileRedactions(config.TitleRedactions); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", filepath.Base(configPath), err)
+	}
+
+	if config.Password != "" {
+		hash, err := hashPassword(config.Password)
+		if err != nil {
+			return nil, err
+		}
+		config.PasswordHash = hash
+		config.Password = ""
+		config.NeedsRecoveryCodes = len(config.RecoveryCodes) == 0
+		config.plaintextPassword = true
+	}
+	return config, nil
+}
+
+func canonicalizeExeNames(config *UserConfig) {
+	limits := make(map[string]int, len(config.AppTimeLimits))
+	for exe, minutes := range config.AppTimeLimits {
+		exe = CanonicalExeName(exe)
+		if exe != "" && minutes > limits[exe] {
+			limits[exe] = minutes
+		}
+	}
+	config.AppTimeLimits = limits
+
+	apps := []string{}
+	seen := make(map[string]bool)
+	for _, exe := range config.WhitelistApps {
+		exe = CanonicalExeName(exe)
+		if exe != "" && !seen[exe] {
+			seen[exe] = true
+			apps = append(apps, exe)
+		}
+	}
+	config.WhitelistApps = apps
+}
+
+func (c *UserConfig) clone() *UserConfig {
+	copied := *c
+	copied.WhitelistApps = slices.Clone(c.WhitelistApps)
+	copied.WhitelistSites = slices.Clone(c.WhitelistSites)
+	copied.AppTimeLimits = cloneLimits(c.AppTimeLimits)
+	copied.SiteTimeLimits = cloneLimits(c.SiteTimeLimits)
+	copied.CategoryTimeLimits = cloneLimits(c.CategoryTimeLimits)
+	copied.RecoveryCodes = slices.Clone(c.RecoveryCodes)
+	copied.TitleRedactions = slices.Clone(c.TitleRedactions)
+	copied.TitlePrivacyHistory = slices.Clone(c.TitlePrivacyHistory)
+	copied.plaintextPassword = false
+	return &copied
+}
+
+func cloneLimits(limits map[string]int) map[string]int {
+	if limits == nil {
+		return make(map[string]int)
+	}
+	return maps.Clone(limits)
 }
 
-func SaveUserConfig() error {
+func loadUserConfigLocked() *UserConfig {
 	if userConfig == nil {
-		userConfig = &UserConfig{
+		config, err := readUserConfig()
+		if err != nil {
+			config = defaultUserConfig()
+		}
+		userConfig, userConfigErr = config, err
+	}
+	return userConfig
+}
+
+// loadUserConfig returns the current snapshot. Snapshots are never modified
+// after they are published; updateUserConfig swaps in a changed copy.
+func loadUserConfig() *UserConfig {
+	userConfigMu.RLock()
+	config := userConfig
+	userConfigMu.RUnlock()
+	if config != nil {
+		return config
+	}
+
+	userConfigMu.Lock()
+	defer userConfigMu.Unlock()
+	return loadUserConfigLocked()
+}
+
+func updateUserConfig(update func(config *UserConfig) error) error {
+	userConfigMu.Lock()
+	defer userConfigMu.Unlock()
+
+	current := loadUserConfigLocked()
+	if userConfigErr != nil {
+		return fmt.Errorf("%v; fix or remove the file before changing settings", userConfigErr)
+	}
 
-			WhitelistApps:        []string{},
-			BreakReminderEnabled: false,
-			BreakReminderMinutes: 60,
-			AppTimeLimits:        make(map[string]int),
-			PomodoroMinutes:      25,
-			Password:             "",
+	config := current.clone()
+	if err := update(config); err != nil {
+		if errors.Is(err, errConfigUnchanged) {
+			return nil
 		}
+		return err
+	}
+	if err := writeUserConfig(config); err != nil {
+		return err
 	}
+	userConfig = config
+	return nil
+}
+
+// UserConfigError reports why config.json could not be loaded, if it could not.
+func UserConfigError() error {
+	loadUserConfig()
+	userConfigMu.RLock()
+	defer userConfigMu.RUnlock()
+	return userConfigErr
+}
+
+// MigrateUserConfig rewrites config.json when loading it converted a
+// plaintext password to a hash, so the plaintext does not stay on disk.
+func MigrateUserConfig() error {
+	if !loadUserConfig().plaintextPassword {
+		return nil
+	}
+	return updateUserConfig(func(config *UserConfig) error {
+		return nil
+	})
+}
 
-	configPath, err := getUserConfigPath()
+func writeUserConfig(config *UserConfig) error {
+	configPath, err := GetUserConfigPath()
 	if err != nil || configPath == "" {
 		return err
 	}
@@ -81,7 +266,7 @@ func SaveUserConfig() error {
 	dataDir := filepath.Dir(configPath)
 	os.MkdirAll(dataDir, 0700)
 
-	data, err := json.MarshalIndent(userConfig, "", "  ")
+	data, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
 		return err
 	}
@@ -100,55 +285,92 @@ func GetWhitelistApps() []string {
 }
 
 func AddWhitelistApp(exeName string) error {
-	config := loadUserConfig()
-
-	exeName = strings.ToLower(strings.TrimSpace(exeName))
+	exeName = CanonicalExeName(exeName)
 	if exeName == "" {
 		return nil
 	}
 
-	if !strings.HasSuffix(exeName, ".exe") {
-		exeName += ".exe"
-	}
-
-	for _, a := range config.WhitelistApps {
-		if strings.EqualFold(a, exeName) {
-			return nil
+	return updateUserConfig(func(config *UserConfig) error {
+		for _, a := range config.WhitelistApps {
+			if a == exeName {
+				return errConfigUnchanged
+			}
 		}
-	}
-
-	config.WhitelistApps = append(config.WhitelistApps, exeName)
-	return SaveUserConfig()
+		config.WhitelistApps = append(config.WhitelistApps, exeName)
+		return nil
+	})
 }
 
 func RemoveWhitelistApp(exeName string) error {
-	config := loadUserConfig()
-
-	var updated []string
-	for _, a := range config.WhitelistApps {
-		if !strings.EqualFold(a, exeName) {
-			updated = append(updated, a)
+	exeName = CanonicalExeName(exeName)
+	return updateUserConfig(func(config *UserConfig) error {
+		var updated []string
+		for _, a := range config.WhitelistApps {
+			if a != exeName {
+				updated = append(updated, a)
+			}
 		}
-	}
-	config.WhitelistApps = updated
-	return SaveUserConfig()
+		config.WhitelistApps = updated
+		return nil
+	})
 }
 
 func IsWhitelisted(exeName string) bool {
 	config := loadUserConfig()
 
-	exeName = strings.ToLower(exeName)
+	exeName = CanonicalExeName(exeName)
 	for _, a := range config.WhitelistApps {
-		if strings.EqualFold(a, exeName) {
+		if a == exeName {
 			return true
 		}
 	}
 	return false
 }
 
-func ReloadUserConfig() {
-	userConfig = nil
-	loadUserConfig()
+func GetWhitelistSites() []string {
+	return loadUserConfig().WhitelistSites
+}
+
+func AddWhitelistSite(pattern string) error {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return nil
+	}
+
+	return updateUserConfig(func(config *UserConfig) error {
+		for _, p := range config.WhitelistSites {
+			if strings.EqualFold(p, pattern) {
+				return errConfigUnchanged
+			}
+		}
+		config.WhitelistSites = append(config.WhitelistSites, pattern)
+		return nil
+	})
+}
+
+func RemoveWhitelistSite(pattern string) error {
+	return updateUserConfig(func(config *UserConfig) error {
+		var updated []string
+		for _, p := range config.WhitelistSites {
+			if !strings.EqualFold(p, pattern) {
+				updated = append(updated, p)
+			}
+		}
+		config.WhitelistSites = updated
+		return nil
+	})
+}
+
+func ReloadUserConfig() error {
+	config, err := readUserConfig()
+	if err != nil {
+		return err
+	}
+
+	userConfigMu.Lock()
+	userConfig, userConfigErr = config, nil
+	userConfigMu.Unlock()
+	return nil
 }
 
 func GetBreakReminderEnabled() bool {
@@ -164,12 +386,13 @@ func GetBreakReminderMinutes() int {
 }
 
 func SetBreakReminder(enabled bool, minutes int) error {
-	config := loadUserConfig()
-	config.BreakReminderEnabled = enabled
-	if minutes > 0 {
-		config.BreakReminderMinutes = minutes
-	}
-	return SaveUserConfig()
+	return updateUserConfig(func(config *UserConfig) error {
+		config.BreakReminderEnabled = enabled
+		if minutes > 0 {
+			config.BreakReminderMinutes = minutes
+		}
+		return nil
+	})
 }
 
 func GetAppTimeLimits() map[string]int {
@@ -177,24 +400,119 @@ func GetAppTimeLimits() map[string]int {
 }
 
 func SetAppTimeLimit(exeName string, minutes int) error {
-	config := loadUserConfig()
-	exeName = strings.ToLower(strings.TrimSpace(exeName))
-	if !strings.HasSuffix(exeName, ".exe") {
-		exeName += ".exe"
+	exeName = CanonicalExeName(exeName)
+	return updateUserConfig(func(config *UserConfig) error {
+		if minutes <= 0 {
+			delete(config.AppTimeLimits, exeName)
+		} else {
+			config.AppTimeLimits[exeName] = minutes
+		}
+		return nil
+	})
+}
+
+func RemoveAppTimeLimit(exeName string) error {
+	return SetAppTimeLimit(exeName, 0)
+}
+
+func ParseLimitTarget(target string) LimitTarget {
+	target = strings.TrimSpace(target)
+	if kind, name, ok := strings.Cut(target, ":"); ok {
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		if kind == LimitKindSite || kind == LimitKindCategory {
+			return LimitTarget{Kind: kind, Name: strings.TrimSpace(name)}
+		}
 	}
-	if minutes <= 0 {
-		delete(config.AppTimeLimits, exeName)
-	} else {
-		config.AppTimeLimits[exeName] = minutes
+	return LimitTarget{Kind: LimitKindApp, Name: CanonicalExeName(target)}
+}
+
+func (t LimitTarget) String() string {
+	if t.Kind == LimitKindApp {
+		return t.Name
 	}
-	return SaveUserConfig()
+	return t.Kind + ":" + t.Name
 }
 
-func RemoveAppTimeLimit(exeName string) error {
+func GetTimeLimits() []TimeLimit {
 	config := loadUserConfig()
-	exeName = strings.ToLower(strings.TrimSpace(exeName))
-	delete(config.AppTimeLimits, exeName)
-	return SaveUserConfig()
+
+	var limits []TimeLimit
+	add := func(kind string, m map[string]int) {
+		for name, minutes := range m {
+			limits = append(limits, TimeLimit{Target: LimitTarget{Kind: kind, Name: name}, Minutes: minutes})
+		}
+	}
+	add(LimitKindApp, config.AppTimeLimits)
+	add(LimitKindSite, config.SiteTimeLimits)
+	add(LimitKindCategory, config.CategoryTimeLimits)
+
+	sort.Slice(limits, func(i, j int) bool {
+		if limits[i].Target.Kind != limits[j].Target.Kind {
+			return limits[i].Target.Kind < limits[j].Target.Kind
+		}
+		return strings.ToLower(limits[i].Target.Name) < strings.ToLower(limits[j].Target.Name)
+	})
+	return limits
+}
+
+func SetTimeLimit(target LimitTarget, minutes int) error {
+	if target.Name == "" {
+		return fmt.Errorf("limit target cannot be empty")
+	}
+
+	switch target.Kind {
+	case LimitKindApp:
+		return SetAppTimeLimit(target.Name, minutes)
+	case LimitKindSite, LimitKindCategory:
+	default:
+		return fmt.Errorf("unknown limit kind %q", target.Kind)
+	}
+
+	return updateUserConfig(func(config *UserConfig) error {
+		limits := config.SiteTimeLimits
+		if target.Kind == LimitKindCategory {
+			limits = config.CategoryTimeLimits
+		}
+		for name := range limits {
+			if strings.EqualFold(name, target.Name) {
+				delete(limits, name)
+			}
+		}
+		if minutes > 0 {
+			limits[target.Name] = minutes
+		}
+		return nil
+	})
+}
+
+func GetLimitWarningPercent() int {
+	pct := loadUserConfig().LimitWarningPercent
+	if pct < 0 || pct >= 100 {
+		return 0
+	}
+	return pct
+}
+
+func GetLimitWarningMinutes() int {
+	mins := loadUserConfig().LimitWarningMinutes
+	if mins < 0 {
+		return 0
+	}
+	return mins
+}
+
+func SetLimitWarnings(percent, minutes int) error {
+	if percent < 0 || percent >= 100 {
+		return fmt.Errorf("warning percent must be between 0 and 99")
+	}
+	if minutes < 0 {
+		return fmt.Errorf("warning minutes cannot be negative")
+	}
+	return updateUserConfig(func(config *UserConfig) error {
+		config.LimitWarningPercent = percent
+		config.LimitWarningMinutes = minutes
+		return nil
+	})
 }
 
 func GetPomodoroMinutes() int {
@@ -206,33 +524,116 @@ func GetPomodoroMinutes() int {
 }
 
 func SetPomodoroMinutes(minutes int) error {
-	config := loadUserConfig()
-	config.PomodoroMinutes = minutes
-	return SaveUserConfig()
-}
-
-func GetPassword() string {
-	return loadUserConfig().Password
+	return updateUserConfig(func(config *UserConfig) error {
+		config.PomodoroMinutes = minutes
+		return nil
+	})
 }
 
 func SetPassword(password string) error {
-	config := loadUserConfig()
-	config.Password = password
-	return SaveUserConfig()
+	hash := ""
+	if password != "" {
+		var err error
+		if hash, err = hashPassword(password); err != nil {
+			return err
+		}
+	}
+
+	return updateUserConfig(func(config *UserConfig) error {
+		config.PasswordHash = hash
+		if hash == "" {
+			config.RecoveryCodes = nil
+			config.NeedsRecoveryCodes = false
+		}
+		config.Password = ""
+		return nil
+	})
 }
 
+// IsPasswordEnabled reports true when config.json cannot be read, since the
+// password it may hold is unknown.
 func IsPasswordEnabled() bool {
-	return loadUserConfig().Password != ""
+	return UserConfigError() != nil || loadUserConfig().PasswordHash != ""
 }
 
 func CheckPassword(input string) bool {
-	return loadUserConfig().Password == input
+	if UserConfigError() != nil {
+		return false
+	}
+	config := loadUserConfig()
+	return config.PasswordHash == "" || verifyPasswordHash(input, config.PasswordHash)
+}
+
+// NeedsRecoveryCodes reports a password migrated from plaintext that has not
+// been given recovery codes yet.
+func NeedsRecoveryCodes() bool {
+	config := loadUserConfig()
+	return config.NeedsRecoveryCodes && config.PasswordHash != ""
 }
 
 func ClearPassword() error {
+	return SetPassword("")
+}
+
+func GenerateRecoveryCodes() ([]string, error) {
 	config := loadUserConfig()
-	config.Password = ""
-	return SaveUserConfig()
+	if config.PasswordHash == "" {
+		return nil, fmt.Errorf("no password is set")
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		hash, err := hashPassword(normalizeRecoveryCode(code))
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		hashes[i] = hash
+	}
+
+	err := updateUserConfig(func(config *UserConfig) error {
+		if config.PasswordHash == "" {
+			return fmt.Errorf("no password is set")
+		}
+		config.RecoveryCodes = hashes
+		config.NeedsRecoveryCodes = false
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func RecoveryCodesRemaining() int {
+	return len(loadUserConfig().RecoveryCodes)
+}
+
+func UseRecoveryCode(input string) bool {
+	code := normalizeRecoveryCode(input)
+	if code == "" {
+		return false
+	}
+
+	for _, hash := range loadUserConfig().RecoveryCodes {
+		if verifyPasswordHash(code, hash) {
+			err := updateUserConfig(func(config *UserConfig) error {
+				i := slices.Index(config.RecoveryCodes, hash)
+				if i < 0 {
+					return fmt.Errorf("recovery code was already used")
+				}
+				config.RecoveryCodes = slices.Delete(config.RecoveryCodes, i, i+1)
+				return nil
+			})
+			return err == nil
+		}
+	}
+	return false
 }
 
 func GetSnoozeDurationMinutes() int {
@@ -244,7 +645,56 @@ func GetSnoozeDurationMinutes() int {
 }
 
 func SetSnoozeDurationMinutes(minutes int) error {
-	config := loadUserConfig()
-	config.SnoozeDurationMinutes = minutes
-	return SaveUserConfig()
+	return updateUserConfig(func(config *UserConfig) error {
+		config.SnoozeDurationMinutes = minutes
+		return nil
+	})
+}
+
+func GetIdleThresholdMinutes() int {
+	mins := loadUserConfig().IdleThresholdMinutes
+	if mins < 1 {
+		return 5
+	}
+	return mins
+}
+
+func SetIdleThresholdMinutes(minutes int) error {
+	return updateUserConfig(func(config *UserConfig) error {
+		config.IdleThresholdMinutes = minutes
+		return nil
+	})
+}
+
+func GetRecordIdleSessions() bool {
+	return loadUserConfig().RecordIdleSessions
+}
+
+func SetRecordIdleSessions(enabled bool) error {
+	return updateUserConfig(func(config *UserConfig) error {
+		config.RecordIdleSessions = enabled
+		return nil
+	})
+}
+
+func GetAPIEnabled() bool {
+	return loadUserConfig().APIEnabled
+}
+
+func GetAPIPort() int {
+	port := loadUserConfig().APIPort
+	if port < 1 || port > 65535 {
+		return DefaultAPIPort
+	}
+	return port
+}
+
+func SetAPIEnabled(enabled bool, port int) error {
+	return updateUserConfig(func(config *UserConfig) error {
+		config.APIEnabled = enabled
+		if port > 0 {
+			config.APIPort = port
+		}
+		return nil
+	})
 }
@@ -0,0 +1,130 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func useTempUserConfig(t *testing.T, contents string) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("APPDATA", dir)
+
+	path, err := GetUserConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contents != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resetUserConfig := func() {
+		userConfigMu.Lock()
+		userConfig, userConfigErr = nil, nil
+		userConfigMu.Unlock()
+	}
+	resetUserConfig()
+	t.Cleanup(resetUserConfig)
+	return path
+}
+
+func TestUserConfigRefusesToOverwriteInvalidFile(t *testing.T) {
+	broken := `{"whitelist_apps": ["code"],`
+	path := useTempUserConfig(t, broken)
+
+	if UserConfigError() == nil {
+		t.Fatal("UserConfigError() = nil for an unparseable config.json")
+	}
+	if err := SetPomodoroMinutes(50); err == nil {
+		t.Error("SetPomodoroMinutes saved over an unparseable config.json")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != broken {
+		t.Errorf("config.json = %q, want it left as %q", data, broken)
+	}
+}
+
+func TestUserConfigMigratesPlaintextPasswordOnlyWhenAsked(t *testing.T) {
+	path := useTempUserConfig(t, `{"password": "hunter2"}`)
+
+	if !IsPasswordEnabled() {
+		t.Fatal("plaintext password was not loaded")
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "hunter2") {
+		t.Fatal("loading config.json rewrote it")
+	}
+
+	if err := MigrateUserConfig(); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(path)
+	if strings.Contains(string(data), "hunter2") || !strings.Contains(string(data), "password_hash") {
+		t.Errorf("migrated config.json = %s, want only a password hash", data)
+	}
+	if !CheckPassword("hunter2") {
+		t.Error("migrated password no longer verifies")
+	}
+	if !NeedsRecoveryCodes() {
+		t.Error("migrated password does not ask for recovery codes")
+	}
+
+	if _, err := GenerateRecoveryCodes(); err != nil {
+		t.Fatal(err)
+	}
+	if NeedsRecoveryCodes() || RecoveryCodesRemaining() == 0 {
+		t.Errorf("after generating codes NeedsRecoveryCodes() = %v, remaining = %d", NeedsRecoveryCodes(), RecoveryCodesRemaining())
+	}
+}
+
+func TestPasswordFailsClosedOnInvalidConfig(t *testing.T) {
+	useTempUserConfig(t, `{"password_hash": "scrypt$`)
+
+	if !IsPasswordEnabled() {
+		t.Error("IsPasswordEnabled() = false for an unreadable config.json")
+	}
+	for _, input := range []string{"", "anything"} {
+		if CheckPassword(input) {
+			t.Errorf("CheckPassword(%q) = true for an unreadable config.json", input)
+		}
+	}
+}
+
+func TestUserConfigConcurrentUpdates(t *testing.T) {
+	useTempUserConfig(t, "")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			if err := AddWhitelistSite(strings.Repeat("x", i+1) + ".com"); err != nil {
+				t.Error(err)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			IsWhitelisted("code")
+			GetTimeLimits()
+		}()
+	}
+	wg.Wait()
+
+	if got := len(GetWhitelistSites()); got != 8 {
+		t.Errorf("whitelisted %d sites, want 8", got)
+	}
+}
@@ -0,0 +1,68 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jezek/xgb/xproto"
+)
+
+func GetForegroundWindowInfo() (*WindowInfo, error) {
+	conn, root, err := x11.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := x11.property(conn, root, "_NET_ACTIVE_WINDOW", xproto.AtomWindow)
+	if err != nil {
+		x11.drop(conn)
+		return nil, fmt.Errorf("failed to query _NET_ACTIVE_WINDOW: %w", err)
+	}
+	window := windowFromProperty(active)
+	if window == 0 {
+		return nil, nil
+	}
+
+	// The window can close between the two queries; that is not an error.
+	title := ""
+	if utf8Atom, err := x11.atom(conn, "UTF8_STRING"); err == nil {
+		if reply, err := x11.property(conn, window, "_NET_WM_NAME", utf8Atom); err == nil {
+			title = textFromProperty(reply)
+		}
+	}
+	if title == "" {
+		if reply, err := xproto.GetProperty(conn, false, window, xproto.AtomWmName, xproto.GetPropertyTypeAny, 0, 1024).Reply(); err == nil {
+			title = textFromProperty(reply)
+		}
+	}
+	if title == "" {
+		return nil, nil
+	}
+
+	var pid uint32
+	if reply, err := x11.property(conn, window, "_NET_WM_PID", xproto.AtomCardinal); err == nil {
+		pid = cardinalFromProperty(reply)
+	}
+
+	exeName, exePath := "", ""
+	if pid != 0 {
+		exeName = getProcessName(pid)
+		exePath, _ = os.Readlink(fmt.Sprintf("/proc/%d/exe", pid))
+	}
+
+	return &WindowInfo{
+		Title:   title,
+		ExeName: exeName,
+		ExePath: exePath,
+		PID:     pid,
+	}, nil
+}
+
+func getProcessName(pid uint32) string {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -16,18 +16,15 @@ var (
 	procOpenProcess              = kernel32.NewProc("OpenProcess")
 	procCloseHandle              = kernel32.NewProc("CloseHandle")
 	procGetModuleBaseNameW       = psapi.NewProc("GetModuleBaseNameW")
+	procQueryFullProcessImageW   = kernel32.NewProc("QueryFullProcessImageNameW")
 )
 
 const (
 	PROCESS_QUERY_INFORMATION = 0x0400
 	PROCESS_VM_READ           = 0x0010
-)
 
-type WindowInfo struct {
-	Title   string
-	ExeName string
-	PID     uint32
-}
+	PROCESS_QUERY_LIMITED_INFORMATION = 0x1000
+)
 
 func GetForegroundWindowInfo() (*WindowInfo, error) {
 	hwnd, _, _ := procGetForegroundWindow.Call()
@@ -43,14 +40,16 @@ func GetForegroundWindowInfo() (*WindowInfo, error) {
 	var pid uint32
 	procGetWindowThreadProcessId.Call(hwnd, uintptr(unsafe.Pointer(&pid)))
 
-	exeName := ""
+	exeName, exePath := "", ""
 	if pid != 0 {
 		exeName = getProcessName(pid)
+		exePath = getProcessPath(pid)
 	}
 
 	return &WindowInfo{
 		Title:   title,
 		ExeName: exeName,
+		ExePath: exePath,
 		PID:     pid,
 	}, nil
 }
@@ -89,3 +88,24 @@ func getProcessName(pid uint32) string {
 	}
 	return syscall.UTF16ToString(buf)
 }
+
+func getProcessPath(pid uint32) string {
+	handle, _, _ := procOpenProcess.Call(PROCESS_QUERY_LIMITED_INFORMATION, 0, uintptr(pid))
+	if handle == 0 {
+		return ""
+	}
+	defer procCloseHandle.Call(handle)
+
+	buf := make([]uint16, 1024)
+	size := uint32(len(buf))
+	ret, _, _ := procQueryFullProcessImageW.Call(
+		handle,
+		0,
+		uintptr(unsafe.Pointer(&buf[0])),
+		uintptr(unsafe.Pointer(&size)),
+	)
+	if ret == 0 {
+		return ""
+	}
+	return syscall.UTF16ToString(buf[:size])
+}
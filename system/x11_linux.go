@@ -0,0 +1,125 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/screensaver"
+	"github.com/jezek/xgb/xproto"
+)
+
+var errNoDisplay = errors.New("no X11 display: DISPLAY is not set")
+
+// x11Display keeps one connection to the X server for window and idle
+// queries, so polling does not start a process every second. Atoms are
+// interned once per connection.
+type x11Display struct {
+	mu    sync.Mutex
+	conn  *xgb.Conn
+	root  xproto.Window
+	atoms map[string]xproto.Atom
+	saver bool
+}
+
+var x11 x11Display
+
+func (d *x11Display) connect() (*xgb.Conn, xproto.Window, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.conn != nil {
+		return d.conn, d.root, nil
+	}
+	if os.Getenv("DISPLAY") == "" {
+		return nil, 0, errNoDisplay
+	}
+
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to connect to the X server: %w", err)
+	}
+	d.conn = conn
+	d.root = xproto.Setup(conn).DefaultScreen(conn).Root
+	d.atoms = make(map[string]xproto.Atom)
+	d.saver = screensaver.Init(conn) == nil
+	return d.conn, d.root, nil
+}
+
+// drop forgets a connection after a failed request so the next poll dials
+// again, e.g. after the X server restarted.
+func (d *x11Display) drop(conn *xgb.Conn) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if conn != nil && d.conn == conn {
+		d.conn.Close()
+		d.conn = nil
+	}
+}
+
+func (d *x11Display) atom(conn *xgb.Conn, name string) (xproto.Atom, error) {
+	d.mu.Lock()
+	atom, ok := d.atoms[name]
+	d.mu.Unlock()
+	if ok {
+		return atom, nil
+	}
+
+	reply, err := xproto.InternAtom(conn, false, uint16(len(name)), name).Reply()
+	if err != nil {
+		return 0, err
+	}
+	d.mu.Lock()
+	d.atoms[name] = reply.Atom
+	d.mu.Unlock()
+	return reply.Atom, nil
+}
+
+func (d *x11Display) property(conn *xgb.Conn, window xproto.Window, name string, typ xproto.Atom) (*xproto.GetPropertyReply, error) {
+	atom, err := d.atom(conn, name)
+	if err != nil {
+		return nil, err
+	}
+	return xproto.GetProperty(conn, false, window, atom, typ, 0, 1024).Reply()
+}
+
+func (d *x11Display) hasScreenSaver() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.saver
+}
+
+func windowFromProperty(reply *xproto.GetPropertyReply) xproto.Window {
+	return xproto.Window(cardinalFromProperty(reply))
+}
+
+func cardinalFromProperty(reply *xproto.GetPropertyReply) uint32 {
+	if reply == nil || reply.Format != 32 || len(reply.Value) < 4 {
+		return 0
+	}
+	return xgb.Get32(reply.Value)
+}
+
+// textFromProperty decodes _NET_WM_NAME (UTF-8) and the older WM_NAME, which
+// is Latin-1 when its type is STRING.
+func textFromProperty(reply *xproto.GetPropertyReply) string {
+	if reply == nil || reply.Format != 8 || len(reply.Value) == 0 {
+		return ""
+	}
+	value := reply.Value[:min(int(reply.ValueLen), len(reply.Value))]
+	if reply.Type == xproto.AtomString {
+		runes := make([]rune, len(value))
+		for i, b := range value {
+			runes[i] = rune(b)
+		}
+		return strings.TrimRight(string(runes), "\x00")
+	}
+	text := strings.TrimRight(string(value), "\x00")
+	if !utf8.ValidString(text) {
+		return strings.ToValidUTF8(text, "�")
+	}
+	return text
+}
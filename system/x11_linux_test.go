@@ -0,0 +1,96 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jezek/xgb/xproto"
+)
+
+func property(format byte, typ xproto.Atom, value []byte) *xproto.GetPropertyReply {
+	n := uint32(len(value))
+	if format == 32 {
+		n /= 4
+	}
+	return &xproto.GetPropertyReply{Format: format, Type: typ, ValueLen: n, Value: value}
+}
+
+func TestCardinalFromProperty(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply *xproto.GetPropertyReply
+		want  uint32
+	}{
+		{"window id", property(32, xproto.AtomWindow, []byte{0x05, 0x00, 0xc0, 0x03}), 0x03c00005},
+		{"pid", property(32, xproto.AtomCardinal, []byte{0x39, 0x30, 0x00, 0x00}), 12345},
+		{"no active window", property(32, xproto.AtomWindow, []byte{0, 0, 0, 0}), 0},
+		{"property missing", property(0, 0, nil), 0},
+		{"wrong format", property(8, xproto.AtomString, []byte("1234")), 0},
+		{"short value", property(32, xproto.AtomCardinal, []byte{1, 2}), 0},
+		{"nil reply", nil, 0},
+	}
+	for _, tt := range tests {
+		if got := cardinalFromProperty(tt.reply); got != tt.want {
+			t.Errorf("%s: cardinalFromProperty = %#x, want %#x", tt.name, got, tt.want)
+		}
+	}
+	if got := windowFromProperty(tests[0].reply); got != 0x03c00005 {
+		t.Errorf("windowFromProperty = %#x, want 0x3c00005", got)
+	}
+}
+
+func TestTextFromProperty(t *testing.T) {
+	const utf8String = xproto.Atom(300)
+
+	tests := []struct {
+		name  string
+		reply *xproto.GetPropertyReply
+		want  string
+	}{
+		{"utf-8 title", property(8, utf8String, []byte("main.go — focusd - Visual Studio Code")), "main.go — focusd - Visual Studio Code"},
+		{"quotes kept", property(8, utf8String, []byte(`say "hi" - Slack`)), `say "hi" - Slack`},
+		{"latin-1 WM_NAME", property(8, xproto.AtomString, []byte{'C', 'a', 'f', 0xe9}), "Café"},
+		{"trailing nul", property(8, utf8String, []byte("Terminal\x00")), "Terminal"},
+		{"padding beyond length", &xproto.GetPropertyReply{Format: 8, Type: utf8String, ValueLen: 3, Value: []byte("vimxx")}, "vim"},
+		{"invalid utf-8", property(8, utf8String, []byte{'a', 0xff, 'b'}), "a�b"},
+		{"empty", property(8, utf8String, nil), ""},
+		{"wrong format", property(32, xproto.AtomCardinal, []byte{1, 0, 0, 0}), ""},
+		{"nil reply", nil, ""},
+	}
+	for _, tt := range tests {
+		if got := textFromProperty(tt.reply); got != tt.want {
+			t.Errorf("%s: textFromProperty = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGetProcessName(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Base(exe)
+	if len(want) > 15 {
+		want = want[:15]
+	}
+
+	if got := getProcessName(uint32(os.Getpid())); got != want {
+		t.Errorf("getProcessName(self) = %q, want %q", got, want)
+	}
+	if got := getProcessName(0); got != "" {
+		t.Errorf("getProcessName(0) = %q, want empty", got)
+	}
+}
+
+func TestX11WithoutDisplay(t *testing.T) {
+	t.Setenv("DISPLAY", "")
+
+	if _, err := GetForegroundWindowInfo(); err != errNoDisplay {
+		t.Errorf("GetForegroundWindowInfo error = %v, want %v", err, errNoDisplay)
+	}
+	if _, err := NewIdleSource(nil); err == nil || !strings.Contains(err.Error(), "DISPLAY") {
+		t.Errorf("NewIdleSource error = %v, want it to mention DISPLAY", err)
+	}
+}